@@ -9,6 +9,8 @@ import (
 	"regexp"
 	"strings"
 	"time"
+
+	"github.com/aliyun/aliyun-oss-go-sdk/oss/internal/endpoint"
 )
 
 type (
@@ -21,6 +23,8 @@ type (
 		client          *http.Client
 		scheme          string
 		now             func() time.Time
+		crc64Check      bool
+		rateLimiter     *RateLimiter
 	}
 	// APIOption provides optional configurations for an API object
 	APIOption func(*API)
@@ -68,6 +72,16 @@ func URLScheme(scheme string) APIOption {
 	}
 }
 
+// Clock sets the function returning the current time, which dates the
+// requests, default is time.Now.
+func Clock(now func() time.Time) APIOption {
+	return func(a *API) {
+		if now != nil {
+			a.now = now
+		}
+	}
+}
+
 // GetService list all buckets
 func (a *API) GetService(options ...Option) (res *ListAllMyBucketsResult, _ error) {
 	return res, a.Do("GET", "", "", &res, options...)
@@ -160,7 +174,7 @@ func (a *API) DeleteBucketLifecycle(bucket string) error {
 
 // PutObject uploads a file from an io.Reader
 func (a *API) PutObject(bucket, object string, rd io.Reader, options ...Option) error {
-	return a.Do("PUT", bucket, object, nil, append([]Option{HTTPBody(rd)}, options...)...)
+	return a.Do("PUT", bucket, object, nil, append([]Option{HTTPBody(rd), crc64Body(0)}, options...)...)
 }
 
 // CopyObject copies an existing object on OSS to another object
@@ -170,14 +184,26 @@ func (a *API) CopyObject(sourceBucket, sourceObject, targetBucket, targetObject
 
 // GetObject returns an object and write it to an io.Writer
 func (a *API) GetObject(bucket, object string, w io.Writer, options ...Option) (res Header, _ error) {
-	return res, a.Do("GET", bucket, object, &bodyAndHeader{Writer: w, Header: &res})
+	return res, a.Do("GET", bucket, object, &bodyAndHeader{Writer: w, Header: &res}, append([]Option{crc64ResponseBody}, options...)...)
 }
 
-// AppendObject uploads a file by append to it from an io.Reader
+// AppendObject uploads a file by append to it from an io.Reader. When CRC-64
+// checking is on, only the first append (position 0) is checked, use
+// AppendObjectWithCRC to check the subsequent ones.
 func (a *API) AppendObject(bucket, object string, rd io.Reader, position AppendPosition, options ...Option) (res AppendPosition, _ error) {
+	if position == 0 {
+		options = append([]Option{crc64Body(0)}, options...)
+	}
 	return res, a.Do("POST", bucket, fmt.Sprintf("%s?append&position=%d", object, position), &res, append([]Option{HTTPBody(rd)}, options...)...)
 }
 
+// AppendObjectWithCRC appends to an object like AppendObject, initCRC is the
+// CRC-64 of the object before appending, i.e. the CRC64 returned by the
+// previous append or 0 for a new object.
+func (a *API) AppendObjectWithCRC(bucket, object string, rd io.Reader, position AppendPosition, initCRC uint64, options ...Option) (res *AppendObjectResult, _ error) {
+	return res, a.Do("POST", bucket, fmt.Sprintf("%s?append&position=%d", object, position), &res, append([]Option{HTTPBody(rd), crc64Body(initCRC)}, options...)...)
+}
+
 // DeleteObject deletes an object
 func (a *API) DeleteObject(bucket, object string) error {
 	return a.Do("DELETE", bucket, object, nil)
@@ -189,8 +215,8 @@ func (a *API) DeleteObjects(bucket string, quiet bool, objects ...string) (res *
 }
 
 // HeadObject returns only the metadata of an object in HTTP headers
-func (a *API) HeadObject(bucket, object string) (res Header, _ error) {
-	return res, a.Do("HEAD", bucket, object, &res)
+func (a *API) HeadObject(bucket, object string, options ...Option) (res Header, _ error) {
+	return res, a.Do("HEAD", bucket, object, &res, options...)
 }
 
 // PutObjectACL sets acess right for an object
@@ -203,14 +229,26 @@ func (a *API) GetObjectACL(bucket, object string) (res *AccessControlPolicy, _ e
 	return res, a.Do("GET", bucket, object+"?acl", &res)
 }
 
+// PutSymlink creates a symbolic link to a target object in the same bucket.
+// Meta, ObjectACL and StorageClass options are supported.
+func (a *API) PutSymlink(bucket, symlink, target string, options ...Option) error {
+	return a.Do("PUT", bucket, symlink+"?symlink", nil, append(options, setHeader("X-Oss-Symlink-Target", url.QueryEscape(target)))...)
+}
+
+// GetSymlink returns the target of a symbolic link
+func (a *API) GetSymlink(bucket, symlink string) (res *SymlinkResult, _ error) {
+	return res, a.Do("GET", bucket, symlink+"?symlink", &res)
+}
+
 // InitUpload starts an multipart upload process
 func (a *API) InitUpload(bucket, object string, options ...Option) (res *InitiateMultipartUploadResult, _ error) {
 	return res, a.Do("POST", bucket, object+"?uploads", &res, append(options, ContentType("application/octet-stream"))...)
 }
 
-// UploadPart updates a trunk of data from an io.Reader
-func (a *API) UploadPart(bucket, object string, uploadID string, partNumber int, rd io.Reader, size int64) (res *UploadPartResult, _ error) {
-	return res, a.Do("PUT", bucket, fmt.Sprintf("%s?partNumber=%d&uploadId=%s", object, partNumber, uploadID), &res, HTTPBody(&io.LimitedReader{R: rd, N: size}), ContentLength(size))
+// UploadPart updates a trunk of data from an io.Reader. ContentMD5 option
+// hashes the part without buffering it when rd is an io.Seeker.
+func (a *API) UploadPart(bucket, object string, uploadID string, partNumber int, rd io.Reader, size int64, options ...Option) (res *UploadPartResult, _ error) {
+	return res, a.Do("PUT", bucket, fmt.Sprintf("%s?partNumber=%d&uploadId=%s", object, partNumber, uploadID), &res, append([]Option{HTTPBody(&io.LimitedReader{R: rd, N: size}), ContentLength(size), crc64Body(0)}, options...)...)
 }
 
 // UploadPartCopy updates a trunk of data from an existing object
@@ -218,9 +256,15 @@ func (a *API) UploadPartCopy(bucket, object string, uploadID string, partNumber
 	return res, a.Do("PUT", bucket, fmt.Sprintf("%s?partNumber=%d&uploadId=%s", object, partNumber, uploadID), &res, CopySource(sourceBucket, sourceObject))
 }
 
-// CompleteUpload notifies that the multipart upload is complete
+// CompleteUpload notifies that the multipart upload is complete. When CRC-64
+// checking is on and the CRC64 and Size of every part are set, the CRC-64 of
+// the object is verified.
 func (a *API) CompleteUpload(bucket, object string, uploadID string, list *CompleteMultipartUpload) (res *CompleteMultipartUploadResult, _ error) {
-	return res, a.Do("POST", bucket, fmt.Sprintf("%s?uploadId=%s", object, uploadID), &res, XMLBody(list), ContentMD5, ContentType("application/octet-stream"))
+	options := []Option{XMLBody(list), ContentMD5, ContentType("application/octet-stream")}
+	if crc, ok := partsCRC64(list.Part); ok {
+		options = append(options, crc64Expected(crc))
+	}
+	return res, a.Do("POST", bucket, fmt.Sprintf("%s?uploadId=%s", object, uploadID), &res, options...)
 }
 
 // AbortUpload aborts a multipart upload
@@ -264,12 +308,32 @@ func (a *API) Do(method, bucket, object string, result interface{}, options ...O
 	if err != nil {
 		return err
 	}
+	var crc *crc64Check
+	if a.crc64Check {
+		crc = newCRC64Check(req)
+	}
+	progress := newProgressTracker(req)
+	req.Body = a.rateLimiter.reader(req.Body)
 	resp, err := a.client.Do(req)
 	if err != nil {
-		return err
+		return progress.finish(err)
+	}
+	resp.Body = a.rateLimiter.reader(resp.Body)
+	progress.wrapResponse(resp)
+	crc.wrapResponse(resp)
+	if stream, ok := result.(streamParser); ok && (resp.StatusCode/100 == 2 || resp.StatusCode == http.StatusNotModified) {
+		return stream.parseStream(resp, func(err error) error {
+			if err == nil {
+				err = crc.verify(resp)
+			}
+			return progress.finish(err)
+		})
 	}
 	defer resp.Body.Close()
-	return a.handleResponse(resp, result)
+	if err := a.handleResponse(resp, result); err != nil {
+		return progress.finish(err)
+	}
+	return progress.finish(crc.verify(resp))
 }
 
 func (a *API) newRequest(method, bucket, object string, options []Option) (*http.Request, error) {
@@ -325,7 +389,7 @@ func ossURL(scheme, host, bucket, object string) (*url.URL, error) {
 	if len(object) > 1023 || object != "" && !rxObjectName.MatchString(object) {
 		return nil, ErrInvalidObjectName
 	}
-	if !isOSSDomain(host) {
+	if !endpoint.IsOSSDomain(host) {
 		return url.Parse(scheme + fmt.Sprintf("%s/%s/%s", host, bucket, object))
 	}
 	return url.Parse(scheme + fmt.Sprintf("%s.%s/%s", bucket, host, object))
@@ -1459,6 +1459,179 @@ Connection: close
 			"Access-Control-Expose-Headers": []string{"x-oss-test"},
 		},
 	},
+
+	{
+		name: "PutSymlink",
+		request: func(a *API) (interface{}, error) {
+			return nil, a.PutSymlink(testBucketName, "link/latest", "builds/v1.0 (final).zip", Meta("Build", "42"), ObjectACL(PrivateACL), StorageClass(IAStorage))
+		},
+		expectedRequest: `PUT /link/latest?symlink HTTP/1.1
+Host: bucket-name.oss-cn-hangzhou.aliyuncs.com
+User-Agent: %s
+Content-Length: 0
+Accept-Encoding: identity
+Authorization: OSS ayahghai0juiSie:qQ46ewToELxQysmzX0+UC+BLoAU=
+Date: %s
+X-Oss-Meta-Build: 42
+X-Oss-Object-Acl: private
+X-Oss-Storage-Class: IA
+X-Oss-Symlink-Target: builds%%2Fv1.0+%%28final%%29.zip`,
+		response: `HTTP/1.1 200 OK
+x-oss-request-id: 582B0A8F6CB1B8C5CB95BCBF
+Date: Tue, 15 Nov 2016 13:12:47 GMT
+ETag: "136A5E0F9A7A03D4F4D2E9A0B6D71C2E"
+Content-Length: 0
+Connection: close
+Server: AliyunOSS
+`,
+		expectedResponse: nil,
+	},
+
+	{
+		name: "GetSymlink",
+		request: func(a *API) (interface{}, error) {
+			r, err := a.GetSymlink(testBucketName, "link/latest")
+			return r, err
+		},
+		expectedRequest: `GET /link/latest?symlink HTTP/1.1
+Host: bucket-name.oss-cn-hangzhou.aliyuncs.com
+User-Agent: %s
+Accept-Encoding: identity
+Authorization: OSS ayahghai0juiSie:S92S1nYqXi6OiR26cZfBpNHK1As=
+Date: %s`,
+		response: `HTTP/1.1 200 OK
+x-oss-request-id: 582B0A8F6CB1B8C5CB95BCBF
+Date: Tue, 15 Nov 2016 13:12:47 GMT
+Last-Modified: Tue, 15 Nov 2016 13:12:40 GMT
+ETag: "136A5E0F9A7A03D4F4D2E9A0B6D71C2E"
+x-oss-symlink-target: builds%2Fv1.0+%28final%29.zip
+Content-Length: 0
+Connection: close
+Server: AliyunOSS
+`,
+		expectedResponse: &SymlinkResult{
+			Target:       "builds/v1.0 (final).zip",
+			ETag:         `"136A5E0F9A7A03D4F4D2E9A0B6D71C2E"`,
+			LastModified: "Tue, 15 Nov 2016 13:12:40 GMT",
+		},
+	},
+
+	{
+		name: "RestoreObject",
+		request: func(a *API) (interface{}, error) {
+			return nil, a.RestoreObject(testBucketName, testObjectName, &RestoreRequest{
+				Days:          2,
+				JobParameters: &JobParameters{Tier: StandardTier},
+			})
+		},
+		expectedRequest: `POST /object/name?restore HTTP/1.1
+Host: bucket-name.oss-cn-hangzhou.aliyuncs.com
+User-Agent: %s
+Content-Length: 99
+Accept-Encoding: identity
+Authorization: OSS ayahghai0juiSie:G1HxK7jxFUJ7CGYOcDmVFZKsMVQ=
+Date: %s
+
+<RestoreRequest><Days>2</Days><JobParameters><Tier>Standard</Tier></JobParameters></RestoreRequest>`,
+		response: `HTTP/1.1 202 Accepted
+x-oss-request-id: 5374A2880232A65C23002D74
+Date: Sat, 26 Dec 2015 23:22:55 GMT
+Content-Length: 0
+Connection: close
+Server: AliyunOSS
+`,
+		expectedResponse: nil,
+	},
+
+	{
+		name: "HeadObjectMeta",
+		request: func(a *API) (interface{}, error) {
+			r, err := a.HeadObjectMeta(testBucketName, testObjectName)
+			return r, err
+		},
+		expectedRequest: `HEAD /object/name HTTP/1.1
+Host: bucket-name.oss-cn-hangzhou.aliyuncs.com
+User-Agent: %s
+Accept-Encoding: identity
+Authorization: OSS ayahghai0juiSie:B29hiJ0Fu10nq+kyeb4+vM6Cwns=
+Date: %s`,
+		response: `HTTP/1.1 200 OK
+x-oss-request-id: 559CC9BDC755F95A64485981
+x-oss-object-type: Appendable
+x-oss-storage-class: Archive
+x-oss-next-append-position: 344606
+x-oss-hash-crc64ecma: 10134427358429785519
+x-oss-restore: ongoing-request="true"
+x-oss-meta-Project: Sync
+X-OSS-META-OWNER: baymax
+ETag: "fba9dede5f27731c9771645a39863328"
+Last-Modified: Fri, 24 Feb 2012 06:07:48 GMT
+Content-Type: image/jpeg
+Content-Length: 344606
+`,
+		expectedResponse: &ObjectMeta{
+			ContentLength:      344606,
+			ContentType:        "image/jpeg",
+			ETag:               "fba9dede5f27731c9771645a39863328",
+			LastModified:       parseTime(gmtTime, "Fri, 24 Feb 2012 06:07:48 GMT"),
+			ObjectType:         AppendableObject,
+			StorageClass:       ArchiveStorage,
+			NextAppendPosition: 344606,
+			CRC64:              10134427358429785519,
+			Restore:            &RestoreStatus{Ongoing: true},
+			UserMeta: map[string]string{
+				"project": "Sync",
+				"owner":   "baymax",
+			},
+			Header: Header{
+				"X-Oss-Request-Id":           []string{"559CC9BDC755F95A64485981"},
+				"X-Oss-Object-Type":          []string{"Appendable"},
+				"X-Oss-Storage-Class":        []string{"Archive"},
+				"X-Oss-Next-Append-Position": []string{"344606"},
+				"X-Oss-Hash-Crc64ecma":       []string{"10134427358429785519"},
+				"X-Oss-Restore":              []string{`ongoing-request="true"`},
+				"X-Oss-Meta-Project":         []string{"Sync"},
+				"X-Oss-Meta-Owner":           []string{"baymax"},
+				"Etag":                       []string{`"fba9dede5f27731c9771645a39863328"`},
+				"Last-Modified":              []string{"Fri, 24 Feb 2012 06:07:48 GMT"},
+				"Content-Type":               []string{"image/jpeg"},
+			},
+		},
+	},
+
+	{
+		name: "GetObjectMeta",
+		request: func(a *API) (interface{}, error) {
+			r, err := a.GetObjectMeta(testBucketName, testObjectName)
+			return r, err
+		},
+		expectedRequest: `HEAD /object/name?objectMeta HTTP/1.1
+Host: bucket-name.oss-cn-hangzhou.aliyuncs.com
+User-Agent: %s
+Accept-Encoding: identity
+Authorization: OSS ayahghai0juiSie:CqqET5AP7RizPFb5JFmoGKXY/28=
+Date: %s`,
+		response: `HTTP/1.1 200 OK
+x-oss-request-id: 559CC9BDC755F95A64485981
+x-oss-last-access-time: Thu, 14 Oct 2021 11:49:05 GMT
+ETag: "5B3C1A2E053D763E1B002CC607C5A0FE"
+Last-Modified: Fri, 24 Feb 2012 06:07:48 GMT
+Content-Length: 344606
+`,
+		expectedResponse: &ObjectMeta{
+			ContentLength:  344606,
+			ETag:           "5B3C1A2E053D763E1B002CC607C5A0FE",
+			LastModified:   parseTime(gmtTime, "Fri, 24 Feb 2012 06:07:48 GMT"),
+			LastAccessTime: parseTime(gmtTime, "Thu, 14 Oct 2021 11:49:05 GMT"),
+			UserMeta:       map[string]string{},
+			Header: Header{
+				"X-Oss-Request-Id":       []string{"559CC9BDC755F95A64485981"},
+				"X-Oss-Last-Access-Time": []string{"Thu, 14 Oct 2021 11:49:05 GMT"},
+				"Etag":                   []string{`"5B3C1A2E053D763E1B002CC607C5A0FE"`},
+				"Last-Modified":          []string{"Fri, 24 Feb 2012 06:07:48 GMT"},
+			},
+		},
+	},
 }
 
 func TestAllOssAPIs(t *testing.T) {
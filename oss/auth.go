@@ -2,15 +2,20 @@ package oss
 
 import (
 	"bytes"
-	"crypto/hmac"
 	"crypto/md5"
-	"crypto/sha1"
 	"encoding/base64"
 	"errors"
+	"hash"
+	"io"
 	"io/ioutil"
 	"net/http"
+	"net/url"
 	"sort"
+	"strconv"
 	"strings"
+	"time"
+
+	"github.com/aliyun/aliyun-oss-go-sdk/oss/internal/sign"
 )
 
 type authorization struct {
@@ -20,7 +25,13 @@ type authorization struct {
 	secret []byte
 }
 
-// ContentMD5 is the option for calculating and adding a Content-Md5 header for an HTTP request
+// ContentMD5 is the option for calculating and adding a Content-Md5 header for an HTTP request.
+//
+// Seekable bodies (io.Seeker, e.g. *os.File, or an io.Reader limited by
+// UploadPart) are hashed from their current position in a first pass and
+// rewound. An io.ReaderAt with a Size method but no Seek has no position, it
+// is always hashed and sent in full from offset 0, whatever was read from it
+// before. Only other bodies are read into memory.
 func ContentMD5(req *http.Request) error {
 	if _, ok := req.Header["Content-Md5"]; ok {
 		return errors.New("Content-Md5 is already set")
@@ -28,75 +39,137 @@ func ContentMD5(req *http.Request) error {
 	if req.Body == nil {
 		return errors.New("Content-Md5 requires non-nil body")
 	}
-	buf, _ := ioutil.ReadAll(req.Body)
-	req.Body = ioutil.NopCloser(bytes.NewReader(buf))
-	sum := md5.Sum(buf)
-	b64 := base64.StdEncoding.EncodeToString(sum[:])
-	req.Header.Set("Content-Md5", b64)
+	sum, err := bodyMD5(req)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Md5", base64.StdEncoding.EncodeToString(sum))
 	return nil
 }
 
-func (a *authorization) canonicalizedOSSHeaders() []byte {
-	var kvs kvSlice
-	for key, vs := range a.req.Header {
-		key = strings.ToLower(key)
-		if strings.HasPrefix(key, "x-oss-") {
-			for _, val := range vs {
-				kvs = append(kvs, kv{key, val})
+// ContentMD5Sum is the option for adding a Content-Md5 header from an MD5
+// digest computed beforehand, e.g. when the body was hashed while it was
+// written
+func ContentMD5Sum(sum []byte) Option {
+	return func(req *http.Request) error {
+		if len(sum) != md5.Size {
+			return errors.New("invalid MD5 digest size")
+		}
+		req.Header.Set("Content-Md5", base64.StdEncoding.EncodeToString(sum))
+		return nil
+	}
+}
+
+type sizeReaderAt interface {
+	io.ReaderAt
+	Size() int64
+}
+
+func bodyMD5(req *http.Request) ([]byte, error) {
+	h := md5.New()
+	switch body := bodyReader(req.Body).(type) {
+	case *io.LimitedReader:
+		if seeker, ok := body.R.(io.Seeker); ok {
+			if err := hashAndRewind(h, io.LimitReader(body.R, body.N), seeker); err != nil {
+				return nil, err
 			}
+			return h.Sum(nil), nil
+		}
+	case io.Seeker:
+		if err := hashAndRewind(h, body.(io.Reader), body); err != nil {
+			return nil, err
 		}
+		return h.Sum(nil), nil
+	case sizeReaderAt:
+		// the body is replaced by the section hashed, whatever was read
+		// from it before
+		section := io.NewSectionReader(body, 0, body.Size())
+		if _, err := io.Copy(h, section); err != nil {
+			return nil, err
+		}
+		section.Seek(0, io.SeekStart)
+		req.Body = nopCloser{section}
+		req.ContentLength = body.Size()
+		return h.Sum(nil), nil
 	}
-	sort.Sort(kvs)
-	var buf bytes.Buffer
-	for _, kv := range kvs {
-		buf.WriteString(kv.key)
-		buf.WriteByte(':')
-		buf.WriteString(kv.val)
-		buf.WriteByte('\n')
+	buf, err := ioutil.ReadAll(req.Body)
+	if err != nil {
+		return nil, err
 	}
-	return buf.Bytes()
+	req.Body = nopCloser{bytes.NewReader(buf)}
+	h.Write(buf)
+	return h.Sum(nil), nil
 }
 
-func (a *authorization) data() []byte {
-	var w bytes.Buffer
-	w.WriteString(a.req.Method)
-	w.WriteByte('\n')
-	w.WriteString(a.req.Header.Get("Content-Md5"))
-	w.WriteByte('\n')
-	w.WriteString(a.req.Header.Get("Content-Type"))
-	w.WriteByte('\n')
-	w.WriteString(a.req.Header.Get("Date"))
-	w.WriteByte('\n')
-	w.Write(a.canonicalizedOSSHeaders())
-	w.WriteString(a.canonicalizedResource())
-	return w.Bytes()
+// hashAndRewind hashes r and then seeks back to the position before hashing
+func hashAndRewind(h hash.Hash, r io.Reader, seeker io.Seeker) error {
+	pos, err := seeker.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(h, r); err != nil {
+		return err
+	}
+	_, err = seeker.Seek(pos, io.SeekStart)
+	return err
 }
 
-func (a *authorization) canonicalizedResource() string {
-	uri := *a.req.URL
-	uri.Scheme = ""
-	uri.Host = ""
-	uri.Path = a.bucket + uri.Path
-	return "/" + uri.String()
+func (a *authorization) canonicalizedOSSHeaders() []byte {
+	return sign.CanonicalizedOSSHeaders(a.req.Header)
+}
+
+func (a *authorization) data() []byte {
+	return sign.StringToSign(a.req, a.bucket)
 }
 
 func (a *authorization) value() string {
-	return hmacSHA1(a.data(), a.secret)
+	return sign.Signature(a.req, a.bucket, a.secret)
 }
-func hmacSHA1(data []byte, secret []byte) string {
-	h := hmac.New(sha1.New, secret)
-	h.Write(data)
-	return base64.StdEncoding.EncodeToString(h.Sum(nil))
+
+// SignURL returns a URL of an object which can be requested with method
+// without credentials until expires, e.g. a GET URL with the Process
+// option. The headers set by options must be sent with the same values.
+func (a *API) SignURL(method, bucket, object string, expires time.Time, options ...Option) (string, error) {
+	uri, err := ossURL(a.scheme, a.endPoint, bucket, object)
+	if err != nil {
+		return "", err
+	}
+	req := &http.Request{Method: method, URL: uri, Header: make(http.Header), Host: uri.Host}
+	for _, option := range options {
+		if err := option(req); err != nil {
+			return "", err
+		}
+	}
+	query := req.URL.RawQuery
+	if a.securityToken != "" {
+		// the token is signed unescaped but sent escaped
+		addRawParam(req.URL, "security-token", a.securityToken)
+	}
+	expiresUnix := strconv.FormatInt(expires.Unix(), 10)
+	req.Header.Set("Date", expiresUnix)
+	auth := authorization{req: req, bucket: bucket, object: object, secret: []byte(a.accessKeySecret)}
+	signature := auth.value()
+	if a.securityToken != "" {
+		req.URL.RawQuery = query
+		addRawParam(req.URL, "security-token", url.QueryEscape(a.securityToken))
+	}
+	req.URL.RawQuery = strings.TrimPrefix(req.URL.RawQuery+"&OSSAccessKeyId="+url.QueryEscape(a.accessKeyID)+
+		"&Expires="+expiresUnix+"&Signature="+url.QueryEscape(signature), "&")
+	return req.URL.String(), nil
 }
 
-type (
-	kv struct {
-		key string
-		val string
+// addRawParam adds an escaped parameter to a query keeping it sorted, as
+// the query is signed
+func addRawParam(u *url.URL, key, value string) {
+	var params []string
+	if u.RawQuery != "" {
+		params = strings.Split(u.RawQuery, "&")
 	}
-	kvSlice []kv
-)
+	params = append(params, key+"="+value)
+	sort.Strings(params)
+	u.RawQuery = strings.Join(params, "&")
+}
 
-func (s kvSlice) Len() int           { return len(s) }
-func (s kvSlice) Less(i, j int) bool { return s[i].key < s[j].key }
-func (s kvSlice) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+func hmacSHA1(data []byte, secret []byte) string {
+	return sign.HMACSHA1(data, secret)
+}
@@ -0,0 +1,72 @@
+package oss_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/aliyun/aliyun-oss-go-sdk/oss"
+)
+
+func TestUploadPartContentMD5(t *testing.T) {
+	server := newTestServer(t)
+	defer server.Close()
+	api := server.API()
+	// the first part is at least 100KB
+	const partSize = 100 * 1024
+	data := testPlaintext(2 * partSize)
+	rd := bytes.NewReader(data)
+	upload, err := api.InitUpload(testBucketName, testObjectName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	list := &oss.CompleteMultipartUpload{}
+	for i := 0; i < 2; i++ {
+		res, err := api.UploadPart(testBucketName, testObjectName, upload.UploadID, i+1, rd, partSize, oss.ContentMD5)
+		if err != nil {
+			t.Fatal(err)
+		}
+		list.Part = append(list.Part, oss.Part{PartNumber: i + 1, ETag: res.ETag})
+	}
+	if _, err := api.CompleteUpload(testBucketName, testObjectName, upload.UploadID, list); err != nil {
+		t.Fatal(err)
+	}
+	if actual := getObjectData(t, api, testObjectName); !bytes.Equal(actual, data) {
+		t.Fatalf(expectBut, data, actual)
+	}
+	// a seekable body is sent from its position
+	seeker := bytes.NewReader(data)
+	seeker.Read(make([]byte, 100))
+	if err := api.PutObject(testBucketName, testObjectName, seeker, oss.ContentMD5); err != nil {
+		t.Fatal(err)
+	}
+	if actual := getObjectData(t, api, testObjectName); !bytes.Equal(actual, data[100:]) {
+		t.Fatalf(expectBut, data[100:], actual)
+	}
+}
+
+func TestReaderAtContentMD5(t *testing.T) {
+	server := newTestServer(t)
+	defer server.Close()
+	api := server.API()
+	data := testPlaintext(1000)
+	// an io.ReaderAt with a Size method but no Seek is sent from offset 0 as
+	// ContentMD5 documents, even after its Read is partly consumed; the
+	// server checks the Content-Md5 of the bytes sent
+	rd := unseekableReaderAt{bytes.NewReader(data)}
+	rd.Read(make([]byte, 100))
+	if err := api.PutObject(testBucketName, testObjectName, rd, oss.ContentMD5); err != nil {
+		t.Fatal(err)
+	}
+	if actual := getObjectData(t, api, testObjectName); !bytes.Equal(actual, data) {
+		t.Fatalf(expectBut, data, actual)
+	}
+}
+
+// unseekableReaderAt hides the io.Seeker method of *bytes.Reader
+type unseekableReaderAt struct {
+	r *bytes.Reader
+}
+
+func (r unseekableReaderAt) Read(p []byte) (int, error)              { return r.r.Read(p) }
+func (r unseekableReaderAt) ReadAt(p []byte, off int64) (int, error) { return r.r.ReadAt(p, off) }
+func (r unseekableReaderAt) Size() int64                             { return r.r.Size() }
@@ -1,6 +1,11 @@
 package oss
 
 import (
+	"bytes"
+	"crypto/md5"
+	"encoding/base64"
+	"io"
+	"io/ioutil"
 	"net/http"
 	"os"
 	"strings"
@@ -65,3 +70,70 @@ func TestContentMD5(t *testing.T) {
 	}
 
 }
+
+func TestContentMD5Streaming(t *testing.T) {
+	data := testPlaintext(1000)
+	sumOf := func(b []byte) string {
+		sum := md5.Sum(b)
+		return base64.StdEncoding.EncodeToString(sum[:])
+	}
+	{
+		// a part of a seekable reader is hashed and rewound
+		rd := bytes.NewReader(data)
+		rd.Seek(100, io.SeekStart)
+		req, _ := http.NewRequest("PUT", "/", nil)
+		HTTPBody(&io.LimitedReader{R: rd, N: 300})(req)
+		if err := ContentMD5(req); err != nil {
+			t.Fatal(err)
+		}
+		if expected, actual := sumOf(data[100:400]), req.Header.Get("Content-Md5"); actual != expected {
+			t.Fatalf(expectBut, expected, actual)
+		}
+		body, _ := ioutil.ReadAll(req.Body)
+		if !bytes.Equal(body, data[100:400]) {
+			t.Fatalf(expectBut, data[100:400], body)
+		}
+	}
+	{
+		f, err := os.Open("testdata/h-content-md5.html")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer f.Close()
+		req, _ := http.NewRequest("PUT", "/", nil)
+		HTTPBody(f)(req)
+		ContentMD5(req)
+		if expected, actual := "0TMnkhCZtrIjdTtJk6x3+Q==", req.Header.Get("Content-Md5"); actual != expected {
+			t.Fatalf(expectBut, expected, actual)
+		}
+		if pos, _ := f.Seek(0, io.SeekCurrent); pos != 0 {
+			t.Fatalf(expectBut, 0, pos)
+		}
+	}
+	{
+		// other readers are buffered
+		req, _ := http.NewRequest("PUT", "/", nil)
+		HTTPBody(io.MultiReader(bytes.NewReader(data)))(req)
+		ContentMD5(req)
+		if expected, actual := sumOf(data), req.Header.Get("Content-Md5"); actual != expected {
+			t.Fatalf(expectBut, expected, actual)
+		}
+		if body, _ := ioutil.ReadAll(req.Body); !bytes.Equal(body, data) {
+			t.Fatalf(expectBut, data, body)
+		}
+	}
+}
+
+func TestContentMD5Sum(t *testing.T) {
+	sum := md5.Sum([]byte("abc"))
+	req, _ := http.NewRequest("PUT", "/", nil)
+	if err := ContentMD5Sum(sum[:])(req); err != nil {
+		t.Fatal(err)
+	}
+	if expected, actual := "kAFQmDzST7DWlj99KOF/cg==", req.Header.Get("Content-Md5"); actual != expected {
+		t.Fatalf(expectBut, expected, actual)
+	}
+	if err := ContentMD5Sum(sum[:3])(req); err == nil {
+		t.Fatal("expect error but got nil")
+	}
+}
@@ -4,7 +4,6 @@ import (
 	"bytes"
 	"encoding/xml"
 	"io"
-	"io/ioutil"
 	"mime"
 	"net/http"
 	"os"
@@ -46,21 +45,90 @@ type (
 		MaxAgeSeconds int `xml:"MaxAgeSeconds,omitempty"`
 	}
 
-	// LifecycleConfiguration represents the deletion rule for a bucket
+	// LifecycleConfiguration represents the lifecycle rules of a bucket
 	LifecycleConfiguration struct {
 		Rule []LifecycleRule
 	}
-	// LifecycleRule represents a deletion rule
+	// LifecycleRule represents a rule expiring or transitioning the objects
+	// with Prefix and all the Tags, except those matched by Filter
 	LifecycleRule struct {
-		ID         string
-		Prefix     string
-		Status     string
-		Expiration Expiration
+		ID     string
+		Prefix string
+		// Status is LifecycleEnabled or LifecycleDisabled
+		Status string
+		// Expiration is left out if it is zero
+		Expiration           Expiration
+		Transition           []Transition          `xml:"Transition,omitempty"`
+		AbortMultipartUpload *AbortMultipartUpload `xml:"AbortMultipartUpload,omitempty"`
+		Tag                  []Tag                 `xml:"Tag,omitempty"`
+		// NoncurrentVersionExpiration and NoncurrentVersionTransition apply
+		// to the previous versions of the objects in a versioned bucket
+		NoncurrentVersionExpiration *NoncurrentVersionExpiration  `xml:"NoncurrentVersionExpiration,omitempty"`
+		NoncurrentVersionTransition []NoncurrentVersionTransition `xml:"NoncurrentVersionTransition,omitempty"`
+		Filter                      *LifecycleFilter              `xml:"Filter,omitempty"`
+		// AtimeBase is returned for the rules on the last access time, the
+		// Unix time from which the access time is tracked
+		AtimeBase int64 `xml:"AtimeBase,omitempty"`
 	}
-	// Expiration represents the expiration time either by days or exact time
+	// Expiration represents the expiration time either by days after the
+	// last modification or by the creation date. Date is kept for the
+	// configurations setting it, it is superseded by CreatedBeforeDate.
 	Expiration struct {
-		Days int        `xml:"Days,omitempty"`
-		Date *time.Time `xml:"Date,omitempty"`
+		Days              int        `xml:"Days,omitempty"`
+		Date              *time.Time `xml:"Date,omitempty"`
+		CreatedBeforeDate *time.Time `xml:"CreatedBeforeDate,omitempty"`
+		// ExpiredObjectDeleteMarker deletes the delete markers with no
+		// previous version in a versioned bucket
+		ExpiredObjectDeleteMarker bool `xml:"ExpiredObjectDeleteMarker,omitempty"`
+	}
+	// Transition changes the storage class of the objects either by days
+	// after the last modification or by the creation date
+	Transition struct {
+		Days              int        `xml:"Days,omitempty"`
+		CreatedBeforeDate *time.Time `xml:"CreatedBeforeDate,omitempty"`
+		StorageClass      StorageClassType
+		// IsAccessTime counts Days from the last access instead of the last
+		// modification, the access monitor of the bucket must be enabled
+		IsAccessTime bool `xml:"IsAccessTime,omitempty"`
+		// ReturnToStdWhenVisit moves an IA object transitioned by the access
+		// time back to Standard when it is accessed
+		ReturnToStdWhenVisit bool `xml:"ReturnToStdWhenVisit,omitempty"`
+		// AllowSmallFile transitions the objects smaller than 64 KB too
+		AllowSmallFile bool `xml:"AllowSmallFile,omitempty"`
+	}
+	// AbortMultipartUpload aborts the multipart uploads either by days after
+	// they are initiated or by the initiation date
+	AbortMultipartUpload struct {
+		Days              int        `xml:"Days,omitempty"`
+		CreatedBeforeDate *time.Time `xml:"CreatedBeforeDate,omitempty"`
+	}
+	// NoncurrentVersionExpiration deletes a previous version NoncurrentDays
+	// after it becomes previous
+	NoncurrentVersionExpiration struct {
+		NoncurrentDays int
+	}
+	// NoncurrentVersionTransition changes the storage class of a previous
+	// version NoncurrentDays after it becomes previous
+	NoncurrentVersionTransition struct {
+		NoncurrentDays       int
+		StorageClass         StorageClassType
+		IsAccessTime         bool `xml:"IsAccessTime,omitempty"`
+		ReturnToStdWhenVisit bool `xml:"ReturnToStdWhenVisit,omitempty"`
+		AllowSmallFile       bool `xml:"AllowSmallFile,omitempty"`
+	}
+	// LifecycleFilter excludes objects from a rule
+	LifecycleFilter struct {
+		Not []LifecycleNot `xml:"Not,omitempty"`
+	}
+	// LifecycleNot excludes the objects with Prefix, and Tag if it is set
+	LifecycleNot struct {
+		Prefix string
+		Tag    *Tag `xml:"Tag,omitempty"`
+	}
+	// Tag is a key-value pair of object tagging
+	Tag struct {
+		Key   string
+		Value string
 	}
 
 	// BucketLoggingStatus  is the container for logging status information
@@ -77,14 +145,94 @@ type (
 	WebsiteConfiguration struct {
 		IndexDocument IndexDocument
 		ErrorDocument ErrorDocument
+		RoutingRules  *RoutingRules `xml:"RoutingRules,omitempty"`
+	}
+	// RoutingRules are matched in the order of their RuleNumber
+	RoutingRules struct {
+		RoutingRule []RoutingRule
 	}
 	// IndexDocument is the container for the Suffix element
 	IndexDocument struct {
 		Suffix string
+		// SupportSubDir serves the index document of a subdirectory instead
+		// of the root one for a path ending with "/"
+		SupportSubDir bool `xml:"SupportSubDir,omitempty"`
+		// Type is how a path missing its "/" is handled with SupportSubDir,
+		// e.g. WebsiteSubDirRedirect
+		Type int `xml:"Type,omitempty"`
 	}
 	// ErrorDocument is the container for Key element No
 	ErrorDocument struct {
 		Key string
+		// HTTPStatus is the status the error document is served with, 404 if
+		// it is 0
+		HTTPStatus int `xml:"HttpStatus,omitempty"`
+	}
+	// RoutingRule redirects the requests matching its Condition
+	RoutingRule struct {
+		RuleNumber int
+		Condition  RoutingRuleCondition
+		Redirect   RoutingRuleRedirect
+	}
+	// RoutingRuleCondition matches the requests whose key and headers match
+	// all its set fields
+	RoutingRuleCondition struct {
+		KeyPrefixEquals string `xml:"KeyPrefixEquals,omitempty"`
+		KeySuffixEquals string `xml:"KeySuffixEquals,omitempty"`
+		// HTTPErrorCodeReturnedEquals matches the requests failing with the
+		// status, e.g. 404 for the missing objects
+		HTTPErrorCodeReturnedEquals int                        `xml:"HttpErrorCodeReturnedEquals,omitempty"`
+		IncludeHeader               []RoutingRuleIncludeHeader `xml:"IncludeHeader,omitempty"`
+	}
+	// RoutingRuleIncludeHeader matches the requests with the header Key whose
+	// value equals, starts or ends with the set fields
+	RoutingRuleIncludeHeader struct {
+		Key        string
+		Equals     string `xml:"Equals,omitempty"`
+		StartsWith string `xml:"StartsWith,omitempty"`
+		EndsWith   string `xml:"EndsWith,omitempty"`
+	}
+	// RoutingRuleRedirect is where a routing rule redirects to, RedirectType
+	// is RedirectMirror, RedirectExternal or RedirectAliCDN
+	RoutingRuleRedirect struct {
+		RedirectType string
+		// PassQueryString appends the query string of the request to the
+		// redirect
+		PassQueryString bool `xml:"PassQueryString,omitempty"`
+		// MirrorURL is the origin a RedirectMirror fetches the missing objects
+		// from
+		MirrorURL             string `xml:"MirrorURL,omitempty"`
+		MirrorPassQueryString bool   `xml:"MirrorPassQueryString,omitempty"`
+		// MirrorFollowRedirect follows the redirects of the origin, it is true
+		// if nil
+		MirrorFollowRedirect *bool          `xml:"MirrorFollowRedirect,omitempty"`
+		MirrorCheckMD5       bool           `xml:"MirrorCheckMd5,omitempty"`
+		MirrorHeaders        *MirrorHeaders `xml:"MirrorHeaders,omitempty"`
+		// Protocol, HostName and HTTPRedirectCode are for RedirectExternal and
+		// RedirectAliCDN
+		Protocol string `xml:"Protocol,omitempty"`
+		HostName string `xml:"HostName,omitempty"`
+		// ReplaceKeyPrefixWith replaces KeyPrefixEquals of the condition if
+		// EnableReplacePrefix is set, ReplaceKeyWith replaces the whole key
+		// and can contain ${key}
+		ReplaceKeyPrefixWith string `xml:"ReplaceKeyPrefixWith,omitempty"`
+		EnableReplacePrefix  bool   `xml:"EnableReplacePrefix,omitempty"`
+		ReplaceKeyWith       string `xml:"ReplaceKeyWith,omitempty"`
+		// HTTPRedirectCode is 301, 302 or 307, 302 if it is 0
+		HTTPRedirectCode int `xml:"HttpRedirectCode,omitempty"`
+	}
+	// MirrorHeaders are the request headers passed to the origin by a
+	// RedirectMirror, all of them but Remove if PassAll is set
+	MirrorHeaders struct {
+		PassAll bool              `xml:"PassAll,omitempty"`
+		Pass    []string          `xml:"Pass,omitempty"`
+		Remove  []string          `xml:"Remove,omitempty"`
+		Set     []MirrorHeaderSet `xml:"Set,omitempty"`
+	}
+	// MirrorHeaderSet is a header set on the requests to the origin
+	MirrorHeaderSet struct {
+		Key   string
+		Value string
 	}
 
 	// RefererConfiguration is the container for referer configuration
@@ -92,6 +240,36 @@ type (
 		AllowEmptyReferer bool
 		Referer           []string `xml:"RefererList>Referer"`
 	}
+
+	// LiveChannelConfiguration is the input for PutLiveChannel API and
+	// returned by GetLiveChannelInfo API
+	LiveChannelConfiguration struct {
+		XMLName     xml.Name `xml:"LiveChannelConfiguration"`
+		Description string   `xml:"Description,omitempty"`
+		// Status is LiveChannelEnabled or LiveChannelDisabled
+		Status   string `xml:"Status,omitempty"`
+		Target   LiveChannelTarget
+		Snapshot *LiveChannelSnapshot `xml:"Snapshot,omitempty"`
+	}
+	// LiveChannelTarget is the container for how a live stream is saved
+	LiveChannelTarget struct {
+		// Type is LiveChannelHLS
+		Type string
+		// FragDuration is the duration of a ts file in seconds
+		FragDuration int `xml:"FragDuration,omitempty"`
+		// FragCount is the number of ts files in the m3u8 playlist
+		FragCount    int    `xml:"FragCount,omitempty"`
+		PlaylistName string `xml:"PlaylistName,omitempty"`
+	}
+	// LiveChannelSnapshot is the container for the snapshots of a live
+	// stream, saved to DestBucket by the RAM role RoleName every Interval
+	// seconds and notified to the MNS topic NotifyTopic
+	LiveChannelSnapshot struct {
+		RoleName    string
+		DestBucket  string
+		NotifyTopic string
+		Interval    int
+	}
 )
 
 // BucketLocation is the option for setting bucket location when calling PutBucket
@@ -115,29 +293,24 @@ func XMLBody(obj interface{}) Option {
 			return err
 		}
 		req.ContentLength = int64(w.Len())
-		req.Body = ioutil.NopCloser(bytes.NewReader(w.Bytes()))
+		req.Body = nopCloser{bytes.NewReader(w.Bytes())}
 		return nil
 	}
 }
 
-// HTTPBody sets http.Request.Body and Content-Length/Type when possible
+// HTTPBody sets http.Request.Body and Content-Length/Type when possible. The
+// body is sent from its current position, but for an io.ReaderAt hashed by
+// ContentMD5, see there.
 func HTTPBody(body io.Reader) Option {
 	return func(req *http.Request) error {
 		rc, ok := body.(io.ReadCloser)
 		if !ok && body != nil {
-			rc = ioutil.NopCloser(body)
+			rc = nopCloser{body}
 		}
 		req.Body = rc
 		fileName := ""
-		switch v := body.(type) {
-		case *bytes.Buffer:
-			req.ContentLength = int64(v.Len())
-		case *bytes.Reader:
-			req.ContentLength = int64(v.Len())
-		case *strings.Reader:
-			req.ContentLength = int64(v.Len())
-		case *os.File:
-			req.ContentLength = tryGetFileSize(v)
+		if size, ok := bodySize(body); ok {
+			req.ContentLength = size
 		}
 		if req.Header.Get("Content-Type") == "" {
 			req.Header.Set("Content-Type", typeByExtension(fileName))
@@ -145,6 +318,39 @@ func HTTPBody(body io.Reader) Option {
 		return nil
 	}
 }
+
+// nopCloser is like ioutil.NopCloser but keeps the underlying io.Reader
+// accessible, so that options like ContentMD5 can seek it
+type nopCloser struct {
+	io.Reader
+}
+
+func (nopCloser) Close() error { return nil }
+
+// bodyReader returns the io.Reader wrapped by HTTPBody or XMLBody
+func bodyReader(body io.ReadCloser) io.Reader {
+	if rc, ok := body.(nopCloser); ok {
+		return rc.Reader
+	}
+	return body
+}
+
+// bodySize returns the number of bytes remaining in body when it can be known
+// without reading it.
+func bodySize(body io.Reader) (int64, bool) {
+	switch v := body.(type) {
+	case *bytes.Buffer:
+		return int64(v.Len()), true
+	case *bytes.Reader:
+		return int64(v.Len()), true
+	case *strings.Reader:
+		return int64(v.Len()), true
+	case *os.File:
+		return tryGetFileSize(v), true
+	}
+	return 0, false
+}
+
 func typeByExtension(file string) string {
 	typ := mime.TypeByExtension(path.Ext(file))
 	if typ == "" {
@@ -156,3 +362,18 @@ func tryGetFileSize(f *os.File) int64 {
 	fInfo, _ := f.Stat()
 	return fInfo.Size()
 }
+
+// MarshalXML implements xml.Marshaler, the Size of the parts returned by
+// ListParts is left out as OSS does not accept it
+func (c CompleteMultipartUpload) MarshalXML(enc *xml.Encoder, start xml.StartElement) error {
+	type part struct {
+		PartNumber   int
+		LastModified *time.Time `xml:"LastModified,omitempty"`
+		ETag         string
+	}
+	parts := make([]part, len(c.Part))
+	for i, p := range c.Part {
+		parts[i] = part{p.PartNumber, p.LastModified, p.ETag}
+	}
+	return enc.EncodeElement(struct{ Part []part }{parts}, start)
+}
@@ -0,0 +1,304 @@
+package oss
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"net/http"
+	"path"
+	"sort"
+	"strings"
+	"time"
+)
+
+type (
+	// BucketFS is a read-only fs.FS of the objects under a prefix of a
+	// bucket, "/" in the object keys separates directories. A directory
+	// exists if any object key starts with its path followed by "/", e.g. a
+	// folder created with an empty "dir/" object. An object has precedence
+	// over a directory with the same name.
+	BucketFS struct {
+		api           *API
+		bucket        string
+		prefix        string
+		readerOptions []ReaderOption
+	}
+
+	// objectInfo implements fs.FileInfo and fs.DirEntry
+	objectInfo struct {
+		name    string
+		size    int64
+		modTime time.Time
+		dir     bool
+		sys     interface{}
+	}
+
+	// objectFile is a file opened by BucketFS, it reads the object with
+	// Range requests
+	objectFile struct {
+		*ObjectReader
+		info *objectInfo
+	}
+
+	// dirFile is a directory opened by BucketFS, its entries are listed on
+	// the first call of ReadDir
+	dirFile struct {
+		fsys    *BucketFS
+		name    string
+		info    *objectInfo
+		entries []fs.DirEntry
+		listed  bool
+	}
+)
+
+var (
+	_ fs.ReadDirFS = (*BucketFS)(nil)
+	_ fs.StatFS    = (*BucketFS)(nil)
+	_ fs.SubFS     = (*BucketFS)(nil)
+)
+
+// NewBucketFS creates a BucketFS of the objects under prefix, which is
+// treated as a directory ("a/b" and "a/b/" are the same). The files are read
+// by ObjectReader with options.
+func NewBucketFS(api *API, bucket, prefix string, options ...ReaderOption) *BucketFS {
+	if prefix != "" && !strings.HasSuffix(prefix, "/") {
+		prefix += "/"
+	}
+	return &BucketFS{api: api, bucket: bucket, prefix: prefix, readerOptions: options}
+}
+
+// Open implements fs.FS
+func (f *BucketFS) Open(name string) (fs.File, error) {
+	info, err := f.stat("open", name)
+	if err != nil {
+		return nil, err
+	}
+	if info.dir {
+		return &dirFile{fsys: f, name: name, info: info}, nil
+	}
+	meta := info.sys.(*ObjectMeta)
+	return &objectFile{ObjectReader: newObjectReader(f.api, f.bucket, f.key(name), meta, f.readerOptions), info: info}, nil
+}
+
+// Stat implements fs.StatFS, Sys of the fs.FileInfo of a file is an
+// *ObjectMeta
+func (f *BucketFS) Stat(name string) (fs.FileInfo, error) {
+	info, err := f.stat("stat", name)
+	if err != nil {
+		return nil, err
+	}
+	return info, nil
+}
+
+// ReadDir implements fs.ReadDirFS, Sys of the fs.FileInfo of a file entry is
+// a Content
+func (f *BucketFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: fs.ErrInvalid}
+	}
+	if name != "." {
+		_, err := f.api.HeadObjectMeta(f.bucket, f.key(name))
+		if err == nil {
+			return nil, &fs.PathError{Op: "readdir", Path: name, Err: errors.New("not a directory")}
+		}
+		if !isNotFound(err) {
+			return nil, &fs.PathError{Op: "readdir", Path: name, Err: err}
+		}
+	}
+	entries, found, err := f.list(name)
+	if err != nil {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: err}
+	}
+	if !found && name != "." {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: fs.ErrNotExist}
+	}
+	return entries, nil
+}
+
+// Sub implements fs.SubFS
+func (f *BucketFS) Sub(dir string) (fs.FS, error) {
+	if !fs.ValidPath(dir) {
+		return nil, &fs.PathError{Op: "sub", Path: dir, Err: fs.ErrInvalid}
+	}
+	if dir == "." {
+		return f, nil
+	}
+	return NewBucketFS(f.api, f.bucket, f.key(dir), f.readerOptions...), nil
+}
+
+func (f *BucketFS) key(name string) string {
+	if name == "." {
+		return f.prefix
+	}
+	return f.prefix + name
+}
+
+// stat returns the object info of a file from HeadObject, or of a directory
+// if there is an object under it
+func (f *BucketFS) stat(op, name string) (*objectInfo, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: op, Path: name, Err: fs.ErrInvalid}
+	}
+	if name == "." {
+		return &objectInfo{name: ".", dir: true}, nil
+	}
+	meta, err := f.api.HeadObjectMeta(f.bucket, f.key(name))
+	if err == nil {
+		return &objectInfo{name: path.Base(name), size: meta.ContentLength, modTime: meta.LastModified, sys: meta}, nil
+	}
+	if !isNotFound(err) {
+		return nil, &fs.PathError{Op: op, Path: name, Err: err}
+	}
+	res, err := f.api.GetBucket(f.bucket, Prefix(f.key(name)+"/"), MaxKeys(1))
+	if err != nil {
+		return nil, &fs.PathError{Op: op, Path: name, Err: err}
+	}
+	if len(res.Contents) == 0 && len(res.CommonPrefixes) == 0 {
+		return nil, &fs.PathError{Op: op, Path: name, Err: fs.ErrNotExist}
+	}
+	return &objectInfo{name: path.Base(name), dir: true}, nil
+}
+
+// list returns the entries of a directory sorted by name, found is false if
+// there is no object under the directory
+func (f *BucketFS) list(name string) (entries []fs.DirEntry, found bool, err error) {
+	prefix := f.key(name)
+	if name != "." {
+		prefix += "/"
+	}
+	marker := ""
+	for {
+		res, err := f.api.GetBucket(f.bucket, Prefix(prefix), Delimiter("/"), Marker(marker))
+		if err != nil {
+			return nil, false, err
+		}
+		for _, content := range res.Contents {
+			found = true
+			entryName := strings.TrimPrefix(content.Key, prefix)
+			if !validEntryName(entryName) {
+				continue // the directory itself or an invalid path
+			}
+			entries = append(entries, &objectInfo{name: entryName, size: content.Size, modTime: content.LastModified, sys: content})
+		}
+		for _, commonPrefix := range res.CommonPrefixes {
+			found = true
+			entryName := strings.TrimSuffix(strings.TrimPrefix(commonPrefix, prefix), "/")
+			if !validEntryName(entryName) {
+				continue
+			}
+			entries = append(entries, &objectInfo{name: entryName, dir: true})
+		}
+		if !res.IsTruncated {
+			break
+		}
+		if marker = res.NextMarker; marker == "" {
+			marker = lastKey(res)
+		}
+	}
+	// a file hides the directory with the same name
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Name() == entries[j].Name() {
+			return !entries[i].IsDir()
+		}
+		return entries[i].Name() < entries[j].Name()
+	})
+	unique := entries[:0]
+	for i, entry := range entries {
+		if i == 0 || entry.Name() != entries[i-1].Name() {
+			unique = append(unique, entry)
+		}
+	}
+	return unique, found, nil
+}
+
+// isNotFound returns whether err is a 404 response, which has no error body
+// for a HEAD request
+func isNotFound(err error) bool {
+	e, ok := err.(*Error)
+	return ok && e.HTTPStatusCode == http.StatusNotFound
+}
+
+func validEntryName(name string) bool {
+	return name != "" && name != "." && name != ".." && !strings.Contains(name, "/")
+}
+
+// lastKey returns the marker of the next page when NextMarker is not
+// returned
+func lastKey(res *ListBucketResult) string {
+	last := ""
+	if n := len(res.Contents); n > 0 {
+		last = res.Contents[n-1].Key
+	}
+	if n := len(res.CommonPrefixes); n > 0 && res.CommonPrefixes[n-1] > last {
+		last = res.CommonPrefixes[n-1]
+	}
+	return last
+}
+
+// Name implements fs.FileInfo
+func (i *objectInfo) Name() string { return i.name }
+
+// Size implements fs.FileInfo
+func (i *objectInfo) Size() int64 { return i.size }
+
+// Mode implements fs.FileInfo, files are read-only
+func (i *objectInfo) Mode() fs.FileMode {
+	if i.dir {
+		return fs.ModeDir | 0555
+	}
+	return 0444
+}
+
+// ModTime implements fs.FileInfo, it is zero for a directory
+func (i *objectInfo) ModTime() time.Time { return i.modTime }
+
+// IsDir implements fs.FileInfo
+func (i *objectInfo) IsDir() bool { return i.dir }
+
+// Sys implements fs.FileInfo
+func (i *objectInfo) Sys() interface{} { return i.sys }
+
+// Type implements fs.DirEntry
+func (i *objectInfo) Type() fs.FileMode { return i.Mode().Type() }
+
+// Info implements fs.DirEntry
+func (i *objectInfo) Info() (fs.FileInfo, error) { return i, nil }
+
+// Stat implements fs.File
+func (f *objectFile) Stat() (fs.FileInfo, error) { return f.info, nil }
+
+// Stat implements fs.File
+func (d *dirFile) Stat() (fs.FileInfo, error) { return d.info, nil }
+
+// Read implements fs.File
+func (d *dirFile) Read([]byte) (int, error) {
+	return 0, &fs.PathError{Op: "read", Path: d.name, Err: errors.New("is a directory")}
+}
+
+// Close implements fs.File
+func (d *dirFile) Close() error { return nil }
+
+// ReadDir implements fs.ReadDirFile
+func (d *dirFile) ReadDir(n int) ([]fs.DirEntry, error) {
+	if !d.listed {
+		entries, _, err := d.fsys.list(d.name)
+		if err != nil {
+			return nil, &fs.PathError{Op: "readdir", Path: d.name, Err: err}
+		}
+		d.entries, d.listed = entries, true
+	}
+	if n <= 0 {
+		entries := d.entries
+		d.entries = nil
+		return entries, nil
+	}
+	if len(d.entries) == 0 {
+		return nil, io.EOF
+	}
+	if n > len(d.entries) {
+		n = len(d.entries)
+	}
+	entries := d.entries[:n]
+	d.entries = d.entries[n:]
+	return entries, nil
+}
@@ -0,0 +1,112 @@
+package oss_test
+
+import (
+	"errors"
+	"io/fs"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/aliyun/aliyun-oss-go-sdk/oss"
+	"github.com/aliyun/aliyun-oss-go-sdk/oss/osstest"
+)
+
+func newTestBucketFS(t *testing.T, server *osstest.Server) *oss.BucketFS {
+	api := server.API()
+	for key, data := range map[string]string{
+		"site/index.html":        "<html></html>",
+		"site/a.txt":             "a",
+		"site/dir/b.txt":         strings.Repeat("b", 3000),
+		"site/dir/sub/c.txt":     "c",
+		"site/empty/":            "",
+		"site/dup":               "file",
+		"site/dup/hidden.txt":    "hidden",
+		"site//invalid":          "invalid",
+		"outside/prefix.txt":     "outside",
+		"site-other/ignored.txt": "ignored",
+	} {
+		if err := api.PutObject(testBucketName, key, strings.NewReader(data)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return oss.NewBucketFS(api, testBucketName, "site", oss.ReaderBlockSize(1024))
+}
+
+func TestBucketFS(t *testing.T) {
+	server := newTestServer(t)
+	defer server.Close()
+	fsys := newTestBucketFS(t, server)
+	if err := fstest.TestFS(fsys, "index.html", "a.txt", "dir/b.txt", "dir/sub/c.txt", "dup"); err != nil {
+		t.Fatal(err)
+	}
+	data, err := fs.ReadFile(fsys, "dir/b.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := strings.Repeat("b", 3000); string(data) != expected {
+		t.Fatalf(expectBut, expected, string(data))
+	}
+	matches, err := fs.Glob(fsys, "*/*.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := "dir/b.txt"; len(matches) != 1 || matches[0] != expected {
+		t.Fatalf(expectBut, expected, matches)
+	}
+	if info, err := fs.Stat(fsys, "empty"); err != nil || !info.IsDir() {
+		t.Fatalf(expectBut, "empty directory", err)
+	}
+	info, err := fs.Stat(fsys, "dup")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.IsDir() || info.Size() != 4 {
+		t.Fatalf(expectBut, "file of 4 bytes", info)
+	}
+	if _, ok := info.Sys().(*oss.ObjectMeta); !ok {
+		t.Fatalf(expectBut, "*oss.ObjectMeta", info.Sys())
+	}
+	for _, name := range []string{"missing", "dir/missing", "/a.txt", "dir/"} {
+		if _, err := fsys.Open(name); err == nil {
+			t.Fatalf(testcaseExpectBut, name, "error", nil)
+		}
+	}
+	if _, err := fs.ReadDir(fsys, "a.txt"); err == nil {
+		t.Fatal("expect error but got nil")
+	}
+	if _, err := fs.ReadDir(fsys, "missing"); !errors.Is(err, fs.ErrNotExist) {
+		t.Fatalf(expectBut, fs.ErrNotExist, err)
+	}
+}
+
+func TestBucketFSSub(t *testing.T) {
+	server := newTestServer(t)
+	defer server.Close()
+	fsys, err := fs.Sub(newTestBucketFS(t, server), "dir")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := fstest.TestFS(fsys, "b.txt", "sub/c.txt"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestBucketFSReadDir(t *testing.T) {
+	server := newTestServer(t)
+	defer server.Close()
+	api := server.API()
+	for _, key := range []string{"a", "b/1", "b/2", "c", "d/1", "e"} {
+		api.PutObject(testBucketName, key, strings.NewReader(key))
+	}
+	entries, err := fs.ReadDir(oss.NewBucketFS(api, testBucketName, ""), ".")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	if expected := "a b c d e"; strings.Join(names, " ") != expected {
+		t.Fatalf(expectBut, expected, names)
+	}
+}
@@ -25,6 +25,34 @@ const (
 	ReplaceMeta = MetadataDirectiveType("REPLACE")
 )
 
+// StorageClassType contains possible values of storage class
+type StorageClassType string
+
+const (
+	// StandardStorage represents Standard storage class
+	StandardStorage = StorageClassType("Standard")
+	// IAStorage represents Infrequent Access storage class
+	IAStorage = StorageClassType("IA")
+	// ArchiveStorage represents Archive storage class
+	ArchiveStorage = StorageClassType("Archive")
+	// ColdArchiveStorage represents Cold Archive storage class
+	ColdArchiveStorage = StorageClassType("ColdArchive")
+)
+
+// ObjectType contains possible values of X-Oss-Object-Type header
+type ObjectType string
+
+const (
+	// NormalObject is an object uploaded by PutObject
+	NormalObject = ObjectType("Normal")
+	// AppendableObject is an object uploaded by AppendObject
+	AppendableObject = ObjectType("Appendable")
+	// MultipartObject is an object uploaded by multipart upload
+	MultipartObject = ObjectType("Multipart")
+	// SymlinkObject is a symbolic link created by PutSymlink
+	SymlinkObject = ObjectType("Symlink")
+)
+
 const (
 	gmtTime = "Mon, 02 Jan 2006 15:04:05 GMT"
 )
@@ -0,0 +1,231 @@
+package oss
+
+import (
+	"context"
+	"hash/crc64"
+	"io"
+	"net/http"
+	"strconv"
+)
+
+// crc64Table is the CRC-64/ECMA-182 table used by OSS (x-oss-hash-crc64ecma)
+var crc64Table = crc64.MakeTable(crc64.ECMA)
+
+type (
+	crc64Key struct{}
+
+	// crc64Request is the CRC-64 checking state of a request, it is attached
+	// to the request context by the crc64 options and only takes effect
+	// when the API object is created with CRC64Check(true)
+	crc64Request struct {
+		body        bool   // check the request body
+		init        uint64 // CRC-64 of the object before the request body
+		response    bool   // check the response body
+		expected    uint64 // precomputed CRC-64 of the object
+		hasExpected bool
+	}
+
+	// crc64Check computes the CRC-64 of a request or response body and
+	// verifies it against the x-oss-hash-crc64ecma header
+	crc64Check struct {
+		*crc64Request
+		reqBody  *crc64Reader
+		respBody *crc64Reader
+	}
+
+	crc64Reader struct {
+		io.ReadCloser
+		crc uint64
+		eof bool
+	}
+)
+
+// CRC64Check turns on end-to-end integrity checking: the CRC-64 of uploaded
+// and downloaded bodies is computed while streaming and compared with the
+// x-oss-hash-crc64ecma header returned by OSS. AppendObject only checks the
+// append at position 0, AppendObjectWithCRC checks the others. A *CRCError
+// is returned on mismatch.
+func CRC64Check(enabled bool) APIOption {
+	return func(a *API) {
+		a.crc64Check = enabled
+	}
+}
+
+// CRC64 returns the CRC-64/ECMA checksum that OSS computes for data
+func CRC64(data []byte) uint64 {
+	return crc64.Checksum(data, crc64Table)
+}
+
+func crc64State(req *http.Request) *crc64Request {
+	state, _ := req.Context().Value(crc64Key{}).(*crc64Request)
+	if state == nil {
+		state = &crc64Request{}
+		*req = *req.WithContext(context.WithValue(req.Context(), crc64Key{}, state))
+	}
+	return state
+}
+
+// crc64Body checks the request body, init is the CRC-64 of the existing
+// object contents the body is appended to
+func crc64Body(init uint64) Option {
+	return func(req *http.Request) error {
+		state := crc64State(req)
+		state.body, state.init = true, init
+		return nil
+	}
+}
+
+// crc64ResponseBody checks the response body when the whole object is
+// returned
+func crc64ResponseBody(req *http.Request) error {
+	crc64State(req).response = true
+	return nil
+}
+
+// crc64Expected checks the CRC-64 returned by OSS against a value computed
+// beforehand
+func crc64Expected(crc uint64) Option {
+	return func(req *http.Request) error {
+		state := crc64State(req)
+		state.expected, state.hasExpected = crc, true
+		return nil
+	}
+}
+
+// newCRC64Check returns nil when the request does not require CRC-64
+// checking, otherwise it starts computing the CRC-64 of the request body
+func newCRC64Check(req *http.Request) *crc64Check {
+	state, _ := req.Context().Value(crc64Key{}).(*crc64Request)
+	if state == nil {
+		return nil
+	}
+	c := &crc64Check{crc64Request: state}
+	if state.body && req.Body != nil {
+		c.reqBody = &crc64Reader{ReadCloser: req.Body, crc: state.init}
+		req.Body = c.reqBody
+	}
+	return c
+}
+
+// wrapResponse starts computing the CRC-64 of the response body
+func (c *crc64Check) wrapResponse(resp *http.Response) {
+	if c != nil && c.response && resp.StatusCode == http.StatusOK {
+		c.respBody = &crc64Reader{ReadCloser: resp.Body}
+		resp.Body = c.respBody
+	}
+}
+
+// verify compares the computed CRC-64 with the x-oss-hash-crc64ecma header.
+// It is skipped when OSS does not return the header.
+func (c *crc64Check) verify(resp *http.Response) error {
+	if c == nil {
+		return nil
+	}
+	value := resp.Header.Get("X-Oss-Hash-Crc64ecma")
+	if value == "" {
+		return nil
+	}
+	server, err := strconv.ParseUint(value, 10, 64)
+	if err != nil {
+		return err
+	}
+	var client uint64
+	switch {
+	case c.hasExpected:
+		client = c.expected
+	case c.reqBody != nil:
+		client = c.reqBody.crc
+	case c.respBody != nil && c.respBody.eof:
+		client = c.respBody.crc
+	default:
+		return nil
+	}
+	if client != server {
+		return &CRCError{
+			ClientCRC: client,
+			ServerCRC: server,
+			RequestID: resp.Header.Get("X-Oss-Request-Id"),
+		}
+	}
+	return nil
+}
+
+func (r *crc64Reader) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	r.crc = crc64.Update(r.crc, crc64Table, p[:n])
+	if err == io.EOF {
+		r.eof = true
+	}
+	return n, err
+}
+
+// crc64Combine returns the CRC-64 of the concatenation of two blocks of data
+// given the CRC-64 of each block and the length of the second one. It is
+// adapted from crc32_combine of zlib.
+func crc64Combine(crc1, crc2 uint64, len2 int64) uint64 {
+	if len2 <= 0 {
+		return crc1
+	}
+	var even, odd [64]uint64
+	// put operator for one zero bit in odd
+	odd[0] = 0xC96C5795D7870F42 // reversed ECMA polynomial
+	row := uint64(1)
+	for n := 1; n < 64; n++ {
+		odd[n] = row
+		row <<= 1
+	}
+	gf2MatrixSquare(&even, &odd) // operator for two zero bits
+	gf2MatrixSquare(&odd, &even) // operator for four zero bits
+	// apply len2 zeros to crc1 (first square will put the operator for one
+	// zero byte, eight zero bits, in even)
+	for {
+		gf2MatrixSquare(&even, &odd)
+		if len2&1 != 0 {
+			crc1 = gf2MatrixTimes(&even, crc1)
+		}
+		len2 >>= 1
+		if len2 == 0 {
+			break
+		}
+		gf2MatrixSquare(&odd, &even)
+		if len2&1 != 0 {
+			crc1 = gf2MatrixTimes(&odd, crc1)
+		}
+		len2 >>= 1
+		if len2 == 0 {
+			break
+		}
+	}
+	return crc1 ^ crc2
+}
+
+func gf2MatrixTimes(mat *[64]uint64, vec uint64) uint64 {
+	var sum uint64
+	for i := 0; vec != 0; i, vec = i+1, vec>>1 {
+		if vec&1 != 0 {
+			sum ^= mat[i]
+		}
+	}
+	return sum
+}
+
+func gf2MatrixSquare(square, mat *[64]uint64) {
+	for n := 0; n < 64; n++ {
+		square[n] = gf2MatrixTimes(mat, mat[n])
+	}
+}
+
+// partsCRC64 combines the CRC-64 of all parts, ok is false when the CRC-64 or
+// size of any part is unknown
+func partsCRC64(parts []Part) (crc uint64, ok bool) {
+	if len(parts) == 0 {
+		return 0, false
+	}
+	for _, part := range parts {
+		if part.CRC64 == nil || part.Size <= 0 {
+			return 0, false
+		}
+		crc = crc64Combine(crc, *part.CRC64, part.Size)
+	}
+	return crc, true
+}
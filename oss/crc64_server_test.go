@@ -0,0 +1,106 @@
+package oss_test
+
+import (
+	"bytes"
+	"encoding/xml"
+	"strings"
+	"testing"
+
+	"github.com/aliyun/aliyun-oss-go-sdk/oss"
+)
+
+func TestCRC64CheckPutGetObject(t *testing.T) {
+	server := newTestServer(t)
+	defer server.Close()
+	transport := &testTransport{}
+	api := server.API(oss.CRC64Check(true), transport.client())
+	data := testPlaintext(1000)
+	if err := api.PutObject(testBucketName, testObjectName, bytes.NewReader(data)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := api.GetObject(testBucketName, testObjectName, new(bytes.Buffer)); err != nil {
+		t.Fatal(err)
+	}
+	transport.setBadCRC(true)
+	if err := api.PutObject(testBucketName, testObjectName, bytes.NewReader(data)); !isCRCError(err) {
+		t.Fatalf(expectBut, "CRCError", err)
+	}
+	if _, err := api.GetObject(testBucketName, testObjectName, new(bytes.Buffer)); !isCRCError(err) {
+		t.Fatalf(expectBut, "CRCError", err)
+	}
+	// a partial read is not checked
+	if _, err := api.GetObject(testBucketName, testObjectName, new(bytes.Buffer), oss.Range("bytes=0-9")); err != nil {
+		t.Fatal(err)
+	}
+	// checking is off by default
+	if _, err := server.API(transport.client()).GetObject(testBucketName, testObjectName, new(bytes.Buffer)); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCRC64CheckAppendObject(t *testing.T) {
+	server := newTestServer(t)
+	defer server.Close()
+	transport := &testTransport{}
+	api := server.API(oss.CRC64Check(true), transport.client())
+	data := testPlaintext(300)
+	res, err := api.AppendObjectWithCRC(testBucketName, testObjectName, bytes.NewReader(data[:100]), 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err = api.AppendObjectWithCRC(testBucketName, testObjectName, bytes.NewReader(data[100:200]), res.NextPosition, res.CRC64)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := oss.CRC64(data[:200]); res.CRC64 != expected {
+		t.Fatalf(expectBut, expected, res.CRC64)
+	}
+	if _, err := api.AppendObjectWithCRC(testBucketName, testObjectName, bytes.NewReader(data[200:]), res.NextPosition, 1); !isCRCError(err) {
+		t.Fatalf(expectBut, "CRCError", err)
+	}
+	transport.setBadCRC(true)
+	if _, err := api.AppendObject(testBucketName, "new", bytes.NewReader(data), 0); !isCRCError(err) {
+		t.Fatalf(expectBut, "CRCError", err)
+	}
+}
+
+func TestCRC64CheckMultipart(t *testing.T) {
+	server := newTestServer(t)
+	defer server.Close()
+	transport := &testTransport{}
+	api := server.API(oss.CRC64Check(true), transport.client())
+	// the first part is at least 100KB
+	data := testPlaintext(150 * 1024)
+	upload := func() (*oss.InitiateMultipartUploadResult, *oss.CompleteMultipartUpload) {
+		upload, err := api.InitUpload(testBucketName, testObjectName)
+		if err != nil {
+			t.Fatal(err)
+		}
+		list := &oss.CompleteMultipartUpload{}
+		for i, part := range [][]byte{data[:100*1024], data[100*1024:]} {
+			res, err := api.UploadPart(testBucketName, testObjectName, upload.UploadID, i+1, bytes.NewReader(part), int64(len(part)))
+			if err != nil {
+				t.Fatal(err)
+			}
+			list.Part = append(list.Part, oss.Part{PartNumber: i + 1, ETag: res.ETag, Size: int64(len(part)), CRC64: res.CRC64})
+		}
+		return upload, list
+	}
+	res, list := upload()
+	if _, err := api.CompleteUpload(testBucketName, testObjectName, res.UploadID, list); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := xml.Marshal(list); strings.Contains(string(data), "<Size>") {
+		t.Fatalf(expectBut, "no Size", string(data))
+	}
+	res, list = upload()
+	*list.Part[0].CRC64++
+	if _, err := api.CompleteUpload(testBucketName, testObjectName, res.UploadID, list); !isCRCError(err) {
+		t.Fatalf(expectBut, "CRCError", err)
+	}
+	res, _ = upload()
+	transport.setBadCRC(true)
+	if _, err := api.UploadPart(testBucketName, testObjectName, res.UploadID, 1, bytes.NewReader(data), 10); !isCRCError(err) {
+		t.Fatalf(expectBut, "CRCError", err)
+	}
+}
@@ -0,0 +1,45 @@
+package oss
+
+import "testing"
+
+func TestCRC64(t *testing.T) {
+	// check value of CRC-64/XZ, the variant used by OSS
+	if expected, actual := uint64(0x995DC9BBDF1939FA), CRC64([]byte("123456789")); actual != expected {
+		t.Fatalf(expectBut, expected, actual)
+	}
+}
+
+func TestCRC64Combine(t *testing.T) {
+	data := testPlaintext(1000)
+	for _, n := range []int{0, 1, 7, 8, 500, 999, 1000} {
+		crc := crc64Combine(CRC64(data[:n]), CRC64(data[n:]), int64(len(data)-n))
+		if expected := CRC64(data); crc != expected {
+			t.Fatalf(testcaseExpectBut, n, expected, crc)
+		}
+	}
+	crcs := []uint64{CRC64(data[:300]), CRC64(data[300:])}
+	parts := []Part{
+		{PartNumber: 1, Size: 300, CRC64: &crcs[0]},
+		{PartNumber: 2, Size: 700, CRC64: &crcs[1]},
+	}
+	if crc, ok := partsCRC64(parts); !ok || crc != CRC64(data) {
+		t.Fatalf(expectBut, CRC64(data), crc)
+	}
+	// 0 is a CRC-64 like any other
+	crcs[1] = 0
+	if crc, ok := partsCRC64(parts); !ok || crc != crc64Combine(crcs[0], 0, 700) {
+		expected := crc64Combine(crcs[0], 0, 700)
+		t.Fatalf(expectBut, expected, []interface{}{crc, ok})
+	}
+	parts[1].CRC64 = nil
+	if _, ok := partsCRC64(parts); ok {
+		t.Fatal("expect unknown CRC-64")
+	}
+}
+
+func TestCRCError(t *testing.T) {
+	err := &CRCError{ClientCRC: 1, ServerCRC: 2, RequestID: "abc"}
+	if expected := "CRC-64 mismatch: client 1, server 2 (abc)"; err.Error() != expected {
+		t.Fatalf(expectBut, expected, err.Error())
+	}
+}
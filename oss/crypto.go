@@ -0,0 +1,462 @@
+package oss
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// Envelope headers stored along with a client-side encrypted object.
+const (
+	cseKey                  = "X-Oss-Meta-Client-Side-Encryption-Key"
+	cseStart                = "X-Oss-Meta-Client-Side-Encryption-Start"
+	cseCEKAlg               = "X-Oss-Meta-Client-Side-Encryption-Cek-Alg"
+	cseWrapAlg              = "X-Oss-Meta-Client-Side-Encryption-Wrap-Alg"
+	cseMatDesc              = "X-Oss-Meta-Client-Side-Encryption-Matdesc"
+	cseUnencryptedLength    = "X-Oss-Meta-Client-Side-Encryption-Unencrypted-Content-Length"
+	cseMultipartDataSize    = "X-Oss-Meta-Client-Side-Encryption-Data-Size"
+	cseMultipartPartSize    = "X-Oss-Meta-Client-Side-Encryption-Part-Size"
+	cseAlgorithmAESCTR      = "AES/CTR/NoPadding"
+	cseDataKeySize          = 32
+	cseBlockSize            = aes.BlockSize
+	cseDefaultContentLength = -1
+)
+
+var (
+	// ErrInvalidPartSize happens when the part size of an encrypted multipart
+	// upload is not a multiple of the cipher block size
+	ErrInvalidPartSize = errors.New("part size must be a positive multiple of 16")
+	// ErrNoMasterKey happens when no master key matches the material
+	// description of an encrypted object
+	ErrNoMasterKey = errors.New("no master key matches the encrypted object")
+	// ErrUnsupportedCipher happens when an encrypted object uses a content
+	// cipher other than AES/CTR/NoPadding
+	ErrUnsupportedCipher = errors.New("unsupported content encryption algorithm")
+)
+
+type (
+	// EncryptionClient encrypts objects before they are uploaded and decrypts
+	// them after they are downloaded. Each object is encrypted by AES-CTR with
+	// its own data key, and the data key is wrapped by a MasterKey and saved
+	// in X-Oss-Meta-Client-Side-Encryption-* headers.
+	//
+	// Only the operations which encrypt or decrypt are provided, the other
+	// ones of the API object would read or write the encrypted bytes.
+	EncryptionClient struct {
+		api        *API
+		masterKey  MasterKey
+		decryptors []MasterKey
+	}
+	// EncryptionOption provides optional configurations for an
+	// EncryptionClient object
+	EncryptionOption func(*EncryptionClient)
+
+	// EncryptedUpload is returned by EncryptionClient.InitUpload and holds the
+	// encryption context shared by all parts of a multipart upload
+	EncryptedUpload struct {
+		*InitiateMultipartUploadResult
+		PartSize int64
+		DataSize int64
+		envelope *envelope
+	}
+
+	// envelope contains the plaintext data key and IV of an object
+	envelope struct {
+		key     []byte
+		iv      []byte
+		wrapAlg string
+		matDesc string
+	}
+)
+
+// NewEncryptionClient creates an EncryptionClient that encrypts new objects
+// with masterKey
+func NewEncryptionClient(api *API, masterKey MasterKey, options ...EncryptionOption) *EncryptionClient {
+	c := &EncryptionClient{
+		api:        api,
+		masterKey:  masterKey,
+		decryptors: []MasterKey{masterKey},
+	}
+	for _, option := range options {
+		option(c)
+	}
+	return c
+}
+
+// DecryptionKeys adds master keys used only for decrypting objects encrypted
+// earlier with other keys, e.g. after a key rotation. The key whose material
+// description matches the object is chosen.
+func DecryptionKeys(keys ...MasterKey) EncryptionOption {
+	return func(c *EncryptionClient) {
+		c.decryptors = append(c.decryptors, keys...)
+	}
+}
+
+// PutObject encrypts the contents read from an io.Reader and uploads it
+func (c *EncryptionClient) PutObject(bucket, object string, rd io.Reader, options ...Option) error {
+	env, err := newEnvelope(c.masterKey)
+	if err != nil {
+		return err
+	}
+	size, ok := bodySize(rd)
+	if !ok {
+		size = cseDefaultContentLength
+	}
+	envOptions, err := env.options(c.masterKey, size)
+	if err != nil {
+		return err
+	}
+	options = append([]Option{HTTPBody(env.encrypt(rd, 0)), crc64Body(0)}, options...)
+	if ok {
+		options = append(options, ContentLength(size))
+	}
+	return c.api.Do("PUT", bucket, object, nil, append(options, envOptions...)...)
+}
+
+// GetObject downloads an object, decrypts it and writes it to an io.Writer.
+// Range option is supported: the requested range is extended to the cipher
+// block boundary when sent and trimmed again after decryption. Objects
+// without an encryption envelope are written as they are.
+func (c *EncryptionClient) GetObject(bucket, object string, w io.Writer, options ...Option) (res Header, _ error) {
+	result := &decryptingBody{Writer: w, Header: &res, client: c}
+	// a new slice, so that calls sharing options do not race on it
+	opts := make([]Option, 0, len(options)+2)
+	opts = append(append(append(opts, crc64ResponseBody), options...), result.alignRange)
+	return res, c.api.Do("GET", bucket, object, result, opts...)
+}
+
+// InitUpload starts an encrypted multipart upload. partSize is the size of
+// every part except the last one and must be a multiple of 16, dataSize is
+// the total size of the object or 0 when unknown.
+func (c *EncryptionClient) InitUpload(bucket, object string, partSize, dataSize int64, options ...Option) (*EncryptedUpload, error) {
+	if partSize <= 0 || partSize%cseBlockSize != 0 {
+		return nil, ErrInvalidPartSize
+	}
+	env, err := newEnvelope(c.masterKey)
+	if err != nil {
+		return nil, err
+	}
+	envOptions, err := env.options(c.masterKey, cseDefaultContentLength)
+	if err != nil {
+		return nil, err
+	}
+	envOptions = append(envOptions, setHeader(cseMultipartPartSize, strconv.FormatInt(partSize, 10)))
+	if dataSize > 0 {
+		envOptions = append(envOptions, setHeader(cseMultipartDataSize, strconv.FormatInt(dataSize, 10)))
+	}
+	res, err := c.api.InitUpload(bucket, object, append(append([]Option{}, options...), envOptions...)...)
+	if err != nil {
+		return nil, err
+	}
+	return &EncryptedUpload{
+		InitiateMultipartUploadResult: res,
+		PartSize:                      partSize,
+		DataSize:                      dataSize,
+		envelope:                      env,
+	}, nil
+}
+
+// UploadPart encrypts and uploads a part of an encrypted multipart upload.
+// size must equal upload.PartSize except for the last part.
+func (c *EncryptionClient) UploadPart(upload *EncryptedUpload, partNumber int, rd io.Reader, size int64, options ...Option) (*UploadPartResult, error) {
+	if size > upload.PartSize {
+		return nil, ErrInvalidPartSize
+	}
+	offset := int64(partNumber-1) * upload.PartSize
+	rd = upload.envelope.encrypt(&io.LimitedReader{R: rd, N: size}, offset)
+	return c.api.UploadPart(upload.Bucket, upload.Key, upload.UploadID, partNumber, rd, size, options...)
+}
+
+// CompleteUpload completes an encrypted multipart upload
+func (c *EncryptionClient) CompleteUpload(upload *EncryptedUpload, list *CompleteMultipartUpload) (*CompleteMultipartUploadResult, error) {
+	return c.api.CompleteUpload(upload.Bucket, upload.Key, upload.UploadID, list)
+}
+
+// AbortUpload aborts an encrypted multipart upload
+func (c *EncryptionClient) AbortUpload(upload *EncryptedUpload) error {
+	return c.api.AbortUpload(upload.Bucket, upload.Key, upload.UploadID)
+}
+
+// masterKeyFor returns the master key that can unwrap an object's data key
+func (c *EncryptionClient) masterKeyFor(wrapAlg, matDesc string) (MasterKey, error) {
+	for _, key := range c.decryptors {
+		if key.WrapAlgorithm() != wrapAlg {
+			continue
+		}
+		desc, err := encodeMatDesc(key.MaterialDescription())
+		if err != nil {
+			return nil, err
+		}
+		if desc == matDesc {
+			return key, nil
+		}
+	}
+	return nil, ErrNoMasterKey
+}
+
+func newEnvelope(masterKey MasterKey) (*envelope, error) {
+	env := &envelope{
+		key:     make([]byte, cseDataKeySize),
+		iv:      make([]byte, cseBlockSize),
+		wrapAlg: masterKey.WrapAlgorithm(),
+	}
+	if _, err := io.ReadFull(rand.Reader, env.key); err != nil {
+		return nil, err
+	}
+	if _, err := io.ReadFull(rand.Reader, env.iv); err != nil {
+		return nil, err
+	}
+	matDesc, err := encodeMatDesc(masterKey.MaterialDescription())
+	if err != nil {
+		return nil, err
+	}
+	env.matDesc = matDesc
+	return env, nil
+}
+
+// options returns the header options that save the wrapped envelope
+func (e *envelope) options(masterKey MasterKey, plainSize int64) ([]Option, error) {
+	key, err := masterKey.Encrypt(e.key)
+	if err != nil {
+		return nil, err
+	}
+	iv, err := masterKey.Encrypt(e.iv)
+	if err != nil {
+		return nil, err
+	}
+	options := []Option{
+		setHeader(cseKey, base64.StdEncoding.EncodeToString(key)),
+		setHeader(cseStart, base64.StdEncoding.EncodeToString(iv)),
+		setHeader(cseCEKAlg, cseAlgorithmAESCTR),
+		setHeader(cseWrapAlg, e.wrapAlg),
+		setHeader(cseMatDesc, e.matDesc),
+	}
+	if plainSize >= 0 {
+		options = append(options, setHeader(cseUnencryptedLength, strconv.FormatInt(plainSize, 10)))
+	}
+	return options, nil
+}
+
+// stream returns the AES-CTR key stream positioned at offset
+func (e *envelope) stream(offset int64) cipher.Stream {
+	block, _ := aes.NewCipher(e.key)
+	iv := new(big.Int).SetBytes(e.iv)
+	iv.Add(iv, big.NewInt(offset/cseBlockSize))
+	counter := make([]byte, cseBlockSize)
+	ivBytes := iv.Bytes()
+	if len(ivBytes) > cseBlockSize {
+		// the counter wraps around at 2^128
+		ivBytes = ivBytes[len(ivBytes)-cseBlockSize:]
+	}
+	copy(counter[cseBlockSize-len(ivBytes):], ivBytes)
+	stream := cipher.NewCTR(block, counter)
+	if skip := offset % cseBlockSize; skip > 0 {
+		discard := make([]byte, skip)
+		stream.XORKeyStream(discard, discard)
+	}
+	return stream
+}
+
+// encrypt returns a reader encrypting rd whose first byte is at offset of
+// the object
+func (e *envelope) encrypt(rd io.Reader, offset int64) io.Reader {
+	return &cipher.StreamReader{S: e.stream(offset), R: rd}
+}
+
+// decrypt returns a writer decrypting data whose first byte is at offset of
+// the object
+func (e *envelope) decrypt(w io.Writer, offset int64) io.Writer {
+	return &cipher.StreamWriter{S: e.stream(offset), W: w}
+}
+
+// readEnvelope unwraps the envelope saved in the headers of an object. nil
+// is returned when the object is not client-side encrypted.
+func (c *EncryptionClient) readEnvelope(h http.Header) (*envelope, error) {
+	if h.Get(cseKey) == "" {
+		return nil, nil
+	}
+	if alg := h.Get(cseCEKAlg); alg != cseAlgorithmAESCTR {
+		return nil, ErrUnsupportedCipher
+	}
+	env := &envelope{wrapAlg: h.Get(cseWrapAlg), matDesc: h.Get(cseMatDesc)}
+	masterKey, err := c.masterKeyFor(env.wrapAlg, env.matDesc)
+	if err != nil {
+		return nil, err
+	}
+	if env.key, err = unwrapHeader(masterKey, h.Get(cseKey)); err != nil {
+		return nil, err
+	}
+	if env.iv, err = unwrapHeader(masterKey, h.Get(cseStart)); err != nil {
+		return nil, err
+	}
+	if len(env.key) != cseDataKeySize || len(env.iv) != cseBlockSize {
+		return nil, fmt.Errorf("invalid encryption envelope: key %d bytes, iv %d bytes", len(env.key), len(env.iv))
+	}
+	return env, nil
+}
+
+func unwrapHeader(masterKey MasterKey, value string) ([]byte, error) {
+	wrapped, err := base64.StdEncoding.DecodeString(value)
+	if err != nil {
+		return nil, err
+	}
+	return masterKey.Decrypt(wrapped)
+}
+
+func encodeMatDesc(desc map[string]string) (string, error) {
+	if len(desc) == 0 {
+		return "", nil
+	}
+	buf, err := json.Marshal(desc) // keys are sorted by encoding/json
+	return string(buf), err
+}
+
+// decryptingBody decrypts http.Response.Body into an io.Writer and also saves
+// the Header object
+type decryptingBody struct {
+	io.Writer
+	*Header
+	client *EncryptionClient
+
+	// start and end of the range requested by the caller, end is -1 when
+	// the range is open-ended, start is -1 when no range is requested
+	start, end int64
+}
+
+// alignRange is an Option appended after the caller's options that extends
+// the start of a Range header to the cipher block boundary
+func (r *decryptingBody) alignRange(req *http.Request) error {
+	r.start, r.end = -1, -1
+	start, end, ok := parseByteRange(req.Header.Get("Range"))
+	if !ok {
+		return nil
+	}
+	r.start, r.end = start, end
+	rng := fmt.Sprintf("bytes=%d-", start-start%cseBlockSize)
+	if end >= 0 {
+		rng += strconv.FormatInt(end, 10)
+	}
+	req.Header.Set("Range", rng)
+	return nil
+}
+
+// Parse implements ResponseParser
+func (r *decryptingBody) Parse(resp *http.Response) error {
+	*r.Header = copyHeader(resp.Header)
+	env, err := r.client.readEnvelope(resp.Header)
+	if err != nil {
+		return err
+	}
+	offset := int64(0)
+	if resp.StatusCode == http.StatusPartialContent {
+		if offset, _, _, err = parseContentRange(resp.Header.Get("Content-Range")); err != nil {
+			return err
+		}
+	}
+	// the aligned range, or the whole object if the range is ignored, is
+	// trimmed to the range requested
+	w := r.Writer
+	if r.start >= 0 {
+		if r.end >= 0 {
+			w = &limitWriter{W: w, N: r.end - r.start + 1}
+		}
+		if skip := r.start - offset; skip > 0 {
+			w = &skipWriter{W: w, N: skip}
+		}
+	}
+	if env == nil {
+		_, err = io.Copy(w, resp.Body)
+		return err
+	}
+	_, err = io.Copy(env.decrypt(w, offset), resp.Body)
+	return err
+}
+
+// skipWriter discards the first N bytes written to it
+type skipWriter struct {
+	W io.Writer
+	N int64
+}
+
+func (w *skipWriter) Write(p []byte) (int, error) {
+	n := len(p)
+	if w.N > 0 {
+		if int64(len(p)) <= w.N {
+			w.N -= int64(len(p))
+			return n, nil
+		}
+		p = p[w.N:]
+		w.N = 0
+	}
+	if _, err := w.W.Write(p); err != nil {
+		return 0, err
+	}
+	return n, nil
+}
+
+// limitWriter discards the bytes written to it after the first N
+type limitWriter struct {
+	W io.Writer
+	N int64
+}
+
+func (w *limitWriter) Write(p []byte) (int, error) {
+	n := len(p)
+	if int64(len(p)) > w.N {
+		p = p[:w.N]
+	}
+	w.N -= int64(len(p))
+	if len(p) > 0 {
+		if _, err := w.W.Write(p); err != nil {
+			return 0, err
+		}
+	}
+	return n, nil
+}
+
+// parseByteRange parses a single range of the form "bytes=start-[end]".
+// Suffix ranges ("bytes=-n") and multiple ranges are not parsed.
+func parseByteRange(value string) (start, end int64, ok bool) {
+	if !strings.HasPrefix(value, "bytes=") || strings.Contains(value, ",") {
+		return 0, 0, false
+	}
+	parts := strings.SplitN(strings.TrimPrefix(value, "bytes="), "-", 2)
+	if len(parts) != 2 || parts[0] == "" {
+		return 0, 0, false
+	}
+	start, err := strconv.ParseInt(strings.TrimSpace(parts[0]), 10, 64)
+	if err != nil || start < 0 {
+		return 0, 0, false
+	}
+	end = -1
+	if s := strings.TrimSpace(parts[1]); s != "" {
+		if end, err = strconv.ParseInt(s, 10, 64); err != nil || end < start {
+			return 0, 0, false
+		}
+	}
+	return start, end, true
+}
+
+// parseContentRange parses a Content-Range header of the form
+// "bytes start-end/size", size is -1 when it is "*"
+func parseContentRange(value string) (start, end, size int64, err error) {
+	if _, err = fmt.Sscanf(value, "bytes %d-%d/", &start, &end); err != nil {
+		return 0, 0, 0, fmt.Errorf("invalid Content-Range %q", value)
+	}
+	size = -1
+	if i := strings.LastIndex(value, "/"); i >= 0 && value[i+1:] != "*" {
+		if size, err = strconv.ParseInt(value[i+1:], 10, 64); err != nil {
+			return 0, 0, 0, fmt.Errorf("invalid Content-Range %q", value)
+		}
+	}
+	return start, end, size, nil
+}
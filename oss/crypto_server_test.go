@@ -0,0 +1,204 @@
+package oss_test
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/rsa"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/aliyun/aliyun-oss-go-sdk/oss"
+	"github.com/aliyun/aliyun-oss-go-sdk/oss/osstest"
+)
+
+func testMasterKeys(t *testing.T) []oss.MasterKey {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	kms := oss.NewLocalKMS()
+	if err := kms.CreateKey("key-1"); err != nil {
+		t.Fatal(err)
+	}
+	return []oss.MasterKey{
+		oss.NewRSAMasterKey(rsaKey, map[string]string{"desc": "rsa"}),
+		&oss.KMSMasterKey{Client: kms, KeyID: "key-1", MatDesc: map[string]string{"desc": "kms"}},
+	}
+}
+
+func TestEncryptionClientPutGet(t *testing.T) {
+	server := newTestServer(t)
+	defer server.Close()
+	plaintext := testPlaintext(1000)
+	for _, key := range testMasterKeys(t) {
+		client := oss.NewEncryptionClient(server.API(), key)
+		if err := client.PutObject(testBucketName, testObjectName, bytes.NewReader(plaintext)); err != nil {
+			t.Fatal(err)
+		}
+		stored := getObjectData(t, server.API(), testObjectName)
+		if len(stored) != len(plaintext) || bytes.Equal(stored, plaintext) {
+			t.Fatalf("expect %d encrypted bytes", len(plaintext))
+		}
+		for _, rng := range [][2]int{{0, 999}, {0, 15}, {3, 17}, {16, 31}, {100, 600}, {999, 999}, {517, -1}} {
+			buf := new(bytes.Buffer)
+			options := []oss.Option{}
+			expected := plaintext[rng[0]:]
+			if rng[1] >= 0 {
+				options = append(options, oss.Range(fmt.Sprintf("bytes=%d-%d", rng[0], rng[1])))
+				expected = plaintext[rng[0] : rng[1]+1]
+			} else {
+				options = append(options, oss.Range(fmt.Sprintf("bytes=%d-", rng[0])))
+			}
+			if _, err := client.GetObject(testBucketName, testObjectName, buf, options...); err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(buf.Bytes(), expected) {
+				t.Fatalf(testcaseExpectBut, rng, expected, buf.Bytes())
+			}
+		}
+		buf := new(bytes.Buffer)
+		header, err := client.GetObject(testBucketName, testObjectName, buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(buf.Bytes(), plaintext) {
+			t.Fatalf(expectBut, plaintext, buf.Bytes())
+		}
+		if expected, actual := "1000", header[oss.CSEUnencryptedLength]; len(actual) != 1 || actual[0] != expected {
+			t.Fatalf(expectBut, expected, actual)
+		}
+	}
+}
+
+func TestEncryptionClientUnknownSize(t *testing.T) {
+	server := newTestServer(t)
+	defer server.Close()
+	client := oss.NewEncryptionClient(server.API(), testMasterKeys(t)[1])
+	plaintext := testPlaintext(100)
+	if err := client.PutObject(testBucketName, testObjectName, io.MultiReader(bytes.NewReader(plaintext))); err != nil {
+		t.Fatal(err)
+	}
+	buf := new(bytes.Buffer)
+	if _, err := client.GetObject(testBucketName, testObjectName, buf); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(buf.Bytes(), plaintext) {
+		t.Fatalf(expectBut, plaintext, buf.Bytes())
+	}
+}
+
+func TestEncryptionClientMultipart(t *testing.T) {
+	server := newTestServer(t)
+	defer server.Close()
+	client := oss.NewEncryptionClient(server.API(), testMasterKeys(t)[0])
+	if _, err := client.InitUpload(testBucketName, testObjectName, 100, 0); err != oss.ErrInvalidPartSize {
+		t.Fatalf(expectBut, oss.ErrInvalidPartSize, err)
+	}
+	// parts are at least 100KB but the last one
+	const partSize = 112 * 1024
+	plaintext := testPlaintext(250 * 1024)
+	upload, err := client.InitUpload(testBucketName, testObjectName, partSize, int64(len(plaintext)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	list := &oss.CompleteMultipartUpload{}
+	for i := 0; i*partSize < len(plaintext); i++ {
+		part := plaintext[i*partSize:]
+		if len(part) > partSize {
+			part = part[:partSize]
+		}
+		res, err := client.UploadPart(upload, i+1, bytes.NewReader(part), int64(len(part)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		list.Part = append(list.Part, oss.Part{PartNumber: i + 1, ETag: res.ETag})
+	}
+	if _, err := client.CompleteUpload(upload, list); err != nil {
+		t.Fatal(err)
+	}
+	// a range across the first two parts
+	buf := new(bytes.Buffer)
+	if _, err := client.GetObject(testBucketName, testObjectName, buf, oss.Range(fmt.Sprintf("bytes=%d-%d", partSize-30, partSize+30))); err != nil {
+		t.Fatal(err)
+	}
+	if expected := plaintext[partSize-30 : partSize+31]; !bytes.Equal(buf.Bytes(), expected) {
+		t.Fatalf(expectBut, expected, buf.Bytes())
+	}
+}
+
+func TestEncryptionClientKeyRotation(t *testing.T) {
+	server := newTestServer(t)
+	defer server.Close()
+	keys := testMasterKeys(t)
+	if err := oss.NewEncryptionClient(server.API(), keys[0]).PutObject(testBucketName, testObjectName, strings.NewReader("abc")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := oss.NewEncryptionClient(server.API(), keys[1]).GetObject(testBucketName, testObjectName, new(bytes.Buffer)); err != oss.ErrNoMasterKey {
+		t.Fatalf(expectBut, oss.ErrNoMasterKey, err)
+	}
+	buf := new(bytes.Buffer)
+	if _, err := oss.NewEncryptionClient(server.API(), keys[1], oss.DecryptionKeys(keys[0])).GetObject(testBucketName, testObjectName, buf); err != nil {
+		t.Fatal(err)
+	}
+	if expected, actual := "abc", buf.String(); actual != expected {
+		t.Fatalf(expectBut, expected, actual)
+	}
+}
+
+func TestEncryptionClientPlainObject(t *testing.T) {
+	server := newTestServer(t)
+	defer server.Close()
+	if err := server.API().PutObject(testBucketName, testObjectName, strings.NewReader("plain")); err != nil {
+		t.Fatal(err)
+	}
+	buf := new(bytes.Buffer)
+	if _, err := oss.NewEncryptionClient(server.API(), testMasterKeys(t)[1]).GetObject(testBucketName, testObjectName, buf); err != nil {
+		t.Fatal(err)
+	}
+	if expected, actual := "plain", buf.String(); actual != expected {
+		t.Fatalf(expectBut, expected, actual)
+	}
+
+	if err := server.API().PutObject(testBucketName, testObjectName, strings.NewReader("0123456789abcdefghijklmnop")); err != nil {
+		t.Fatal(err)
+	}
+	// a server ignoring Range returns the whole object with 200
+	ignoreRange := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		req.Header.Del("Range")
+		server.Handler.ServeHTTP(w, req)
+	}))
+	defer ignoreRange.Close()
+	for _, api := range []*oss.API{server.API(), oss.New(strings.TrimPrefix(ignoreRange.URL, "http://"), osstest.DefaultAccessKeyID, osstest.DefaultAccessKeySecret)} {
+		for rng, expected := range map[string]string{"bytes=20-22": "klm", "bytes=20-": "klmnop", "bytes=3-17": "3456789abcdefgh"} {
+			buf := new(bytes.Buffer)
+			if _, err := oss.NewEncryptionClient(api, testMasterKeys(t)[1]).GetObject(testBucketName, testObjectName, buf, oss.Range(rng)); err != nil {
+				t.Fatal(err)
+			}
+			if actual := buf.String(); actual != expected {
+				t.Fatalf(testcaseExpectBut, rng, expected, actual)
+			}
+		}
+	}
+}
+
+func TestEncryptionClientSharedOptions(t *testing.T) {
+	server := newTestServer(t)
+	defer server.Close()
+	client := oss.NewEncryptionClient(server.API(), testMasterKeys(t)[1])
+	if err := client.PutObject(testBucketName, testObjectName, strings.NewReader("abc")); err != nil {
+		t.Fatal(err)
+	}
+	// the spare capacity of options must not be written by GetObject
+	options := make([]oss.Option, 1, 4)
+	options[0] = oss.Range("bytes=1-")
+	if _, err := client.GetObject(testBucketName, testObjectName, new(bytes.Buffer), options...); err != nil {
+		t.Fatal(err)
+	}
+	if spare := options[:4]; spare[1] != nil || spare[2] != nil || spare[3] != nil {
+		t.Fatalf(expectBut, "unchanged options", spare)
+	}
+}
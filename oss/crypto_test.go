@@ -0,0 +1,80 @@
+package oss
+
+import (
+	"bytes"
+	"io"
+	"testing"
+)
+
+func testPlaintext(n int) []byte {
+	buf := make([]byte, n)
+	for i := range buf {
+		buf[i] = byte(i * 7)
+	}
+	return buf
+}
+
+func TestEnvelopeStreamOffset(t *testing.T) {
+	kms := NewLocalKMS()
+	if err := kms.CreateKey("key-1"); err != nil {
+		t.Fatal(err)
+	}
+	env, err := newEnvelope(&KMSMasterKey{Client: kms, KeyID: "key-1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range env.iv {
+		env.iv[i] = 0xff // counter overflow
+	}
+	plaintext := testPlaintext(100)
+	ciphertext := new(bytes.Buffer)
+	io.Copy(ciphertext, env.encrypt(bytes.NewReader(plaintext), 0))
+	for _, offset := range []int64{0, 1, 15, 16, 17, 50} {
+		buf := new(bytes.Buffer)
+		w := env.decrypt(buf, offset)
+		w.Write(ciphertext.Bytes()[offset:])
+		if !bytes.Equal(buf.Bytes(), plaintext[offset:]) {
+			t.Fatalf(testcaseExpectBut, offset, plaintext[offset:], buf.Bytes())
+		}
+	}
+}
+
+func TestParseByteRange(t *testing.T) {
+	for _, testcase := range []struct {
+		value      string
+		start, end int64
+		ok         bool
+	}{
+		{"bytes=0-9", 0, 9, true},
+		{"bytes=10-", 10, -1, true},
+		{"bytes=-10", 0, 0, false},
+		{"bytes=9-0", 0, 0, false},
+		{"bytes=0-1,3-4", 0, 0, false},
+		{"items=0-1", 0, 0, false},
+		{"", 0, 0, false},
+	} {
+		start, end, ok := parseByteRange(testcase.value)
+		if start != testcase.start || end != testcase.end || ok != testcase.ok {
+			t.Fatalf(testcaseExpectBut, testcase.value, []interface{}{testcase.start, testcase.end, testcase.ok}, []interface{}{start, end, ok})
+		}
+	}
+}
+
+func TestLocalKMS(t *testing.T) {
+	kms := NewLocalKMS()
+	if _, err := kms.Encrypt("missing", []byte("a")); err != ErrUnknownKMSKey {
+		t.Fatalf(expectBut, ErrUnknownKMSKey, err)
+	}
+	kms.CreateKey("k")
+	ciphertext, err := kms.Encrypt("k", []byte("secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ciphertext[len(ciphertext)-1] ^= 1
+	if _, err := kms.Decrypt(ciphertext); err == nil {
+		t.Fatal("expect error but got nil")
+	}
+	if _, err := kms.Decrypt(nil); err != ErrUnknownKMSKey {
+		t.Fatalf(expectBut, ErrUnknownKMSKey, err)
+	}
+}
@@ -14,6 +14,12 @@ var (
 	ErrInvalidObjectName = errors.New("invalid object name")
 )
 
+// codeErrors are the errors matching the codes of an *Error with errors.Is
+var codeErrors = map[string]error{
+	"FileImmutable":           ErrObjectImmutable,
+	"WORMConfigurationLocked": ErrWormLocked,
+}
+
 // Error represents the XML error returned by OSS APIs
 type Error struct {
 	Code         string
@@ -35,9 +41,33 @@ func (e *Error) Error() string {
 	return fmt.Sprintf("%s (%s): %s (%s, %s)", e.Code, e.HTTPStatus, e.Message, e.RequestID, e.HostID)
 }
 
+// Is reports whether target is the error of the code, e.g. ErrObjectImmutable
+func (e *Error) Is(target error) bool {
+	err, ok := codeErrors[e.Code]
+	return ok && err == target
+}
+
+// IsErrorCode returns whether err is an *Error returned by OSS with the code
+func IsErrorCode(err error, code string) bool {
+	e, ok := err.(*Error)
+	return ok && e.Code == code
+}
+
 func parseError(resp *http.Response) error {
 	errObj := new(Error)
 	errObj.ParseError = xml.NewDecoder(resp.Body).Decode(&errObj)
 	errObj.HTTPStatusCode, errObj.HTTPStatus = resp.StatusCode, resp.Status
 	return errObj
 }
+
+// CRCError happens when the CRC-64 computed by the client differs from the
+// x-oss-hash-crc64ecma header returned by OSS
+type CRCError struct {
+	ClientCRC uint64
+	ServerCRC uint64
+	RequestID string
+}
+
+func (e *CRCError) Error() string {
+	return fmt.Sprintf("CRC-64 mismatch: client %d, server %d (%s)", e.ClientCRC, e.ServerCRC, e.RequestID)
+}
@@ -0,0 +1,13 @@
+package oss
+
+import "time"
+
+// CSEUnencryptedLength is the header of the plaintext size of an encrypted
+// object, for the tests of package oss_test
+const CSEUnencryptedLength = cseUnencryptedLength
+
+// SetClock replaces the clock of a RateLimiter, for the tests of package
+// oss_test
+func (l *RateLimiter) SetClock(now func() time.Time, sleep func(time.Duration)) {
+	l.now, l.sleep = now, sleep
+}
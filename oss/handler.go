@@ -0,0 +1,119 @@
+package oss
+
+import (
+	"io"
+	"net/http"
+	"path"
+	"strings"
+)
+
+// ObjectHandler is an http.Handler serving the objects of a bucket. The
+// request path is mapped to an object key under Prefix. Range and
+// conditional request headers are forwarded to OSS, and 206 and 304
+// responses are relayed with the relevant headers. The 4xx and 503 errors of
+// OSS are answered with their status, other failures with 502. The body is
+// streamed without buffering.
+type ObjectHandler struct {
+	API    *API
+	Bucket string
+	// Prefix is prepended to the cleaned request path without its leading
+	// "/" to get the object key
+	Prefix string
+	// IndexDocument is served for a path ending with "/", such a path is
+	// not found if it is empty
+	IndexDocument string
+	// Options are added to every request, e.g. TrafficLimit
+	Options []Option
+}
+
+// forwardedHeaders are the request headers forwarded to OSS
+var forwardedHeaders = []string{
+	"Range",
+	"If-Match",
+	"If-None-Match",
+	"If-Modified-Since",
+	"If-Unmodified-Since",
+}
+
+// relayedHeaders are the response headers relayed from OSS
+var relayedHeaders = []string{
+	"Accept-Ranges",
+	"Cache-Control",
+	"Content-Disposition",
+	"Content-Encoding",
+	"Content-Language",
+	"Content-Length",
+	"Content-Range",
+	"Content-Type",
+	"ETag",
+	"Expires",
+	"Last-Modified",
+}
+
+// relayedResponse copies the response of OSS to an http.ResponseWriter
+type relayedResponse struct {
+	w       http.ResponseWriter
+	written bool
+}
+
+// ServeHTTP implements http.Handler
+func (h *ObjectHandler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.Method != "GET" && req.Method != "HEAD" {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+	key, ok := h.objectKey(req.URL.Path)
+	if !ok {
+		http.NotFound(w, req)
+		return
+	}
+	options := append([]Option{}, h.Options...)
+	for _, name := range forwardedHeaders {
+		options = append(options, setHeader(name, req.Header.Get(name)))
+	}
+	relay := &relayedResponse{w: w}
+	err := h.API.Do(req.Method, h.Bucket, key, relay, options...)
+	if err == nil || relay.written {
+		return
+	}
+	status := http.StatusBadGateway
+	if e, ok := err.(*Error); ok {
+		switch code := e.HTTPStatusCode; {
+		case code == http.StatusNotModified, code/100 == 4, code == http.StatusServiceUnavailable:
+			status = code
+		}
+	}
+	http.Error(w, http.StatusText(status), status)
+}
+
+// objectKey maps a request path to an object key
+func (h *ObjectHandler) objectKey(urlPath string) (string, bool) {
+	name := strings.TrimPrefix(path.Clean("/"+urlPath), "/")
+	if strings.HasSuffix(urlPath, "/") || name == "" {
+		if h.IndexDocument == "" {
+			return "", false
+		}
+		if name != "" {
+			name += "/"
+		}
+		name += h.IndexDocument
+	}
+	return h.Prefix + name, true
+}
+
+func (r *relayedResponse) parseStream(resp *http.Response, done func(err error) error) error {
+	defer resp.Body.Close()
+	for _, name := range relayedHeaders {
+		if value := resp.Header.Get(name); value != "" {
+			r.w.Header().Set(name, value)
+		}
+	}
+	r.w.WriteHeader(resp.StatusCode)
+	r.written = true
+	if _, err := io.Copy(r.w, resp.Body); err != nil {
+		// the client sees a truncated body
+		return done(err)
+	}
+	return done(nil)
+}
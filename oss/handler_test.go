@@ -0,0 +1,113 @@
+package oss_test
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/aliyun/aliyun-oss-go-sdk/oss"
+)
+
+func TestObjectHandler(t *testing.T) {
+	server := newTestServer(t)
+	defer server.Close()
+	api := server.API()
+	for key, data := range map[string]string{
+		"www/index.html":      "home",
+		"www/docs/index.html": "docs",
+		"www/a.txt":           "0123456789",
+		"secret.txt":          "secret",
+	} {
+		if err := api.PutObject(testBucketName, key, strings.NewReader(data), oss.ContentType("text/plain"), oss.CacheControl("max-age=60")); err != nil {
+			t.Fatal(err)
+		}
+	}
+	handler := &oss.ObjectHandler{API: api, Bucket: testBucketName, Prefix: "www/", IndexDocument: "index.html"}
+	etag := etagOf([]byte("0123456789"))
+	header, err := api.HeadObject(testBucketName, "www/a.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	lastModified := http.Header(header).Get("Last-Modified")
+	for _, testcase := range []struct {
+		method, path string
+		header       map[string]string
+		status       int
+		body         string
+		respHeader   map[string]string
+	}{
+		{"GET", "/a.txt", nil, 200, "0123456789", map[string]string{
+			"ETag": etag, "Content-Type": "text/plain", "Cache-Control": "max-age=60", "Content-Length": "10",
+		}},
+		{"HEAD", "/a.txt", nil, 200, "", map[string]string{"ETag": etag, "Content-Length": "10"}},
+		{"GET", "/a.txt", map[string]string{"Range": "bytes=2-4"}, 206, "234", map[string]string{"Content-Range": "bytes 2-4/10"}},
+		{"GET", "/a.txt", map[string]string{"Range": "bytes=20-"}, 416, "Requested Range Not Satisfiable\n", nil},
+		{"GET", "/a.txt", map[string]string{"If-None-Match": etag}, 304, "", map[string]string{"ETag": etag}},
+		{"HEAD", "/a.txt", map[string]string{"If-None-Match": etag}, 304, "", nil},
+		{"GET", "/a.txt", map[string]string{"If-Modified-Since": lastModified}, 304, "", nil},
+		{"GET", "/a.txt", map[string]string{"If-Match": `"other"`}, 412, "Precondition Failed\n", nil},
+		{"GET", "/", nil, 200, "home", nil},
+		{"GET", "/docs/", nil, 200, "docs", nil},
+		{"GET", "/missing/", nil, 404, "Not Found\n", nil},
+		{"GET", "/../secret.txt", nil, 404, "Not Found\n", nil},
+		{"PUT", "/a.txt", nil, 405, "Method Not Allowed\n", map[string]string{"Allow": "GET, HEAD"}},
+	} {
+		req := httptest.NewRequest(testcase.method, testcase.path, nil)
+		for k, v := range testcase.header {
+			req.Header.Set(k, v)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		resp := rec.Result()
+		body, _ := ioutil.ReadAll(resp.Body)
+		if resp.StatusCode != testcase.status || string(body) != testcase.body {
+			t.Fatalf(testcaseExpectBut, testcase.method+" "+testcase.path, []interface{}{testcase.status, testcase.body}, []interface{}{resp.StatusCode, string(body)})
+		}
+		for k, v := range testcase.respHeader {
+			if actual := resp.Header.Get(k); actual != v {
+				t.Fatalf(testcaseExpectBut, testcase.method+" "+testcase.path+" "+k, v, actual)
+			}
+		}
+		if resp.Header.Get("X-Oss-Hash-Crc64ecma") != "" {
+			t.Fatalf(testcaseExpectBut, testcase.path, "no OSS headers", resp.Header)
+		}
+	}
+	handler.IndexDocument = ""
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf(expectBut, http.StatusNotFound, rec.Code)
+	}
+}
+
+func TestObjectHandlerErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		// the object key is the status of the error
+		codes := map[string]string{"403": "AccessDenied", "400": "InvalidArgument", "503": "SlowDown", "500": "InternalError"}
+		key := path.Base(req.URL.Path)
+		status, _ := strconv.Atoi(key)
+		w.Header().Set("Content-Type", "application/xml")
+		w.WriteHeader(status)
+		fmt.Fprintf(w, "<Error><Code>%s</Code></Error>", codes[key])
+	}))
+	handler := &oss.ObjectHandler{API: oss.New(strings.TrimPrefix(server.URL, "http://"), "id", "secret"), Bucket: testBucketName}
+	for path, status := range map[string]int{"/403": 403, "/400": 400, "/503": 503, "/500": 502} {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest("GET", path, nil))
+		if rec.Code != status {
+			t.Fatalf(testcaseExpectBut, path, status, rec.Code)
+		}
+	}
+	// a transport failure
+	server.Close()
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("GET", "/403", nil))
+	if rec.Code != http.StatusBadGateway {
+		t.Fatalf(expectBut, http.StatusBadGateway, rec.Code)
+	}
+}
@@ -0,0 +1,306 @@
+package oss
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// Modes of ResizeOptions
+const (
+	// ResizeLfit scales into the width and height, keeping the aspect ratio
+	ResizeLfit = "lfit"
+	// ResizeMfit scales to cover the width and height, keeping the ratio
+	ResizeMfit = "mfit"
+	// ResizeFill scales to cover and crops the center to the size
+	ResizeFill = "fill"
+	// ResizePad scales into the size and pads with Color
+	ResizePad = "pad"
+	// ResizeFixed scales to the size regardless of the aspect ratio
+	ResizeFixed = "fixed"
+)
+
+// Gravities of CropOptions and WatermarkOptions
+const (
+	GravityNorthWest = "nw"
+	GravityNorth     = "north"
+	GravityNorthEast = "ne"
+	GravityWest      = "west"
+	GravityCenter    = "center"
+	GravityEast      = "east"
+	GravitySouthWest = "sw"
+	GravitySouth     = "south"
+	GravitySouthEast = "se"
+)
+
+type (
+	// ImageProcess builds the image operations of x-oss-process, e.g.
+	// NewImageProcess().Resize(ResizeOptions{Width: 100}).Format("webp")
+	// is "image/resize,w_100/format,webp". Zero fields of the options are
+	// left out.
+	ImageProcess struct {
+		operations []string
+	}
+
+	// ResizeOptions are the parameters of the resize operation
+	ResizeOptions struct {
+		Mode   string
+		Width  int
+		Height int
+		// Long and Short are the sizes of the longer and shorter sides
+		Long  int
+		Short int
+		// Percentage scales by a percentage, from 1 to 1000
+		Percentage int
+		// Enlarge allows the result to be larger than the image
+		Enlarge bool
+		// Color is the RGB hex color padding in ResizePad mode, e.g. FFFFFF
+		Color string
+	}
+
+	// CropOptions are the parameters of the crop operation, X and Y are
+	// relative to the Gravity, north west by default
+	CropOptions struct {
+		X       int
+		Y       int
+		Width   int
+		Height  int
+		Gravity string
+	}
+
+	// WatermarkOptions are the parameters of the watermark operation, Text
+	// or Image is set. The text, font and image are base64-encoded when
+	// the operation is built.
+	WatermarkOptions struct {
+		Text string
+		// Font is the font type of the text, e.g. wqy-zenhei
+		Font  string
+		Color string
+		Size  int
+		// Shadow is the transparency of the text shadow, from 0 to 100
+		Shadow int
+		// Rotate rotates the text clockwise by degrees
+		Rotate int
+		// Fill tiles the text over the image
+		Fill bool
+		// Image is the key of a watermark image in the same bucket, which
+		// can carry its own x-oss-process, e.g. "logo.png?x-oss-process=image/resize,P_20"
+		Image string
+		// Transparency is the opacity of the watermark, from 0 to 100
+		Transparency int
+		Gravity      string
+		X            int
+		Y            int
+		// VOffset is the vertical offset of a watermark on the west,
+		// center or east
+		VOffset int
+	}
+
+	// ProcessResult is returned by ProcessObject API
+	ProcessResult struct {
+		Bucket   string `json:"bucket"`
+		Object   string `json:"object"`
+		FileSize int64  `json:"fileSize"`
+		Status   string `json:"status"`
+	}
+
+	// ImageInfo is returned by GetImageInfo API
+	ImageInfo struct {
+		Format   string
+		Width    int
+		Height   int
+		FileSize int64
+		// Fields are all the fields returned by image/info including
+		// EXIF, e.g. Orientation or DateTimeOriginal
+		Fields map[string]string
+	}
+)
+
+// NewImageProcess creates an empty ImageProcess
+func NewImageProcess() *ImageProcess {
+	return &ImageProcess{}
+}
+
+func (p *ImageProcess) add(name string, params ...string) *ImageProcess {
+	operation := name
+	for _, param := range params {
+		if param != "" {
+			operation += "," + param
+		}
+	}
+	p.operations = append(p.operations, operation)
+	return p
+}
+
+// imageParam returns "key_value", or "" for a zero value
+func imageParam(key string, value int) string {
+	if value == 0 {
+		return ""
+	}
+	return key + "_" + strconv.Itoa(value)
+}
+
+func imageStringParam(key, value string) string {
+	if value == "" {
+		return ""
+	}
+	return key + "_" + value
+}
+
+// imageBase64 encodes the text parameters of image operations
+func imageBase64(s string) string {
+	if s == "" {
+		return ""
+	}
+	return base64.RawURLEncoding.EncodeToString([]byte(s))
+}
+
+// Resize scales the image
+func (p *ImageProcess) Resize(o ResizeOptions) *ImageProcess {
+	limit := ""
+	if o.Enlarge {
+		limit = "limit_0"
+	}
+	return p.add("resize",
+		imageStringParam("m", o.Mode),
+		imageParam("w", o.Width),
+		imageParam("h", o.Height),
+		imageParam("l", o.Long),
+		imageParam("s", o.Short),
+		imageParam("p", o.Percentage),
+		limit,
+		imageStringParam("color", o.Color))
+}
+
+// Crop cuts a rectangle of the image
+func (p *ImageProcess) Crop(o CropOptions) *ImageProcess {
+	return p.add("crop",
+		imageParam("x", o.X),
+		imageParam("y", o.Y),
+		imageParam("w", o.Width),
+		imageParam("h", o.Height),
+		imageStringParam("g", o.Gravity))
+}
+
+// Rotate rotates the image clockwise by degrees from 0 to 360
+func (p *ImageProcess) Rotate(degrees int) *ImageProcess {
+	return p.add("rotate", strconv.Itoa(degrees))
+}
+
+// Watermark adds a text or image watermark
+func (p *ImageProcess) Watermark(o WatermarkOptions) *ImageProcess {
+	fill := ""
+	if o.Fill {
+		fill = "fill_1"
+	}
+	return p.add("watermark",
+		imageStringParam("text", imageBase64(o.Text)),
+		imageStringParam("type", imageBase64(o.Font)),
+		imageStringParam("color", o.Color),
+		imageParam("size", o.Size),
+		imageParam("shadow", o.Shadow),
+		imageParam("rotate", o.Rotate),
+		fill,
+		imageStringParam("image", imageBase64(o.Image)),
+		imageParam("t", o.Transparency),
+		imageStringParam("g", o.Gravity),
+		imageParam("x", o.X),
+		imageParam("y", o.Y),
+		imageParam("voffset", o.VOffset))
+}
+
+// Format converts the image, e.g. to jpg, png, webp, bmp, gif or tiff
+func (p *ImageProcess) Format(format string) *ImageProcess {
+	return p.add("format", format)
+}
+
+// Quality sets the quality of a JPG or WebP result to a percentage of the
+// quality of the image
+func (p *ImageProcess) Quality(percentage int) *ImageProcess {
+	return p.add("quality", imageParam("q", percentage))
+}
+
+// AbsoluteQuality sets the quality of a JPG or WebP result
+func (p *ImageProcess) AbsoluteQuality(quality int) *ImageProcess {
+	return p.add("quality", imageParam("Q", quality))
+}
+
+// Info returns the format, size and EXIF of the image as JSON instead of
+// the image, see GetImageInfo
+func (p *ImageProcess) Info() *ImageProcess {
+	return p.add("info")
+}
+
+// String returns the value of x-oss-process
+func (p *ImageProcess) String() string {
+	return strings.Join(append([]string{"image"}, p.operations...), "/")
+}
+
+// Process is an option to set x-oss-process parameter, e.g. to the String
+// of an ImageProcess for GetObject or SignURL
+func Process(value string) Option {
+	return func(req *http.Request) error {
+		if value == "" {
+			return nil
+		}
+		q := req.URL.Query()
+		q.Del("x-oss-process")
+		param := "x-oss-process=" + processEscape(value)
+		if encoded := q.Encode(); encoded != "" {
+			param = encoded + "&" + param
+		}
+		req.URL.RawQuery = param
+		return nil
+	}
+}
+
+// processEscape escapes x-oss-process but its separators, so that it is
+// signed as OSS verifies it
+func processEscape(value string) string {
+	return strings.NewReplacer("%2F", "/", "%2C", ",", "%7C", "|", "%3D", "=").Replace(url.QueryEscape(value))
+}
+
+// ProcessObject processes an object, e.g. with the String of an
+// ImageProcess, and saves the result as targetObject in targetBucket, which
+// is the bucket of the object if it is empty
+func (a *API) ProcessObject(bucket, object, process, targetBucket, targetObject string) (res *ProcessResult, _ error) {
+	saveAs := "|sys/saveas,o_" + imageBase64(targetObject)
+	if targetBucket != "" {
+		saveAs += ",b_" + imageBase64(targetBucket)
+	}
+	// the body is form-urlencoded, e.g. a "+" would be read as a space
+	body := "x-oss-process=" + processEscape(process+saveAs)
+	return res, a.Do("POST", bucket, object+"?x-oss-process", &res, HTTPBody(strings.NewReader(body)), ContentType("application/x-www-form-urlencoded"))
+}
+
+// GetImageInfo returns the format, size and EXIF of an image object
+func (a *API) GetImageInfo(bucket, object string, options ...Option) (res *ImageInfo, _ error) {
+	return res, a.Do("GET", bucket, object, &res, append(options, Process(NewImageProcess().Info().String()))...)
+}
+
+// Parse implements ResponseParser
+func (r *ProcessResult) Parse(resp *http.Response) error {
+	return json.NewDecoder(resp.Body).Decode(r)
+}
+
+// Parse implements ResponseParser
+func (r *ImageInfo) Parse(resp *http.Response) error {
+	var fields map[string]struct {
+		Value string `json:"value"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&fields); err != nil {
+		return err
+	}
+	r.Fields = make(map[string]string, len(fields))
+	for name, field := range fields {
+		r.Fields[name] = field.Value
+	}
+	r.Format = r.Fields["Format"]
+	r.Width, _ = strconv.Atoi(r.Fields["ImageWidth"])
+	r.Height, _ = strconv.Atoi(r.Fields["ImageHeight"])
+	r.FileSize, _ = strconv.ParseInt(r.Fields["FileSize"], 10, 64)
+	return nil
+}
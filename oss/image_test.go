@@ -0,0 +1,102 @@
+package oss
+
+import (
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestImageProcess(t *testing.T) {
+	for _, testcase := range []struct {
+		process  *ImageProcess
+		expected string
+	}{
+		{NewImageProcess().Resize(ResizeOptions{Mode: ResizeLfit, Width: 100, Height: 50}), "image/resize,m_lfit,w_100,h_50"},
+		{NewImageProcess().Resize(ResizeOptions{Mode: ResizePad, Long: 300, Enlarge: true, Color: "FF0000"}), "image/resize,m_pad,l_300,limit_0,color_FF0000"},
+		{NewImageProcess().Crop(CropOptions{X: 10, Width: 200, Height: 100, Gravity: GravityCenter}).Rotate(90), "image/crop,x_10,w_200,h_100,g_center/rotate,90"},
+		{NewImageProcess().Watermark(WatermarkOptions{Text: "Hello World", Size: 30, Gravity: GravitySouthEast, X: 10, Y: 10}), "image/watermark,text_SGVsbG8gV29ybGQ,size_30,g_se,x_10,y_10"},
+		{NewImageProcess().Watermark(WatermarkOptions{Image: "logo.png?x-oss-process=image/resize,P_20", Transparency: 50}), "image/watermark,image_bG9nby5wbmc_eC1vc3MtcHJvY2Vzcz1pbWFnZS9yZXNpemUsUF8yMA,t_50"},
+		{NewImageProcess().Format("webp").Quality(80).AbsoluteQuality(90), "image/format,webp/quality,q_80/quality,Q_90"},
+		{NewImageProcess().Info(), "image/info"},
+	} {
+		if actual := testcase.process.String(); actual != testcase.expected {
+			t.Fatalf(expectBut, testcase.expected, actual)
+		}
+	}
+}
+
+func TestProcessOption(t *testing.T) {
+	req, _ := http.NewRequest("GET", "http://bucket.oss-cn-hangzhou.aliyuncs.com/a.jpg?versionId=1", nil)
+	if err := Process("image/resize,w_100|sys/saveas,o_YQ")(req); err != nil {
+		t.Fatal(err)
+	}
+	if expected := "versionId=1&x-oss-process=image/resize,w_100|sys/saveas,o_YQ"; req.URL.RawQuery != expected {
+		t.Fatalf(expectBut, expected, req.URL.RawQuery)
+	}
+}
+
+func TestSignURL(t *testing.T) {
+	token := "CAIS+token/with=="
+	api := New("oss-cn-hangzhou.aliyuncs.com", testID, testSecret, SecurityToken(token))
+	expires := time.Unix(1500000000, 0)
+	signed, err := api.SignURL("GET", "bucket", "a.jpg", expires, Process("image/resize,w_100"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	u, _ := url.Parse(signed)
+	q := u.Query()
+	req, _ := http.NewRequest("GET", "http://bucket.oss-cn-hangzhou.aliyuncs.com/a.jpg?security-token="+token+"&x-oss-process=image/resize,w_100", nil)
+	req.Header.Set("Date", "1500000000")
+	auth := authorization{req: req, bucket: "bucket", secret: []byte(testSecret)}
+	if u.Host != "bucket.oss-cn-hangzhou.aliyuncs.com" || q.Get("OSSAccessKeyId") != testID || q.Get("Expires") != "1500000000" || q.Get("Signature") != auth.value() || q.Get("security-token") != token {
+		t.Fatalf(expectBut, auth.value(), signed)
+	}
+}
+
+func TestProcessObject(t *testing.T) {
+	var body string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		switch {
+		case req.Method == "POST" && req.URL.RawQuery == "x-oss-process":
+			data, _ := ioutil.ReadAll(req.Body)
+			body = string(data)
+			io.WriteString(w, `{"bucket":"target","fileSize":3267,"object":"thumb.jpg","status":"OK"}`)
+		case req.Method == "GET" && req.URL.Query().Get("x-oss-process") == "image/info":
+			io.WriteString(w, `{"FileSize":{"value":"21839"},"Format":{"value":"jpg"},"ImageHeight":{"value":"267"},"ImageWidth":{"value":"400"},"Orientation":{"value":"1"}}`)
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+	defer server.Close()
+	api := New(strings.TrimPrefix(server.URL, "http://"), testID, testSecret)
+	process := NewImageProcess().Resize(ResizeOptions{Width: 100}).String()
+	res, err := api.ProcessObject("bucket", "a.jpg", process, "target", "thumb.jpg")
+	if expected := (ProcessResult{Bucket: "target", Object: "thumb.jpg", FileSize: 3267, Status: "OK"}); err != nil || *res != expected {
+		t.Fatalf(expectBut, expected, []interface{}{res, err})
+	}
+	if expected := "x-oss-process=image/resize,w_100|sys/saveas,o_dGh1bWIuanBn,b_dGFyZ2V0"; body != expected {
+		t.Fatalf(expectBut, expected, body)
+	}
+	if _, err := api.ProcessObject("bucket", "a.jpg", "image/resize,w_100%+", "", "thumb.jpg"); err != nil {
+		t.Fatal(err)
+	}
+	if form, err := url.ParseQuery(body); err != nil || form.Get("x-oss-process") != "image/resize,w_100%+|sys/saveas,o_dGh1bWIuanBn" {
+		t.Fatalf(expectBut, "image/resize,w_100%+", []interface{}{body, err})
+	}
+	info, err := api.GetImageInfo("bucket", "a.jpg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := &ImageInfo{Format: "jpg", Width: 400, Height: 267, FileSize: 21839, Fields: map[string]string{
+		"FileSize": "21839", "Format": "jpg", "ImageHeight": "267", "ImageWidth": "400", "Orientation": "1",
+	}}
+	if !reflect.DeepEqual(info, expected) {
+		t.Fatalf(expectBut, expected, info)
+	}
+}
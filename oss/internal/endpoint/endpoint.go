@@ -0,0 +1,55 @@
+// Package endpoint tells the OSS endpoints and the buckets and objects of
+// their URLs, for the oss package and the transports of osstest
+package endpoint
+
+import (
+	"net"
+	"net/url"
+	"strings"
+)
+
+// Hosts are the domains of the OSS endpoints, whose buckets are addressed
+// as subdomains
+var Hosts = []string{
+	"aliyun-inc.com", "aliyuncs.com", "alibaba.net", "s3.amazonaws.com",
+}
+
+// IsOSSDomain returns whether a host, with an optional port, is an OSS
+// endpoint or one of its subdomains
+func IsOSSDomain(hostPort string) bool {
+	host, _, err := net.SplitHostPort(hostPort)
+	if err != nil {
+		host = hostPort
+	}
+	for _, ossHost := range Hosts {
+		if strings.HasSuffix(host, ossHost) {
+			return true
+		}
+	}
+	return false
+}
+
+// Target returns the bucket and object of a request URL sent by oss.API,
+// where the bucket is either a subdomain of an OSS endpoint or the first
+// path segment
+func Target(u *url.URL) (bucket, object string) {
+	path := strings.TrimPrefix(u.Path, "/")
+	if IsOSSDomain(u.Host) {
+		host := u.Hostname()
+		for _, ossHost := range Hosts {
+			if strings.HasSuffix(host, ossHost) {
+				// bucket.endpoint has one more label than the endpoint
+				if labels := strings.Split(strings.TrimSuffix(host, ossHost), "."); len(labels) > 2 {
+					return labels[0], path
+				}
+				break
+			}
+		}
+		return "", path
+	}
+	parts := strings.SplitN(path, "/", 2)
+	if len(parts) == 2 {
+		return parts[0], parts[1]
+	}
+	return parts[0], ""
+}
@@ -0,0 +1,23 @@
+package endpoint
+
+import (
+	"net/url"
+	"testing"
+)
+
+func TestTarget(t *testing.T) {
+	for _, testcase := range []struct {
+		url, bucket, object string
+	}{
+		{"http://bucket.oss-cn-hangzhou.aliyuncs.com/a/b", "bucket", "a/b"},
+		{"http://oss-cn-hangzhou.aliyuncs.com/", "", ""},
+		{"http://127.0.0.1:8080/bucket/a/b", "bucket", "a/b"},
+		{"http://127.0.0.1:8080/bucket/", "bucket", ""},
+		{"http://127.0.0.1:8080", "", ""},
+	} {
+		u, _ := url.Parse(testcase.url)
+		if bucket, object := Target(u); bucket != testcase.bucket || object != testcase.object {
+			t.Fatalf("testcase %v:\n--- EXPECT ---\n%v\n--- BUT GOT ---\n%v", testcase.url, []string{testcase.bucket, testcase.object}, []string{bucket, object})
+		}
+	}
+}
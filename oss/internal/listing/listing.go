@@ -0,0 +1,49 @@
+// Package listing pages object keys like GetBucket, for the fake OSS
+// servers of osstest and the tests of the oss package
+package listing
+
+import "strings"
+
+// Result is a page of keys
+type Result struct {
+	// Keys are the keys listed, CommonPrefixes the keys grouped by
+	// delimiter, both in order
+	Keys           []string
+	CommonPrefixes []string
+	// NextMarker is the marker of the next page if IsTruncated is set
+	NextMarker  string
+	IsTruncated bool
+}
+
+// List returns the page after marker of the sorted keys with prefix. The
+// keys containing delimiter after prefix are grouped by their common prefix
+// up to the delimiter. A page has at most maxKeys keys and common prefixes.
+func List(keys []string, prefix, delimiter, marker string, maxKeys int) *Result {
+	res := new(Result)
+	for _, key := range keys {
+		if !strings.HasPrefix(key, prefix) || key <= marker {
+			continue
+		}
+		next := key
+		if i := strings.Index(key[len(prefix):], delimiter); delimiter != "" && i >= 0 {
+			next = key[:len(prefix)+i+len(delimiter)]
+			if next <= marker || (len(res.CommonPrefixes) > 0 && res.CommonPrefixes[len(res.CommonPrefixes)-1] == next) {
+				continue
+			}
+		}
+		if len(res.Keys)+len(res.CommonPrefixes) == maxKeys {
+			res.IsTruncated = true
+			break
+		}
+		res.NextMarker = next
+		if next != key {
+			res.CommonPrefixes = append(res.CommonPrefixes, next)
+			continue
+		}
+		res.Keys = append(res.Keys, key)
+	}
+	if !res.IsTruncated {
+		res.NextMarker = ""
+	}
+	return res
+}
@@ -0,0 +1,27 @@
+package listing
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestList(t *testing.T) {
+	keys := []string{"a", "b/1", "b/2", "c/1", "c/d/1", "e"}
+	for _, testcase := range []struct {
+		prefix, delimiter, marker string
+		maxKeys                   int
+		expected                  Result
+	}{
+		{maxKeys: 100, expected: Result{Keys: keys}},
+		{delimiter: "/", maxKeys: 100, expected: Result{Keys: []string{"a", "e"}, CommonPrefixes: []string{"b/", "c/"}}},
+		{delimiter: "/", maxKeys: 2, expected: Result{Keys: []string{"a"}, CommonPrefixes: []string{"b/"}, NextMarker: "b/", IsTruncated: true}},
+		{delimiter: "/", marker: "b/", maxKeys: 2, expected: Result{Keys: []string{"e"}, CommonPrefixes: []string{"c/"}}},
+		{prefix: "c/", delimiter: "/", maxKeys: 100, expected: Result{Keys: []string{"c/1"}, CommonPrefixes: []string{"c/d/"}}},
+		{prefix: "b/", marker: "b/1", maxKeys: 100, expected: Result{Keys: []string{"b/2"}}},
+	} {
+		actual := List(keys, testcase.prefix, testcase.delimiter, testcase.marker, testcase.maxKeys)
+		if !reflect.DeepEqual(*actual, testcase.expected) {
+			t.Fatalf("testcase %+v:\n--- EXPECT ---\n%v\n--- BUT GOT ---\n%v", testcase, testcase.expected, *actual)
+		}
+	}
+}
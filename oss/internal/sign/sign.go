@@ -0,0 +1,88 @@
+// Package sign computes the signature of the OSS authorization, for the oss
+// package and the server of osstest
+package sign
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/base64"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+type (
+	kv struct {
+		key string
+		val string
+	}
+	kvSlice []kv
+)
+
+func (s kvSlice) Len() int           { return len(s) }
+func (s kvSlice) Less(i, j int) bool { return s[i].key < s[j].key }
+func (s kvSlice) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+
+// CanonicalizedOSSHeaders returns the x-oss-* headers as sorted "key:value"
+// lines
+func CanonicalizedOSSHeaders(header http.Header) []byte {
+	var kvs kvSlice
+	for key, vs := range header {
+		key = strings.ToLower(key)
+		if strings.HasPrefix(key, "x-oss-") {
+			for _, val := range vs {
+				kvs = append(kvs, kv{key, val})
+			}
+		}
+	}
+	sort.Sort(kvs)
+	var buf bytes.Buffer
+	for _, kv := range kvs {
+		buf.WriteString(kv.key)
+		buf.WriteByte(':')
+		buf.WriteString(kv.val)
+		buf.WriteByte('\n')
+	}
+	return buf.Bytes()
+}
+
+// CanonicalizedResource returns the path of a URL prefixed by its bucket,
+// with the query as it is sent
+func CanonicalizedResource(u *url.URL, bucket string) string {
+	uri := *u
+	uri.Scheme = ""
+	uri.Host = ""
+	uri.Path = bucket + uri.Path
+	return "/" + uri.String()
+}
+
+// StringToSign returns the data signed for a request to a bucket
+func StringToSign(req *http.Request, bucket string) []byte {
+	var w bytes.Buffer
+	w.WriteString(req.Method)
+	w.WriteByte('\n')
+	w.WriteString(req.Header.Get("Content-Md5"))
+	w.WriteByte('\n')
+	w.WriteString(req.Header.Get("Content-Type"))
+	w.WriteByte('\n')
+	w.WriteString(req.Header.Get("Date"))
+	w.WriteByte('\n')
+	w.Write(CanonicalizedOSSHeaders(req.Header))
+	w.WriteString(CanonicalizedResource(req.URL, bucket))
+	return w.Bytes()
+}
+
+// Signature returns the signature of a request to a bucket, as sent in the
+// Authorization header "OSS AccessKeyId:Signature"
+func Signature(req *http.Request, bucket string, secret []byte) string {
+	return HMACSHA1(StringToSign(req, bucket), secret)
+}
+
+// HMACSHA1 returns the HMAC-SHA1 of data in base64
+func HMACSHA1(data []byte, secret []byte) string {
+	h := hmac.New(sha1.New, secret)
+	h.Write(data)
+	return base64.StdEncoding.EncodeToString(h.Sum(nil))
+}
@@ -0,0 +1,22 @@
+package sign
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestSignature(t *testing.T) {
+	req, _ := http.NewRequest("PUT", "http://oss-example.oss-cn-hangzhou.aliyuncs.com/nelson?acl", nil)
+	req.Header.Set("Content-Type", "text/html")
+	req.Header.Set("Date", "Thu, 17 Nov 2005 18:49:58 GMT")
+	req.Header.Set("X-OSS-Meta-Author", "foo@bar.com")
+	req.Header.Set("X-OSS-Magic", "abracadabra")
+	req.Header.Set("Content-Md5", "ODBGOERFMDMzQTczRUY3NUE3NzA5QzdFNUYzMDQxNEM=")
+	if actual, expected := string(StringToSign(req, "oss-example")),
+		"PUT\nODBGOERFMDMzQTczRUY3NUE3NzA5QzdFNUYzMDQxNEM=\ntext/html\nThu, 17 Nov 2005 18:49:58 GMT\nx-oss-magic:abracadabra\nx-oss-meta-author:foo@bar.com\n/oss-example/nelson?acl"; actual != expected {
+		t.Fatalf("--- EXPECT ---\n%v\n--- BUT GOT ---\n%v", expected, actual)
+	}
+	if actual, expected := Signature(req, "oss-example", []byte("OtxrzxIsfpFjA7SwPzILwy8Bw21TLhquhboDYROV")), HMACSHA1(StringToSign(req, "oss-example"), []byte("OtxrzxIsfpFjA7SwPzILwy8Bw21TLhquhboDYROV")); actual != expected {
+		t.Fatalf("--- EXPECT ---\n%v\n--- BUT GOT ---\n%v", expected, actual)
+	}
+}
@@ -0,0 +1,362 @@
+package oss
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/md5"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Values of InventoryConfiguration
+const (
+	InventoryFormatCSV = "CSV"
+
+	InventoryDaily  = "Daily"
+	InventoryWeekly = "Weekly"
+
+	// InventoryAllVersions lists the noncurrent versions and delete markers
+	// as well
+	InventoryAllVersions     = "All"
+	InventoryCurrentVersions = "Current"
+)
+
+// Optional fields of an inventory report
+const (
+	InventoryFieldSize                = "Size"
+	InventoryFieldLastModifiedDate    = "LastModifiedDate"
+	InventoryFieldETag                = "ETag"
+	InventoryFieldStorageClass        = "StorageClass"
+	InventoryFieldIsMultipartUploaded = "IsMultipartUploaded"
+	InventoryFieldEncryptionStatus    = "EncryptionStatus"
+	InventoryFieldObjectACL           = "ObjectAcl"
+	InventoryFieldTaggingCount        = "TaggingCount"
+	InventoryFieldObjectType          = "ObjectType"
+	InventoryFieldCRC64               = "Crc64"
+)
+
+// ErrInventoryChecksum happens when a file of an inventory report does not
+// match the MD5 or the size in its manifest
+var ErrInventoryChecksum = errors.New("inventory file checksum mismatch")
+
+type (
+	// InventoryConfiguration is the input for PutBucketInventory API and
+	// returned by GetBucketInventory API
+	InventoryConfiguration struct {
+		ID        string `xml:"Id"`
+		IsEnabled bool
+		Filter    *InventoryFilter `xml:"Filter,omitempty"`
+		// Destination is where the reports are saved
+		Destination            InventoryDestination `xml:"Destination>OSSBucketDestination"`
+		Frequency              string               `xml:"Schedule>Frequency"`
+		IncludedObjectVersions string
+		OptionalFields         []string `xml:"OptionalFields>Field,omitempty"`
+	}
+	// InventoryFilter selects the objects in a report, the timestamps are
+	// in Unix seconds and the sizes in bytes
+	InventoryFilter struct {
+		Prefix                   string `xml:"Prefix,omitempty"`
+		LastModifyBeginTimeStamp int64  `xml:"LastModifyBeginTimeStamp,omitempty"`
+		LastModifyEndTimeStamp   int64  `xml:"LastModifyEndTimeStamp,omitempty"`
+		LowerSizeBound           int64  `xml:"LowerSizeBound,omitempty"`
+		UpperSizeBound           int64  `xml:"UpperSizeBound,omitempty"`
+		// StorageClass is a comma separated list of storage classes
+		StorageClass string `xml:"StorageClass,omitempty"`
+	}
+	// InventoryDestination is the bucket the reports are saved to
+	InventoryDestination struct {
+		Format    string
+		AccountID string `xml:"AccountId"`
+		// RoleArn is the RAM role writing the reports, e.g.
+		// acs:ram::123456789:role/AliyunOSSRole
+		RoleArn string
+		// Bucket is the ARN of the bucket, e.g. acs:oss:::bucket-name
+		Bucket     string
+		Prefix     string               `xml:"Prefix,omitempty"`
+		Encryption *InventoryEncryption `xml:"Encryption,omitempty"`
+	}
+	// InventoryEncryption encrypts the reports with either SSE-OSS or
+	// SSE-KMS
+	InventoryEncryption struct {
+		SSEOSS *InventorySSEOSS `xml:"SSE-OSS,omitempty"`
+		SSEKMS *InventorySSEKMS `xml:"SSE-KMS,omitempty"`
+	}
+	// InventorySSEOSS encrypts the reports with keys managed by OSS
+	InventorySSEOSS struct{}
+	// InventorySSEKMS encrypts the reports with a KMS key
+	InventorySSEKMS struct {
+		KeyID string `xml:"KeyId"`
+	}
+
+	// ListInventoryConfigurationsResult is returned by ListBucketInventory
+	// API
+	ListInventoryConfigurationsResult struct {
+		InventoryConfiguration []InventoryConfiguration
+		IsTruncated            bool
+		NextContinuationToken  string
+	}
+
+	// InventoryManifest is the manifest.json of an inventory report
+	InventoryManifest struct {
+		CreationTimestamp string `json:"creationTimestamp"`
+		DestinationBucket string `json:"destinationBucket"`
+		FileFormat        string `json:"fileFormat"`
+		// FileSchema is the comma separated fields of the rows
+		FileSchema   string          `json:"fileSchema"`
+		Files        []InventoryFile `json:"files"`
+		SourceBucket string          `json:"sourceBucket"`
+		Version      string          `json:"version"`
+	}
+	// InventoryFile is a gzip'ed CSV file of an inventory report
+	InventoryFile struct {
+		// MD5Checksum is the hex MD5 of the file
+		MD5Checksum string `json:"MD5checksum"`
+		Key         string `json:"key"`
+		Size        int64  `json:"size"`
+	}
+
+	// InventoryRecord is a row of an inventory report, the fields missing
+	// in the report are zero
+	InventoryRecord struct {
+		Bucket              string
+		Key                 string
+		VersionID           string
+		IsLatest            bool
+		IsDeleteMarker      bool
+		Size                int64
+		StorageClass        string
+		LastModified        time.Time
+		ETag                string
+		IsMultipartUploaded bool
+		EncryptionStatus    bool
+		ObjectACL           string
+		TaggingCount        int
+		ObjectType          string
+		CRC64               uint64
+	}
+
+	// InventoryReader reads the rows of an inventory report file by file,
+	// it must be closed by the caller
+	InventoryReader struct {
+		// Manifest is the manifest of the report
+		Manifest *InventoryManifest
+
+		api     *API
+		bucket  string
+		options []Option
+		schema  []string
+		next    int // index of the next file in Manifest.Files
+		file    *InventoryFile
+		stream  *ObjectStream
+		body    io.Reader // stream hashed into sum
+		sum     *fileSum
+		csv     *csv.Reader
+	}
+
+	// fileSum is the MD5 and size of the bytes written to it
+	fileSum struct {
+		hash.Hash
+		size int64
+	}
+)
+
+// PutBucketInventory creates or replaces an inventory configuration of a
+// bucket
+func (a *API) PutBucketInventory(bucket string, config *InventoryConfiguration) error {
+	return a.Do("PUT", bucket, inventoryResource(config.ID), nil, XMLBody(config))
+}
+
+// GetBucketInventory returns an inventory configuration of a bucket
+func (a *API) GetBucketInventory(bucket, id string) (res *InventoryConfiguration, _ error) {
+	return res, a.Do("GET", bucket, inventoryResource(id), &res)
+}
+
+// ListBucketInventory lists the inventory configurations of a bucket, 100
+// at most, continuationToken is the NextContinuationToken of the previous
+// page
+func (a *API) ListBucketInventory(bucket, continuationToken string) (res *ListInventoryConfigurationsResult, _ error) {
+	resource := "?inventory"
+	if continuationToken != "" {
+		resource = "?continuation-token=" + url.QueryEscape(continuationToken) + "&inventory"
+	}
+	return res, a.Do("GET", bucket, resource, &res)
+}
+
+// DeleteBucketInventory deletes an inventory configuration of a bucket, the
+// reports are kept
+func (a *API) DeleteBucketInventory(bucket, id string) error {
+	return a.Do("DELETE", bucket, inventoryResource(id), nil)
+}
+
+func inventoryResource(id string) string {
+	return "?inventory&inventoryId=" + url.QueryEscape(id)
+}
+
+// OpenInventory loads the manifest.json of an inventory report saved in
+// bucket, e.g. "prefix/source-bucket/id/2019-09-01T00-00Z/manifest.json",
+// and returns an InventoryReader of its rows. The options are added to the
+// GET requests of the manifest and the files.
+func (a *API) OpenInventory(bucket, manifestKey string, options ...Option) (*InventoryReader, error) {
+	var buf bytes.Buffer
+	if _, err := a.GetObject(bucket, manifestKey, &buf, options...); err != nil {
+		return nil, err
+	}
+	var manifest InventoryManifest
+	if err := json.Unmarshal(buf.Bytes(), &manifest); err != nil {
+		return nil, err
+	}
+	if manifest.FileFormat != InventoryFormatCSV {
+		return nil, fmt.Errorf("unsupported inventory file format %q", manifest.FileFormat)
+	}
+	schema := strings.Split(manifest.FileSchema, ",")
+	for i := range schema {
+		schema[i] = strings.TrimSpace(schema[i])
+	}
+	return &InventoryReader{Manifest: &manifest, api: a, bucket: bucket, options: options, schema: schema}, nil
+}
+
+// Read returns the next row of the report, or io.EOF after the last one. A
+// file is verified against its MD5 and size when its rows are read, and
+// ErrInventoryChecksum is returned instead of io.EOF of the file if they do
+// not match.
+func (r *InventoryReader) Read() (*InventoryRecord, error) {
+	for {
+		if r.csv == nil {
+			if r.next == len(r.Manifest.Files) {
+				return nil, io.EOF
+			}
+			if err := r.open(&r.Manifest.Files[r.next]); err != nil {
+				return nil, err
+			}
+			r.next++
+		}
+		row, err := r.csv.Read()
+		if err == io.EOF {
+			if err := r.verify(); err != nil {
+				return nil, err
+			}
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+		record, err := r.parse(row)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", r.file.Key, err)
+		}
+		return record, nil
+	}
+}
+
+// Close implements io.Closer
+func (r *InventoryReader) Close() error {
+	if r.stream == nil {
+		return nil
+	}
+	err := r.stream.Close()
+	r.stream, r.csv = nil, nil
+	return err
+}
+
+func (r *InventoryReader) open(file *InventoryFile) error {
+	stream, err := r.api.GetObjectReader(r.bucket, file.Key, r.options...)
+	if err != nil {
+		return err
+	}
+	r.file, r.stream, r.sum = file, stream, &fileSum{Hash: md5.New()}
+	r.body = io.TeeReader(stream, r.sum)
+	gz, err := gzip.NewReader(r.body)
+	if err != nil {
+		r.Close()
+		return err
+	}
+	r.csv = csv.NewReader(gz)
+	r.csv.FieldsPerRecord = len(r.schema)
+	r.csv.ReuseRecord = true
+	return nil
+}
+
+// verify reads the current file to EOF and closes it
+func (r *InventoryReader) verify() error {
+	if _, err := io.Copy(ioutil.Discard, r.body); err != nil {
+		r.Close()
+		return err
+	}
+	if err := r.Close(); err != nil {
+		return err
+	}
+	if !strings.EqualFold(hex.EncodeToString(r.sum.Sum(nil)), r.file.MD5Checksum) || r.file.Size != 0 && r.sum.size != r.file.Size {
+		return ErrInventoryChecksum
+	}
+	return nil
+}
+
+func (r *InventoryReader) parse(row []string) (*InventoryRecord, error) {
+	record := &InventoryRecord{}
+	var err error
+	for i, value := range row {
+		switch field := r.schema[i]; field {
+		case "Bucket":
+			record.Bucket = value
+		case "Key":
+			record.Key, err = url.QueryUnescape(value)
+		case "VersionId":
+			record.VersionID = value
+		case "IsLatest":
+			record.IsLatest, err = strconv.ParseBool(value)
+		case "IsDeleteMarker":
+			record.IsDeleteMarker, err = strconv.ParseBool(value)
+		case InventoryFieldSize:
+			record.Size, err = strconv.ParseInt(value, 10, 64)
+		case InventoryFieldStorageClass:
+			record.StorageClass = value
+		case InventoryFieldLastModifiedDate:
+			record.LastModified, err = time.Parse(time.RFC3339, value)
+		case InventoryFieldETag:
+			record.ETag = value
+		case InventoryFieldIsMultipartUploaded:
+			record.IsMultipartUploaded, err = strconv.ParseBool(value)
+		case InventoryFieldEncryptionStatus:
+			record.EncryptionStatus, err = strconv.ParseBool(value)
+		case InventoryFieldObjectACL:
+			record.ObjectACL = value
+		case InventoryFieldTaggingCount:
+			record.TaggingCount, err = strconv.Atoi(value)
+		case InventoryFieldObjectType:
+			record.ObjectType = value
+		case InventoryFieldCRC64:
+			record.CRC64, err = strconv.ParseUint(value, 10, 64)
+		}
+		if err != nil && value != "" {
+			return nil, fmt.Errorf("invalid %s %q", r.schema[i], value)
+		}
+		err = nil
+	}
+	return record, nil
+}
+
+func (s *fileSum) Write(p []byte) (int, error) {
+	s.size += int64(len(p))
+	return s.Hash.Write(p)
+}
+
+// Parse implements ResponseParser
+func (r *InventoryConfiguration) Parse(resp *http.Response) error {
+	return xml.NewDecoder(resp.Body).Decode(r)
+}
+
+// Parse implements ResponseParser
+func (r *ListInventoryConfigurationsResult) Parse(resp *http.Response) error {
+	return xml.NewDecoder(resp.Body).Decode(r)
+}
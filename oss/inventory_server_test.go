@@ -0,0 +1,94 @@
+package oss_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/aliyun/aliyun-oss-go-sdk/oss"
+)
+
+func TestInventoryReader(t *testing.T) {
+	server := newTestServer(t)
+	defer server.Close()
+	api := server.API()
+	files := []string{
+		"\"src\",\"a%2Fb%20c.txt\",\"100\",\"Standard\",\"2019-09-01T06:09:48Z\",\"5B3C1A2E053D763E1B002CC607C5A0FE\",\"false\",\"true\"\n",
+		"\"src\",\"big.bin\",\"104857600\",\"IA\",\"2019-09-02T06:09:48Z\",\"5B3C1A2E053D763E1B002CC607C5A0FE-20\",\"true\",\"false\"\n" +
+			"\"src\",\"empty\",\"0\",\"Archive\",\"2019-09-03T06:09:48Z\",\"D41D8CD98F00B204E9800998ECF8427E\",\"false\",\"false\"\n",
+	}
+	manifest := oss.InventoryManifest{
+		CreationTimestamp: "1567318188",
+		DestinationBucket: testBucketName,
+		FileFormat:        oss.InventoryFormatCSV,
+		FileSchema:        "Bucket, Key, Size, StorageClass, LastModifiedDate, ETag, IsMultipartUploaded, EncryptionStatus",
+		SourceBucket:      "src",
+		Version:           "2019-09-01",
+	}
+	for i, data := range files {
+		var buf bytes.Buffer
+		w := gzip.NewWriter(&buf)
+		io.WriteString(w, data)
+		w.Close()
+		key := "inventory/src/report1/data/" + string(rune('a'+i)) + ".csv.gz"
+		sum := md5.Sum(buf.Bytes())
+		manifest.Files = append(manifest.Files, oss.InventoryFile{MD5Checksum: hex.EncodeToString(sum[:]), Key: key, Size: int64(buf.Len())})
+		if err := api.PutObject(testBucketName, key, &buf); err != nil {
+			t.Fatal(err)
+		}
+	}
+	putManifest := func() {
+		data, _ := json.Marshal(&manifest)
+		if err := api.PutObject(testBucketName, "inventory/src/report1/manifest.json", bytes.NewReader(data)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	putManifest()
+	r, err := api.OpenInventory(testBucketName, "inventory/src/report1/manifest.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	var records []oss.InventoryRecord
+	for {
+		record, err := r.Read()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatal(err)
+		}
+		records = append(records, *record)
+	}
+	expected := []oss.InventoryRecord{
+		{Bucket: "src", Key: "a/b c.txt", Size: 100, StorageClass: "Standard", LastModified: time.Date(2019, 9, 1, 6, 9, 48, 0, time.UTC),
+			ETag: "5B3C1A2E053D763E1B002CC607C5A0FE", EncryptionStatus: true},
+		{Bucket: "src", Key: "big.bin", Size: 104857600, StorageClass: "IA", LastModified: time.Date(2019, 9, 2, 6, 9, 48, 0, time.UTC),
+			ETag: "5B3C1A2E053D763E1B002CC607C5A0FE-20", IsMultipartUploaded: true},
+		{Bucket: "src", Key: "empty", StorageClass: "Archive", LastModified: time.Date(2019, 9, 3, 6, 9, 48, 0, time.UTC),
+			ETag: "D41D8CD98F00B204E9800998ECF8427E"},
+	}
+	if !reflect.DeepEqual(records, expected) {
+		t.Fatalf(expectBut, expected, records)
+	}
+
+	manifest.Files[1].MD5Checksum = "00000000000000000000000000000000"
+	putManifest()
+	r, err = api.OpenInventory(testBucketName, "inventory/src/report1/manifest.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	n := 0
+	for ; err == nil; n++ {
+		_, err = r.Read()
+	}
+	if err != oss.ErrInventoryChecksum || n != 4 {
+		t.Fatalf(expectBut, oss.ErrInventoryChecksum, err)
+	}
+}
@@ -0,0 +1,139 @@
+package oss
+
+import "testing"
+
+var inventoryTestcases = []testcase{
+	{
+		name: "PutBucketInventory",
+		request: func(a *API) (interface{}, error) {
+			return nil, a.PutBucketInventory(testBucketName, &InventoryConfiguration{
+				ID:        "report1",
+				IsEnabled: true,
+				Filter:    &InventoryFilter{Prefix: "filterPrefix/", LowerSizeBound: 1024},
+				Destination: InventoryDestination{
+					Format:     InventoryFormatCSV,
+					AccountID:  "1000000000000000",
+					RoleArn:    "acs:ram::1000000000000000:role/AliyunOSSRole",
+					Bucket:     "acs:oss:::destination-bucket",
+					Prefix:     "prefix1",
+					Encryption: &InventoryEncryption{SSEKMS: &InventorySSEKMS{KeyID: "keyId"}},
+				},
+				Frequency:              InventoryDaily,
+				IncludedObjectVersions: InventoryAllVersions,
+				OptionalFields:         []string{InventoryFieldSize, InventoryFieldETag},
+			})
+		},
+		expectedRequest: `PUT /?inventory&inventoryId=report1 HTTP/1.1
+Host: bucket-name.oss-cn-hangzhou.aliyuncs.com
+User-Agent: %s
+Content-Length: 675
+Accept-Encoding: identity
+Authorization: OSS ayahghai0juiSie:jv3UePElRYBrMM07RlZPNwtzlCM=
+Date: %s
+
+<InventoryConfiguration><Id>report1</Id><IsEnabled>true</IsEnabled><Filter><Prefix>filterPrefix/</Prefix><LowerSizeBound>1024</LowerSizeBound></Filter><Destination><OSSBucketDestination><Format>CSV</Format><AccountId>1000000000000000</AccountId><RoleArn>acs:ram::1000000000000000:role/AliyunOSSRole</RoleArn><Bucket>acs:oss:::destination-bucket</Bucket><Prefix>prefix1</Prefix><Encryption><SSE-KMS><KeyId>keyId</KeyId></SSE-KMS></Encryption></OSSBucketDestination></Destination><Schedule><Frequency>Daily</Frequency></Schedule><IncludedObjectVersions>All</IncludedObjectVersions><OptionalFields><Field>Size</Field><Field>ETag</Field></OptionalFields></InventoryConfiguration>`,
+		response: `HTTP/1.1 200 OK
+x-oss-request-id: 5C1B138A109F4E405B2D8AEF
+Date: Sat, 30 Apr 2022 08:42:18 GMT
+Content-Length: 0
+Connection: close
+Server: AliyunOSS
+`,
+		expectedResponse: nil,
+	},
+
+	{
+		name: "ListBucketInventory",
+		request: func(a *API) (interface{}, error) {
+			r, err := a.ListBucketInventory(testBucketName, "report1")
+			return r, err
+		},
+		expectedRequest: `GET /?continuation-token=report1&inventory HTTP/1.1
+Host: bucket-name.oss-cn-hangzhou.aliyuncs.com
+User-Agent: %s
+Accept-Encoding: identity
+Authorization: OSS ayahghai0juiSie:FQDOHzKVS05pySgwuF84BIsD9Tk=
+Date: %s`,
+		response: `HTTP/1.1 200 OK
+x-oss-request-id: 5C1B138A109F4E405B2D8AEF
+Date: Sat, 30 Apr 2022 08:42:18 GMT
+Content-Type: application/xml
+Content-Length: 888
+Connection: close
+Server: AliyunOSS
+
+<?xml version="1.0" encoding="UTF-8"?>
+<ListInventoryConfigurationsResult>
+  <InventoryConfiguration>
+    <Id>report2</Id>
+    <IsEnabled>false</IsEnabled>
+    <Destination>
+      <OSSBucketDestination>
+        <Format>CSV</Format>
+        <AccountId>1000000000000000</AccountId>
+        <RoleArn>acs:ram::1000000000000000:role/AliyunOSSRole</RoleArn>
+        <Bucket>acs:oss:::destination-bucket</Bucket>
+        <Encryption>
+          <SSE-OSS></SSE-OSS>
+        </Encryption>
+      </OSSBucketDestination>
+    </Destination>
+    <Schedule>
+      <Frequency>Weekly</Frequency>
+    </Schedule>
+    <IncludedObjectVersions>Current</IncludedObjectVersions>
+    <OptionalFields>
+      <Field>StorageClass</Field>
+    </OptionalFields>
+  </InventoryConfiguration>
+  <IsTruncated>true</IsTruncated>
+  <NextContinuationToken>report3</NextContinuationToken>
+</ListInventoryConfigurationsResult>`,
+		expectedResponse: &ListInventoryConfigurationsResult{
+			InventoryConfiguration: []InventoryConfiguration{
+				{
+					ID: "report2",
+					Destination: InventoryDestination{
+						Format:     InventoryFormatCSV,
+						AccountID:  "1000000000000000",
+						RoleArn:    "acs:ram::1000000000000000:role/AliyunOSSRole",
+						Bucket:     "acs:oss:::destination-bucket",
+						Encryption: &InventoryEncryption{SSEOSS: &InventorySSEOSS{}},
+					},
+					Frequency:              InventoryWeekly,
+					IncludedObjectVersions: InventoryCurrentVersions,
+					OptionalFields:         []string{InventoryFieldStorageClass},
+				},
+			},
+			IsTruncated:           true,
+			NextContinuationToken: "report3",
+		},
+	},
+
+	{
+		name: "DeleteBucketInventory",
+		request: func(a *API) (interface{}, error) {
+			return nil, a.DeleteBucketInventory(testBucketName, "report1")
+		},
+		expectedRequest: `DELETE /?inventory&inventoryId=report1 HTTP/1.1
+Host: bucket-name.oss-cn-hangzhou.aliyuncs.com
+User-Agent: %s
+Accept-Encoding: identity
+Authorization: OSS ayahghai0juiSie:ALLPYTf5wHizXRjcmHpprqJQPdg=
+Date: %s`,
+		response: `HTTP/1.1 204 No Content
+x-oss-request-id: 5C1B138A109F4E405B2D8AEF
+Date: Sat, 30 Apr 2022 08:42:18 GMT
+Content-Length: 0
+Connection: close
+Server: AliyunOSS
+`,
+		expectedResponse: nil,
+	},
+}
+
+func TestInventoryAPI(t *testing.T) {
+	for i := range inventoryTestcases {
+		testAPI(t, &inventoryTestcases[i])
+	}
+}
@@ -0,0 +1,371 @@
+package oss
+
+import (
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Statuses of a LifecycleRule and an AccessMonitorConfiguration
+const (
+	LifecycleEnabled  = "Enabled"
+	LifecycleDisabled = "Disabled"
+)
+
+// AccessMonitorConfiguration is the input for PutBucketAccessMonitor API and
+// returned by GetBucketAccessMonitor API
+type AccessMonitorConfiguration struct {
+	// Status is LifecycleEnabled or LifecycleDisabled
+	Status string
+}
+
+// PutBucketAccessMonitor turns the tracking of the last access time of the
+// objects of a bucket on or off, it must be on for the lifecycle rules on
+// the access time
+func (a *API) PutBucketAccessMonitor(bucket, status string) error {
+	return a.Do("PUT", bucket, "?accessmonitor", nil, XMLBody(&AccessMonitorConfiguration{Status: status}))
+}
+
+// GetBucketAccessMonitor returns whether the last access time of the
+// objects of a bucket is tracked
+func (a *API) GetBucketAccessMonitor(bucket string) (res *AccessMonitorConfiguration, _ error) {
+	return res, a.Do("GET", bucket, "?accessmonitor", &res)
+}
+
+// MarshalXML implements xml.Marshaler, a zero Expiration is left out of its
+// LifecycleRule
+func (e Expiration) MarshalXML(enc *xml.Encoder, start xml.StartElement) error {
+	if e == (Expiration{}) {
+		return nil
+	}
+	type expiration Expiration
+	return enc.EncodeElement(expiration(e), start)
+}
+
+// Parse implements ResponseParser
+func (r *AccessMonitorConfiguration) Parse(resp *http.Response) error {
+	return xml.NewDecoder(resp.Body).Decode(r)
+}
+
+// Actions reported by SimulateLifecycle
+const (
+	LifecycleExpire     = "Expire"
+	LifecycleTransition = "Transition"
+)
+
+// storageClassRank orders the storage classes from the warmest
+var storageClassRank = map[StorageClassType]int{
+	"":                 0,
+	StandardStorage:    0,
+	IAStorage:          1,
+	ArchiveStorage:     2,
+	ColdArchiveStorage: 3,
+}
+
+type (
+	// LifecycleObject is an object or a version given to SimulateLifecycle
+	LifecycleObject struct {
+		Key          string
+		LastModified time.Time
+		Size         int64
+		StorageClass StorageClassType
+		Tags         map[string]string
+		// LastAccess is the last access time for the rules on it, the last
+		// modification if zero
+		LastAccess time.Time
+		// NoncurrentSince is when a previous version became previous, it is
+		// zero for the current versions
+		NoncurrentSince time.Time
+		// IsDeleteMarker is set for the current delete markers
+		IsDeleteMarker bool
+	}
+
+	// LifecycleReport is returned by SimulateLifecycle
+	LifecycleReport struct {
+		// Actions are sorted by time and key
+		Actions   []LifecycleAction
+		Conflicts []LifecycleConflict
+	}
+	// LifecycleAction is an expiration or a transition of an object by a
+	// rule
+	LifecycleAction struct {
+		Key    string
+		RuleID string
+		// Action is LifecycleExpire or LifecycleTransition
+		Action string
+		// StorageClass is the target of a transition
+		StorageClass StorageClassType
+		// Time is when the action is due, after the midnight UTC following
+		// the day count of the rule
+		Time time.Time
+		// Due is whether Time is not after the reference time
+		Due bool
+		// Noncurrent is set for the actions on previous versions
+		Noncurrent bool
+		// Superseded is set if the object is expired earlier or at the same
+		// time by another action
+		Superseded bool
+	}
+	// LifecycleConflict is a problem of a configuration, RuleIDs are the
+	// rules involved
+	LifecycleConflict struct {
+		RuleIDs []string
+		Reason  string
+	}
+)
+
+// LifecycleObjects returns the objects of a GetBucket listing for
+// SimulateLifecycle
+func LifecycleObjects(list *ListBucketResult) []LifecycleObject {
+	objects := make([]LifecycleObject, len(list.Contents))
+	for i, content := range list.Contents {
+		objects[i] = LifecycleObject{
+			Key:          content.Key,
+			LastModified: content.LastModified,
+			Size:         content.Size,
+			StorageClass: StorageClassType(content.StorageClass),
+		}
+	}
+	return objects
+}
+
+// SimulateLifecycle reports when the enabled rules of a configuration would
+// expire or transition the objects, and flags the rules overlapping or
+// conflicting with each other, without calling OSS. The actions due by now
+// are marked Due. The multipart uploads aborted by the rules are not
+// reported.
+func SimulateLifecycle(config *LifecycleConfiguration, objects []LifecycleObject, now time.Time) *LifecycleReport {
+	report := &LifecycleReport{Conflicts: lifecycleConflicts(config)}
+	versioned := make(map[string]bool)
+	for _, object := range objects {
+		if !object.NoncurrentSince.IsZero() {
+			versioned[object.Key] = true
+		}
+	}
+	for i := range objects {
+		object := &objects[i]
+		first := len(report.Actions)
+		for j := range config.Rule {
+			rule := &config.Rule[j]
+			if rule.Status != LifecycleEnabled || !rule.matches(object) {
+				continue
+			}
+			report.Actions = append(report.Actions, rule.actions(object, versioned[object.Key])...)
+		}
+		supersede(report.Actions[first:])
+	}
+	for i := range report.Actions {
+		report.Actions[i].Due = !report.Actions[i].Time.After(now)
+	}
+	sort.SliceStable(report.Actions, func(i, j int) bool {
+		a, b := &report.Actions[i], &report.Actions[j]
+		if !a.Time.Equal(b.Time) {
+			return a.Time.Before(b.Time)
+		}
+		return a.Key < b.Key
+	})
+	return report
+}
+
+func (r *LifecycleRule) matches(object *LifecycleObject) bool {
+	if !strings.HasPrefix(object.Key, r.Prefix) {
+		return false
+	}
+	for _, tag := range r.Tag {
+		if value, ok := object.Tags[tag.Key]; !ok || value != tag.Value {
+			return false
+		}
+	}
+	if r.Filter != nil {
+		for _, not := range r.Filter.Not {
+			if !strings.HasPrefix(object.Key, not.Prefix) {
+				continue
+			}
+			if not.Tag == nil {
+				return false
+			}
+			if value, ok := object.Tags[not.Tag.Key]; ok && value == not.Tag.Value {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// actions returns the actions of a rule matching an object, versioned is
+// whether the object has previous versions
+func (r *LifecycleRule) actions(object *LifecycleObject, versioned bool) []LifecycleAction {
+	var actions []LifecycleAction
+	add := func(action string, class StorageClassType, t time.Time) {
+		actions = append(actions, LifecycleAction{
+			Key:          object.Key,
+			RuleID:       r.ID,
+			Action:       action,
+			StorageClass: class,
+			Time:         t,
+			Noncurrent:   !object.NoncurrentSince.IsZero(),
+		})
+	}
+	if !object.NoncurrentSince.IsZero() {
+		if e := r.NoncurrentVersionExpiration; e != nil {
+			add(LifecycleExpire, "", afterDays(object.NoncurrentSince, e.NoncurrentDays))
+		}
+		for _, transition := range r.NoncurrentVersionTransition {
+			if colder(transition.StorageClass, object.StorageClass) {
+				add(LifecycleTransition, transition.StorageClass, afterDays(r.accessBase(object, transition.IsAccessTime, object.NoncurrentSince), transition.NoncurrentDays))
+			}
+		}
+		return actions
+	}
+	if object.IsDeleteMarker {
+		if r.Expiration.ExpiredObjectDeleteMarker && !versioned {
+			add(LifecycleExpire, "", object.LastModified)
+		}
+		return actions
+	}
+	if t, ok := r.Expiration.due(object); ok {
+		add(LifecycleExpire, "", t)
+	}
+	for _, transition := range r.Transition {
+		if !colder(transition.StorageClass, object.StorageClass) {
+			continue
+		}
+		if transition.CreatedBeforeDate != nil {
+			if object.LastModified.Before(*transition.CreatedBeforeDate) {
+				add(LifecycleTransition, transition.StorageClass, *transition.CreatedBeforeDate)
+			}
+		} else if transition.Days > 0 {
+			add(LifecycleTransition, transition.StorageClass, afterDays(r.accessBase(object, transition.IsAccessTime, object.LastModified), transition.Days))
+		}
+	}
+	return actions
+}
+
+// accessBase returns the time the days of a transition count from
+func (r *LifecycleRule) accessBase(object *LifecycleObject, isAccessTime bool, base time.Time) time.Time {
+	if !isAccessTime {
+		return base
+	}
+	if !object.LastAccess.IsZero() && object.LastAccess.After(base) {
+		base = object.LastAccess
+	}
+	if atime := time.Unix(r.AtimeBase, 0); r.AtimeBase > 0 && atime.After(base) {
+		base = atime
+	}
+	return base
+}
+
+func (e *Expiration) due(object *LifecycleObject) (time.Time, bool) {
+	switch {
+	case e.Days > 0:
+		return afterDays(object.LastModified, e.Days), true
+	case e.CreatedBeforeDate != nil:
+		return *e.CreatedBeforeDate, object.LastModified.Before(*e.CreatedBeforeDate)
+	case e.Date != nil:
+		return *e.Date, object.LastModified.Before(*e.Date)
+	}
+	return time.Time{}, false
+}
+
+// afterDays returns the midnight UTC following days after t
+func afterDays(t time.Time, days int) time.Time {
+	t = t.UTC().AddDate(0, 0, days)
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	if day.Equal(t) {
+		return day
+	}
+	return day.AddDate(0, 0, 1)
+}
+
+func colder(class, than StorageClassType) bool {
+	return storageClassRank[class] > storageClassRank[than]
+}
+
+// supersede marks the actions of an object after its earliest expiration
+func supersede(actions []LifecycleAction) {
+	var expire *LifecycleAction
+	for i := range actions {
+		if actions[i].Action == LifecycleExpire && (expire == nil || actions[i].Time.Before(expire.Time)) {
+			expire = &actions[i]
+		}
+	}
+	if expire == nil {
+		return
+	}
+	for i := range actions {
+		if &actions[i] != expire && !actions[i].Time.Before(expire.Time) {
+			actions[i].Superseded = true
+		}
+	}
+}
+
+// lifecycleConflicts returns the duplicate IDs, the enabled rules which can
+// match the same objects, and the rules expiring objects before
+// transitioning them or transitioning them to a warmer class later
+func lifecycleConflicts(config *LifecycleConfiguration) []LifecycleConflict {
+	var conflicts []LifecycleConflict
+	ids := make(map[string]bool)
+	for i := range config.Rule {
+		rule := &config.Rule[i]
+		if rule.ID != "" && ids[rule.ID] {
+			conflicts = append(conflicts, LifecycleConflict{[]string{rule.ID}, "duplicate rule ID"})
+		}
+		ids[rule.ID] = true
+		conflicts = append(conflicts, rule.conflicts()...)
+		if rule.Status != LifecycleEnabled {
+			continue
+		}
+		for j := range config.Rule[:i] {
+			other := &config.Rule[j]
+			if other.Status == LifecycleEnabled && rule.overlaps(other) {
+				conflicts = append(conflicts, LifecycleConflict{[]string{other.ID, rule.ID},
+					fmt.Sprintf("prefixes %q and %q overlap", other.Prefix, rule.Prefix)})
+			}
+		}
+	}
+	return conflicts
+}
+
+// conflicts returns the problems of the days of a rule
+func (r *LifecycleRule) conflicts() []LifecycleConflict {
+	var conflicts []LifecycleConflict
+	for i, transition := range r.Transition {
+		if transition.Days > 0 && r.Expiration.Days > 0 && transition.Days >= r.Expiration.Days {
+			conflicts = append(conflicts, LifecycleConflict{[]string{r.ID},
+				fmt.Sprintf("transition to %s after %d days is not before the expiration after %d days", transition.StorageClass, transition.Days, r.Expiration.Days)})
+		}
+		for _, other := range r.Transition[:i] {
+			if transition.Days > 0 && other.Days > 0 && transition.IsAccessTime == other.IsAccessTime &&
+				(colder(transition.StorageClass, other.StorageClass) != (transition.Days > other.Days) || transition.Days == other.Days) {
+				conflicts = append(conflicts, LifecycleConflict{[]string{r.ID},
+					fmt.Sprintf("transitions to %s after %d days and to %s after %d days are out of order", other.StorageClass, other.Days, transition.StorageClass, transition.Days)})
+			}
+		}
+	}
+	for _, transition := range r.NoncurrentVersionTransition {
+		if e := r.NoncurrentVersionExpiration; e != nil && transition.NoncurrentDays >= e.NoncurrentDays {
+			conflicts = append(conflicts, LifecycleConflict{[]string{r.ID},
+				fmt.Sprintf("noncurrent transition to %s after %d days is not before the expiration after %d days", transition.StorageClass, transition.NoncurrentDays, e.NoncurrentDays)})
+		}
+	}
+	return conflicts
+}
+
+// overlaps returns whether two rules can match the same object, i.e. a
+// prefix is a prefix of the other and no tag has different values
+func (r *LifecycleRule) overlaps(other *LifecycleRule) bool {
+	if !strings.HasPrefix(r.Prefix, other.Prefix) && !strings.HasPrefix(other.Prefix, r.Prefix) {
+		return false
+	}
+	for _, tag := range r.Tag {
+		for _, otherTag := range other.Tag {
+			if tag.Key == otherTag.Key && tag.Value != otherTag.Value {
+				return false
+			}
+		}
+	}
+	return true
+}
@@ -0,0 +1,301 @@
+package oss
+
+import (
+	"encoding/xml"
+	"reflect"
+	"regexp"
+	"testing"
+	"time"
+)
+
+const lifecycleXML = `<LifecycleConfiguration>
+  <Rule>
+    <ID>rule1</ID>
+    <Prefix>logs/</Prefix>
+    <Status>Enabled</Status>
+    <Expiration>
+      <Days>365</Days>
+    </Expiration>
+    <Transition>
+      <Days>30</Days>
+      <StorageClass>IA</StorageClass>
+    </Transition>
+    <Transition>
+      <Days>180</Days>
+      <StorageClass>Archive</StorageClass>
+    </Transition>
+    <AbortMultipartUpload>
+      <Days>7</Days>
+    </AbortMultipartUpload>
+    <Tag>
+      <Key>type</Key>
+      <Value>log</Value>
+    </Tag>
+    <Filter>
+      <Not>
+        <Prefix>logs/keep/</Prefix>
+        <Tag>
+          <Key>keep</Key>
+          <Value>true</Value>
+        </Tag>
+      </Not>
+    </Filter>
+  </Rule>
+  <Rule>
+    <ID>rule2</ID>
+    <Prefix>backup/</Prefix>
+    <Status>Disabled</Status>
+    <Expiration>
+      <CreatedBeforeDate>2022-10-12T00:00:00Z</CreatedBeforeDate>
+    </Expiration>
+    <Transition>
+      <CreatedBeforeDate>2022-01-01T00:00:00Z</CreatedBeforeDate>
+      <StorageClass>ColdArchive</StorageClass>
+    </Transition>
+    <AbortMultipartUpload>
+      <CreatedBeforeDate>2022-01-01T00:00:00Z</CreatedBeforeDate>
+    </AbortMultipartUpload>
+  </Rule>
+  <Rule>
+    <ID>rule3</ID>
+    <Prefix></Prefix>
+    <Status>Enabled</Status>
+    <Expiration>
+      <ExpiredObjectDeleteMarker>true</ExpiredObjectDeleteMarker>
+    </Expiration>
+    <NoncurrentVersionExpiration>
+      <NoncurrentDays>60</NoncurrentDays>
+    </NoncurrentVersionExpiration>
+    <NoncurrentVersionTransition>
+      <NoncurrentDays>10</NoncurrentDays>
+      <StorageClass>IA</StorageClass>
+    </NoncurrentVersionTransition>
+  </Rule>
+  <Rule>
+    <ID>rule4</ID>
+    <Prefix>img/</Prefix>
+    <Status>Enabled</Status>
+    <Transition>
+      <Days>30</Days>
+      <StorageClass>IA</StorageClass>
+      <IsAccessTime>true</IsAccessTime>
+      <ReturnToStdWhenVisit>true</ReturnToStdWhenVisit>
+      <AllowSmallFile>true</AllowSmallFile>
+    </Transition>
+    <AtimeBase>1631698332</AtimeBase>
+  </Rule>
+</LifecycleConfiguration>`
+
+func TestLifecycleConfigurationXML(t *testing.T) {
+	var config LifecycleConfiguration
+	if err := xml.Unmarshal([]byte(lifecycleXML), &config); err != nil {
+		t.Fatal(err)
+	}
+	expected := LifecycleConfiguration{
+		Rule: []LifecycleRule{
+			{
+				ID:         "rule1",
+				Prefix:     "logs/",
+				Status:     LifecycleEnabled,
+				Expiration: Expiration{Days: 365},
+				Transition: []Transition{
+					{Days: 30, StorageClass: IAStorage},
+					{Days: 180, StorageClass: ArchiveStorage},
+				},
+				AbortMultipartUpload: &AbortMultipartUpload{Days: 7},
+				Tag:                  []Tag{{Key: "type", Value: "log"}},
+				Filter:               &LifecycleFilter{Not: []LifecycleNot{{Prefix: "logs/keep/", Tag: &Tag{Key: "keep", Value: "true"}}}},
+			},
+			{
+				ID:                   "rule2",
+				Prefix:               "backup/",
+				Status:               LifecycleDisabled,
+				Expiration:           Expiration{CreatedBeforeDate: parseTimePtr(time.RFC3339, "2022-10-12T00:00:00Z")},
+				Transition:           []Transition{{CreatedBeforeDate: parseTimePtr(time.RFC3339, "2022-01-01T00:00:00Z"), StorageClass: ColdArchiveStorage}},
+				AbortMultipartUpload: &AbortMultipartUpload{CreatedBeforeDate: parseTimePtr(time.RFC3339, "2022-01-01T00:00:00Z")},
+			},
+			{
+				ID:                          "rule3",
+				Status:                      LifecycleEnabled,
+				Expiration:                  Expiration{ExpiredObjectDeleteMarker: true},
+				NoncurrentVersionExpiration: &NoncurrentVersionExpiration{NoncurrentDays: 60},
+				NoncurrentVersionTransition: []NoncurrentVersionTransition{{NoncurrentDays: 10, StorageClass: IAStorage}},
+			},
+			{
+				ID:         "rule4",
+				Prefix:     "img/",
+				Status:     LifecycleEnabled,
+				Transition: []Transition{{Days: 30, StorageClass: IAStorage, IsAccessTime: true, ReturnToStdWhenVisit: true, AllowSmallFile: true}},
+				AtimeBase:  1631698332,
+			},
+		},
+	}
+	if !reflect.DeepEqual(config, expected) {
+		t.Fatalf(expectBut, expected, config)
+	}
+	data, err := xml.Marshal(&config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if compact := regexp.MustCompile(`>\s+<`).ReplaceAllString(lifecycleXML, "><"); string(data) != compact {
+		t.Fatalf(expectBut, compact, string(data))
+	}
+}
+
+var lifecycleTestcases = []testcase{
+	{
+		name: "PutBucketAccessMonitor",
+		request: func(a *API) (interface{}, error) {
+			return nil, a.PutBucketAccessMonitor(testBucketName, LifecycleEnabled)
+		},
+		expectedRequest: `PUT /?accessmonitor HTTP/1.1
+Host: bucket-name.oss-cn-hangzhou.aliyuncs.com
+User-Agent: %s
+Content-Length: 81
+Accept-Encoding: identity
+Authorization: OSS ayahghai0juiSie:73Fnl3+kPI7cT6webUUajkFYekI=
+Date: %s
+
+<AccessMonitorConfiguration><Status>Enabled</Status></AccessMonitorConfiguration>`,
+		response: `HTTP/1.1 200 OK
+x-oss-request-id: 534B371674E88A4D8906008B
+Date: Mon, 14 Apr 2014 01:17:10 GMT
+Content-Length: 0
+Connection: close
+Server: AliyunOSS
+`,
+		expectedResponse: nil,
+	},
+
+	{
+		name: "GetBucketAccessMonitor",
+		request: func(a *API) (interface{}, error) {
+			r, err := a.GetBucketAccessMonitor(testBucketName)
+			return r, err
+		},
+		expectedRequest: `GET /?accessmonitor HTTP/1.1
+Host: bucket-name.oss-cn-hangzhou.aliyuncs.com
+User-Agent: %s
+Accept-Encoding: identity
+Authorization: OSS ayahghai0juiSie:fuaQWVvZjN3jq9SxEGZGGdrvF5A=
+Date: %s`,
+		response: `HTTP/1.1 200 OK
+x-oss-request-id: 534B371674E88A4D8906008B
+Date: Mon, 14 Apr 2014 01:17:10 GMT
+Content-Type: application/xml
+Content-Length: 124
+Connection: close
+Server: AliyunOSS
+
+<?xml version="1.0" encoding="UTF-8"?>
+<AccessMonitorConfiguration>
+  <Status>Enabled</Status>
+</AccessMonitorConfiguration>`,
+		expectedResponse: &AccessMonitorConfiguration{Status: LifecycleEnabled},
+	},
+}
+
+func TestLifecycleAPI(t *testing.T) {
+	for i := range lifecycleTestcases {
+		testAPI(t, &lifecycleTestcases[i])
+	}
+}
+
+func TestSimulateLifecycle(t *testing.T) {
+	config := &LifecycleConfiguration{
+		Rule: []LifecycleRule{
+			{
+				ID:         "logs",
+				Prefix:     "logs/",
+				Status:     LifecycleEnabled,
+				Expiration: Expiration{Days: 30},
+				Transition: []Transition{{Days: 10, StorageClass: IAStorage}, {Days: 40, StorageClass: ArchiveStorage}},
+				Filter:     &LifecycleFilter{Not: []LifecycleNot{{Prefix: "logs/keep/"}}},
+			},
+			{
+				ID:         "tmp",
+				Prefix:     "logs/tmp",
+				Status:     LifecycleEnabled,
+				Expiration: Expiration{Days: 1},
+			},
+			{
+				ID:         "tagged",
+				Status:     LifecycleEnabled,
+				Tag:        []Tag{{Key: "type", Value: "cache"}},
+				Expiration: Expiration{CreatedBeforeDate: parseTimePtr(time.RFC3339, "2022-01-01T00:00:00Z")},
+			},
+			{
+				ID:                          "versions",
+				Prefix:                      "doc/",
+				Status:                      LifecycleEnabled,
+				Expiration:                  Expiration{ExpiredObjectDeleteMarker: true},
+				NoncurrentVersionExpiration: &NoncurrentVersionExpiration{NoncurrentDays: 5},
+			},
+			{
+				ID:         "disabled",
+				Status:     LifecycleDisabled,
+				Expiration: Expiration{Days: 1},
+			},
+		},
+	}
+	modified := parseTime(time.RFC3339, "2021-12-01T10:00:00Z")
+	objects := []LifecycleObject{
+		{Key: "logs/a", LastModified: modified},
+		{Key: "logs/keep/b", LastModified: modified},
+		{Key: "logs/tmp1", LastModified: modified, StorageClass: IAStorage},
+		{Key: "cache", LastModified: modified, Tags: map[string]string{"type": "cache"}},
+		{Key: "doc/a", LastModified: modified, NoncurrentSince: parseTime(time.RFC3339, "2021-12-20T10:00:00Z")},
+		{Key: "doc/a", LastModified: parseTime(time.RFC3339, "2021-12-20T10:00:00Z"), IsDeleteMarker: true},
+		{Key: "doc/b", LastModified: parseTime(time.RFC3339, "2021-12-20T10:00:00Z"), IsDeleteMarker: true},
+	}
+	report := SimulateLifecycle(config, objects, parseTime(time.RFC3339, "2021-12-25T00:00:00Z"))
+	expected := []LifecycleAction{
+		{Key: "logs/tmp1", RuleID: "tmp", Action: LifecycleExpire, Time: parseTime(time.RFC3339, "2021-12-03T00:00:00Z"), Due: true},
+		{Key: "logs/a", RuleID: "logs", Action: LifecycleTransition, StorageClass: IAStorage, Time: parseTime(time.RFC3339, "2021-12-12T00:00:00Z"), Due: true},
+		{Key: "doc/b", RuleID: "versions", Action: LifecycleExpire, Time: parseTime(time.RFC3339, "2021-12-20T10:00:00Z"), Due: true},
+		{Key: "doc/a", RuleID: "versions", Action: LifecycleExpire, Time: parseTime(time.RFC3339, "2021-12-26T00:00:00Z"), Noncurrent: true},
+		{Key: "cache", RuleID: "tagged", Action: LifecycleExpire, Time: parseTime(time.RFC3339, "2022-01-01T00:00:00Z")},
+		{Key: "logs/a", RuleID: "logs", Action: LifecycleExpire, Time: parseTime(time.RFC3339, "2022-01-01T00:00:00Z")},
+		{Key: "logs/tmp1", RuleID: "logs", Action: LifecycleExpire, Time: parseTime(time.RFC3339, "2022-01-01T00:00:00Z"), Superseded: true},
+		{Key: "logs/a", RuleID: "logs", Action: LifecycleTransition, StorageClass: ArchiveStorage, Time: parseTime(time.RFC3339, "2022-01-11T00:00:00Z"), Superseded: true},
+		{Key: "logs/tmp1", RuleID: "logs", Action: LifecycleTransition, StorageClass: ArchiveStorage, Time: parseTime(time.RFC3339, "2022-01-11T00:00:00Z"), Superseded: true},
+	}
+	if !reflect.DeepEqual(report.Actions, expected) {
+		t.Fatalf(expectBut, expected, report.Actions)
+	}
+	conflicts := []LifecycleConflict{
+		{[]string{"logs"}, "transition to Archive after 40 days is not before the expiration after 30 days"},
+		{[]string{"logs", "tmp"}, `prefixes "logs/" and "logs/tmp" overlap`},
+		{[]string{"logs", "tagged"}, `prefixes "logs/" and "" overlap`},
+		{[]string{"tmp", "tagged"}, `prefixes "logs/tmp" and "" overlap`},
+		{[]string{"tagged", "versions"}, `prefixes "" and "doc/" overlap`},
+	}
+	if !reflect.DeepEqual(report.Conflicts, conflicts) {
+		t.Fatalf(expectBut, conflicts, report.Conflicts)
+	}
+}
+
+func TestSimulateLifecycleDate(t *testing.T) {
+	// the legacy Date expires the objects modified before it, like
+	// CreatedBeforeDate
+	config := &LifecycleConfiguration{
+		Rule: []LifecycleRule{
+			{
+				ID:         "legacy",
+				Status:     LifecycleEnabled,
+				Expiration: Expiration{Date: parseTimePtr(time.RFC3339, "2022-01-01T00:00:00Z")},
+			},
+		},
+	}
+	objects := []LifecycleObject{
+		{Key: "old", LastModified: parseTime(time.RFC3339, "2021-12-01T10:00:00Z")},
+		{Key: "new", LastModified: parseTime(time.RFC3339, "2022-02-01T10:00:00Z")},
+	}
+	report := SimulateLifecycle(config, objects, parseTime(time.RFC3339, "2022-03-01T00:00:00Z"))
+	expected := []LifecycleAction{
+		{Key: "old", RuleID: "legacy", Action: LifecycleExpire, Time: parseTime(time.RFC3339, "2022-01-01T00:00:00Z"), Due: true},
+	}
+	if !reflect.DeepEqual(report.Actions, expected) {
+		t.Fatalf(expectBut, expected, report.Actions)
+	}
+}
@@ -0,0 +1,117 @@
+package oss
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/url"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// Statuses of a LiveChannel
+const (
+	LiveChannelEnabled  = "enabled"
+	LiveChannelDisabled = "disabled"
+	// LiveChannelLive and LiveChannelIdle are returned by GetLiveChannelStat
+	LiveChannelLive = "Live"
+	LiveChannelIdle = "Idle"
+)
+
+// LiveChannelHLS is the only type of LiveChannelTarget
+const LiveChannelHLS = "HLS"
+
+// PutLiveChannel creates a channel receiving an RTMP live stream and saving
+// it as HLS, or modifies its configuration
+func (a *API) PutLiveChannel(bucket, channel string, config *LiveChannelConfiguration) (res *CreateLiveChannelResult, _ error) {
+	return res, a.Do("PUT", bucket, channel+"?live", &res, XMLBody(config))
+}
+
+// PutLiveChannelStatus enables or disables a channel, disabling it stops
+// the ongoing stream
+func (a *API) PutLiveChannelStatus(bucket, channel, status string) error {
+	return a.Do("PUT", bucket, channel+"?live&status="+url.QueryEscape(status), nil)
+}
+
+// GetLiveChannelInfo returns the configuration of a channel
+func (a *API) GetLiveChannelInfo(bucket, channel string) (res *LiveChannelConfiguration, _ error) {
+	return res, a.Do("GET", bucket, channel+"?live", &res)
+}
+
+// GetLiveChannelStat returns the status of the stream of a channel
+func (a *API) GetLiveChannelStat(bucket, channel string) (res *LiveChannelStat, _ error) {
+	return res, a.Do("GET", bucket, channel+"?comp=stat&live", &res)
+}
+
+// GetLiveChannelHistory returns the last streams of a channel, up to 10
+func (a *API) GetLiveChannelHistory(bucket, channel string) (res *LiveChannelHistory, _ error) {
+	return res, a.Do("GET", bucket, channel+"?comp=history&live", &res)
+}
+
+// ListLiveChannel lists the channels of a bucket, filtered by the Prefix,
+// Marker and MaxKeys options
+func (a *API) ListLiveChannel(bucket string, options ...Option) (res *ListLiveChannelResult, _ error) {
+	return res, a.Do("GET", bucket, "?live", &res, options...)
+}
+
+// DeleteLiveChannel deletes a channel, the saved ts and m3u8 files are kept
+func (a *API) DeleteLiveChannel(bucket, channel string) error {
+	return a.Do("DELETE", bucket, channel+"?live", nil)
+}
+
+// PostVodPlaylist creates a VOD playlist of the ts files of a channel
+// between start and end, the duration must be less than a day
+func (a *API) PostVodPlaylist(bucket, channel, playlist string, start, end time.Time) error {
+	return a.Do("POST", bucket, fmt.Sprintf("%s/%s?endTime=%d&startTime=%d&vod", channel, playlist, end.Unix(), start.Unix()), nil)
+}
+
+// GetVodPlaylist writes a VOD m3u8 playlist of the ts files of a channel
+// between start and end to an io.Writer without saving it
+func (a *API) GetVodPlaylist(bucket, channel string, start, end time.Time, w io.Writer) error {
+	return a.Do("GET", bucket, fmt.Sprintf("%s?endTime=%d&startTime=%d&vod", channel, end.Unix(), start.Unix()), &bodyAndHeader{Writer: w, Header: new(Header)})
+}
+
+// SignRTMPURL returns the RTMP URL publishing a stream to a channel until
+// expires, playlist overrides the PlaylistName of the channel if not empty
+func (a *API) SignRTMPURL(bucket, channel, playlist string, expires time.Time) (string, error) {
+	uri, err := ossURL("rtmp", a.endPoint, bucket, "live/"+channel)
+	if err != nil {
+		return "", err
+	}
+	params := make(url.Values)
+	if playlist != "" {
+		params.Set("playlistName", playlist)
+	}
+	if a.securityToken != "" {
+		params.Set("security-token", a.securityToken)
+	}
+	expiresUnix := strconv.FormatInt(expires.Unix(), 10)
+	signature := hmacSHA1(rtmpSigningData(expiresUnix, params, bucket, channel), []byte(a.accessKeySecret))
+	params.Set("OSSAccessKeyId", a.accessKeyID)
+	params.Set("Expires", expiresUnix)
+	params.Set("Signature", signature)
+	uri.RawQuery = params.Encode()
+	return uri.String(), nil
+}
+
+// rtmpSigningData returns the string to sign of an RTMP URL, the sorted
+// parameters as "key:value" lines after the expiration time
+func rtmpSigningData(expires string, params url.Values, bucket, channel string) []byte {
+	var w bytes.Buffer
+	w.WriteString(expires)
+	w.WriteByte('\n')
+	keys := make([]string, 0, len(params))
+	for key := range params {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		w.WriteString(key)
+		w.WriteByte(':')
+		w.WriteString(params.Get(key))
+		w.WriteByte('\n')
+	}
+	w.WriteString("/" + bucket + "/" + channel)
+	return w.Bytes()
+}
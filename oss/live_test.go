@@ -0,0 +1,348 @@
+package oss
+
+import (
+	"bytes"
+	"encoding/xml"
+	"net/url"
+	"testing"
+	"time"
+)
+
+var liveTestcases = []testcase{
+	{
+		name: "PutLiveChannel",
+		request: func(a *API) (interface{}, error) {
+			r, err := a.PutLiveChannel(testBucketName, "test-channel", &LiveChannelConfiguration{
+				Description: "test",
+				Status:      LiveChannelEnabled,
+				Target:      LiveChannelTarget{Type: LiveChannelHLS, FragDuration: 2, FragCount: 3, PlaylistName: "playlist.m3u8"},
+			})
+			return r, err
+		},
+		expectedRequest: `PUT /test-channel?live HTTP/1.1
+Host: bucket-name.oss-cn-hangzhou.aliyuncs.com
+User-Agent: %s
+Content-Length: 237
+Accept-Encoding: identity
+Authorization: OSS ayahghai0juiSie:kGh4CCtOYDCXn6k/skCDFs9mbFU=
+Date: %s
+
+<LiveChannelConfiguration><Description>test</Description><Status>enabled</Status><Target><Type>HLS</Type><FragDuration>2</FragDuration><FragCount>3</FragCount><PlaylistName>playlist.m3u8</PlaylistName></Target></LiveChannelConfiguration>`,
+		response: `HTTP/1.1 200 OK
+x-oss-request-id: 5C1B138A109F4E405B2D8AEF
+Date: Wed, 24 Sep 2015 11:11:05 GMT
+Content-Type: application/xml
+Content-Length: 322
+Connection: close
+Server: AliyunOSS
+
+<?xml version="1.0" encoding="UTF-8"?>
+<CreateLiveChannelResult>
+  <PublishUrls>
+    <Url>rtmp://bucket-name.oss-cn-hangzhou.aliyuncs.com/live/test-channel</Url>
+  </PublishUrls>
+  <PlayUrls>
+    <Url>http://bucket-name.oss-cn-hangzhou.aliyuncs.com/test-channel/playlist.m3u8</Url>
+  </PlayUrls>
+</CreateLiveChannelResult>`,
+		expectedResponse: &CreateLiveChannelResult{
+			PublishURLs: []string{"rtmp://bucket-name.oss-cn-hangzhou.aliyuncs.com/live/test-channel"},
+			PlayURLs:    []string{"http://bucket-name.oss-cn-hangzhou.aliyuncs.com/test-channel/playlist.m3u8"},
+		},
+	},
+
+	{
+		name: "PutLiveChannelStatus",
+		request: func(a *API) (interface{}, error) {
+			return nil, a.PutLiveChannelStatus(testBucketName, "test-channel", LiveChannelDisabled)
+		},
+		expectedRequest: `PUT /test-channel?live&status=disabled HTTP/1.1
+Host: bucket-name.oss-cn-hangzhou.aliyuncs.com
+User-Agent: %s
+Content-Length: 0
+Accept-Encoding: identity
+Authorization: OSS ayahghai0juiSie:r0QJt47yA/PvHkUiLsjqqKLQGzk=
+Date: %s`,
+		response: `HTTP/1.1 200 OK
+x-oss-request-id: 5C1B138A109F4E405B2D8AEF
+Date: Wed, 24 Sep 2015 11:11:05 GMT
+Content-Length: 0
+Connection: close
+Server: AliyunOSS
+`,
+		expectedResponse: nil,
+	},
+
+	{
+		name: "GetLiveChannelInfo",
+		request: func(a *API) (interface{}, error) {
+			r, err := a.GetLiveChannelInfo(testBucketName, "test-channel")
+			return r, err
+		},
+		expectedRequest: `GET /test-channel?live HTTP/1.1
+Host: bucket-name.oss-cn-hangzhou.aliyuncs.com
+User-Agent: %s
+Accept-Encoding: identity
+Authorization: OSS ayahghai0juiSie:d3Ci3p/TC4e6YXCXHKyy6jEEx/Q=
+Date: %s`,
+		response: `HTTP/1.1 200 OK
+x-oss-request-id: 5C1B138A109F4E405B2D8AEF
+Date: Wed, 24 Sep 2015 11:11:05 GMT
+Content-Type: application/xml
+Content-Length: 305
+Connection: close
+Server: AliyunOSS
+
+<?xml version="1.0" encoding="UTF-8"?>
+<LiveChannelConfiguration>
+  <Description></Description>
+  <Status>enabled</Status>
+  <Target>
+    <Type>HLS</Type>
+    <FragDuration>2</FragDuration>
+    <FragCount>3</FragCount>
+    <PlaylistName>playlist.m3u8</PlaylistName>
+  </Target>
+</LiveChannelConfiguration>`,
+		expectedResponse: &LiveChannelConfiguration{
+			XMLName: xml.Name{Local: "LiveChannelConfiguration"},
+			Status:  LiveChannelEnabled,
+			Target:  LiveChannelTarget{Type: LiveChannelHLS, FragDuration: 2, FragCount: 3, PlaylistName: "playlist.m3u8"},
+		},
+	},
+
+	{
+		name: "GetLiveChannelStat",
+		request: func(a *API) (interface{}, error) {
+			r, err := a.GetLiveChannelStat(testBucketName, "test-channel")
+			return r, err
+		},
+		expectedRequest: `GET /test-channel?comp=stat&live HTTP/1.1
+Host: bucket-name.oss-cn-hangzhou.aliyuncs.com
+User-Agent: %s
+Accept-Encoding: identity
+Authorization: OSS ayahghai0juiSie:XlmnEqEqejFo2ACrq8iNdZCaAz0=
+Date: %s`,
+		response: `HTTP/1.1 200 OK
+x-oss-request-id: 5C1B138A109F4E405B2D8AEF
+Date: Wed, 24 Sep 2015 11:11:05 GMT
+Content-Type: application/xml
+Content-Length: 462
+Connection: close
+Server: AliyunOSS
+
+<?xml version="1.0" encoding="UTF-8"?>
+<LiveChannelStat>
+  <Status>Live</Status>
+  <ConnectedTime>2016-08-25T06:25:15.000Z</ConnectedTime>
+  <RemoteAddr>10.1.2.3:47745</RemoteAddr>
+  <Video>
+    <Width>1280</Width>
+    <Height>536</Height>
+    <FrameRate>24</FrameRate>
+    <Bandwidth>0</Bandwidth>
+    <Codec>H264</Codec>
+  </Video>
+  <Audio>
+    <Bandwidth>0</Bandwidth>
+    <SampleRate>44100</SampleRate>
+    <Codec>ADPCM</Codec>
+  </Audio>
+</LiveChannelStat>`,
+		expectedResponse: &LiveChannelStat{
+			Status:        LiveChannelLive,
+			ConnectedTime: parseTimePtr(time.RFC3339, "2016-08-25T06:25:15.000Z"),
+			RemoteAddr:    "10.1.2.3:47745",
+			Video:         &LiveChannelVideo{Width: 1280, Height: 536, FrameRate: 24, Codec: "H264"},
+			Audio:         &LiveChannelAudio{SampleRate: 44100, Codec: "ADPCM"},
+		},
+	},
+
+	{
+		name: "GetLiveChannelHistory",
+		request: func(a *API) (interface{}, error) {
+			r, err := a.GetLiveChannelHistory(testBucketName, "test-channel")
+			return r, err
+		},
+		expectedRequest: `GET /test-channel?comp=history&live HTTP/1.1
+Host: bucket-name.oss-cn-hangzhou.aliyuncs.com
+User-Agent: %s
+Accept-Encoding: identity
+Authorization: OSS ayahghai0juiSie:5eHU5kPP1Hw2seh+2DNnKTT6OfE=
+Date: %s`,
+		response: `HTTP/1.1 200 OK
+x-oss-request-id: 5C1B138A109F4E405B2D8AEF
+Date: Wed, 24 Sep 2015 11:11:05 GMT
+Content-Type: application/xml
+Content-Length: 262
+Connection: close
+Server: AliyunOSS
+
+<?xml version="1.0" encoding="UTF-8"?>
+<LiveChannelHistory>
+  <LiveRecord>
+    <StartTime>2016-07-30T01:53:21.000Z</StartTime>
+    <EndTime>2016-07-30T01:53:31.000Z</EndTime>
+    <RemoteAddr>10.101.194.148:56861</RemoteAddr>
+  </LiveRecord>
+</LiveChannelHistory>`,
+		expectedResponse: &LiveChannelHistory{
+			LiveRecord: []LiveRecord{
+				{
+					StartTime:  parseTime(time.RFC3339, "2016-07-30T01:53:21.000Z"),
+					EndTime:    parseTime(time.RFC3339, "2016-07-30T01:53:31.000Z"),
+					RemoteAddr: "10.101.194.148:56861",
+				},
+			},
+		},
+	},
+
+	{
+		name: "ListLiveChannel",
+		request: func(a *API) (interface{}, error) {
+			r, err := a.ListLiveChannel(testBucketName, MaxKeys(1))
+			return r, err
+		},
+		expectedRequest: `GET /?live=&max-keys=1 HTTP/1.1
+Host: bucket-name.oss-cn-hangzhou.aliyuncs.com
+User-Agent: %s
+Accept-Encoding: identity
+Authorization: OSS ayahghai0juiSie:v3M94JiwpE7XcXOArrDfc0Qx/TM=
+Date: %s`,
+		response: `HTTP/1.1 200 OK
+x-oss-request-id: 5C1B138A109F4E405B2D8AEF
+Date: Wed, 24 Sep 2015 11:11:05 GMT
+Content-Type: application/xml
+Content-Length: 638
+Connection: close
+Server: AliyunOSS
+
+<?xml version="1.0" encoding="UTF-8"?>
+<ListLiveChannelResult>
+  <Prefix></Prefix>
+  <Marker></Marker>
+  <MaxKeys>1</MaxKeys>
+  <IsTruncated>true</IsTruncated>
+  <NextMarker>channel-0</NextMarker>
+  <LiveChannel>
+    <Name>channel-0</Name>
+    <Description></Description>
+    <Status>disabled</Status>
+    <LastModified>2016-07-30T01:54:21.000Z</LastModified>
+    <PublishUrls>
+      <Url>rtmp://bucket-name.oss-cn-hangzhou.aliyuncs.com/live/channel-0</Url>
+    </PublishUrls>
+    <PlayUrls>
+      <Url>http://bucket-name.oss-cn-hangzhou.aliyuncs.com/channel-0/playlist.m3u8</Url>
+    </PlayUrls>
+  </LiveChannel>
+</ListLiveChannelResult>`,
+		expectedResponse: &ListLiveChannelResult{
+			MaxKeys:     1,
+			IsTruncated: true,
+			NextMarker:  "channel-0",
+			LiveChannel: []LiveChannel{
+				{
+					Name:         "channel-0",
+					Status:       LiveChannelDisabled,
+					LastModified: parseTime(time.RFC3339, "2016-07-30T01:54:21.000Z"),
+					PublishURLs:  []string{"rtmp://bucket-name.oss-cn-hangzhou.aliyuncs.com/live/channel-0"},
+					PlayURLs:     []string{"http://bucket-name.oss-cn-hangzhou.aliyuncs.com/channel-0/playlist.m3u8"},
+				},
+			},
+		},
+	},
+
+	{
+		name: "DeleteLiveChannel",
+		request: func(a *API) (interface{}, error) {
+			return nil, a.DeleteLiveChannel(testBucketName, "test-channel")
+		},
+		expectedRequest: `DELETE /test-channel?live HTTP/1.1
+Host: bucket-name.oss-cn-hangzhou.aliyuncs.com
+User-Agent: %s
+Accept-Encoding: identity
+Authorization: OSS ayahghai0juiSie:TrtdcLtLD+SGXQHC0st4DpGRlH0=
+Date: %s`,
+		response: `HTTP/1.1 204 No Content
+x-oss-request-id: 5C1B138A109F4E405B2D8AEF
+Date: Wed, 24 Sep 2015 11:11:05 GMT
+Content-Length: 0
+Connection: close
+Server: AliyunOSS
+`,
+		expectedResponse: nil,
+	},
+
+	{
+		name: "PostVodPlaylist",
+		request: func(a *API) (interface{}, error) {
+			return nil, a.PostVodPlaylist(testBucketName, "test-channel", "vod.m3u8", time.Unix(1472020031, 0), time.Unix(1472020226, 0))
+		},
+		expectedRequest: `POST /test-channel/vod.m3u8?endTime=1472020226&startTime=1472020031&vod HTTP/1.1
+Host: bucket-name.oss-cn-hangzhou.aliyuncs.com
+User-Agent: %s
+Content-Length: 0
+Accept-Encoding: identity
+Authorization: OSS ayahghai0juiSie:ZX2FGpH/AI1RFt4H/GgPc5gMf4o=
+Date: %s`,
+		response: `HTTP/1.1 200 OK
+x-oss-request-id: 5C1B138A109F4E405B2D8AEF
+Date: Wed, 24 Sep 2015 11:11:05 GMT
+Content-Length: 0
+Connection: close
+Server: AliyunOSS
+`,
+		expectedResponse: nil,
+	},
+
+	{
+		name: "GetVodPlaylist",
+		request: func(a *API) (interface{}, error) {
+			var w bytes.Buffer
+			err := a.GetVodPlaylist(testBucketName, "test-channel", time.Unix(1472020031, 0), time.Unix(1472020226, 0), &w)
+			return w.String(), err
+		},
+		expectedRequest: `GET /test-channel?endTime=1472020226&startTime=1472020031&vod HTTP/1.1
+Host: bucket-name.oss-cn-hangzhou.aliyuncs.com
+User-Agent: %s
+Accept-Encoding: identity
+Authorization: OSS ayahghai0juiSie:PFHKHAV3Pu9UyVrbR20pj2274mU=
+Date: %s`,
+		response: `HTTP/1.1 200 OK
+x-oss-request-id: 5C1B138A109F4E405B2D8AEF
+Date: Wed, 24 Sep 2015 11:11:05 GMT
+Content-Type: application/x-mpegURL
+Content-Length: 96
+Connection: close
+Server: AliyunOSS
+
+#EXTM3U
+#EXT-X-VERSION:3
+#EXT-X-TARGETDURATION:13
+#EXTINF:7.120,
+1543895706266.ts
+#EXT-X-ENDLIST`,
+		expectedResponse: "#EXTM3U\n#EXT-X-VERSION:3\n#EXT-X-TARGETDURATION:13\n#EXTINF:7.120,\n1543895706266.ts\n#EXT-X-ENDLIST",
+	},
+}
+
+func TestLiveChannelAPI(t *testing.T) {
+	for i := range liveTestcases {
+		testAPI(t, &liveTestcases[i])
+	}
+}
+
+func TestSignRTMPURL(t *testing.T) {
+	api := New(testEndpoint, testID, testSecret)
+	signed, err := api.SignRTMPURL(testBucketName, "test-channel", "playlist.m3u8", time.Unix(1472020226, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	signature := hmacSHA1([]byte("1472020226\nplaylistName:playlist.m3u8\n/bucket-name/test-channel"), []byte(testSecret))
+	expected := "rtmp://bucket-name.oss-cn-hangzhou.aliyuncs.com/live/test-channel?Expires=1472020226&OSSAccessKeyId=" + testID +
+		"&Signature=" + url.QueryEscape(signature) + "&playlistName=playlist.m3u8"
+	if signed != expected {
+		t.Fatalf(expectBut, expected, signed)
+	}
+}
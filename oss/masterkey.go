@@ -0,0 +1,178 @@
+package oss
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/rsa"
+	"errors"
+	"io"
+	"sync"
+)
+
+// Wrap algorithms of the master keys provided by the SDK
+const (
+	RSAWrapAlgorithm = "RSA/NONE/PKCS1Padding"
+	KMSWrapAlgorithm = "KMS/ALICLOUD"
+)
+
+var (
+	// ErrNoPrivateKey happens when decrypting with an RSAMasterKey that only
+	// has a public key
+	ErrNoPrivateKey = errors.New("RSA private key is required for decryption")
+	// ErrUnknownKMSKey happens when a KMS key ID is not found
+	ErrUnknownKMSKey = errors.New("unknown KMS key")
+)
+
+type (
+	// MasterKey wraps and unwraps the per-object data keys of an
+	// EncryptionClient
+	MasterKey interface {
+		// Encrypt wraps a data key
+		Encrypt(plaintext []byte) ([]byte, error)
+		// Decrypt unwraps a data key
+		Decrypt(ciphertext []byte) ([]byte, error)
+		// WrapAlgorithm is saved with the object to identify the key type
+		WrapAlgorithm() string
+		// MaterialDescription is saved with the object to identify the key
+		MaterialDescription() map[string]string
+	}
+
+	// RSAMasterKey wraps data keys with an RSA key pair. Only the public key
+	// is needed for uploading.
+	RSAMasterKey struct {
+		PublicKey  *rsa.PublicKey
+		PrivateKey *rsa.PrivateKey
+		MatDesc    map[string]string
+	}
+
+	// KMSClient is the subset of a key management service used to wrap data
+	// keys, e.g. Alibaba Cloud KMS Encrypt and Decrypt APIs
+	KMSClient interface {
+		Encrypt(keyID string, plaintext []byte) ([]byte, error)
+		Decrypt(ciphertext []byte) ([]byte, error)
+	}
+
+	// KMSMasterKey wraps data keys with a key managed by a KMSClient
+	KMSMasterKey struct {
+		Client  KMSClient
+		KeyID   string
+		MatDesc map[string]string
+	}
+
+	// LocalKMS is an in-process KMSClient for testing and development. Each
+	// key is an AES-256 key and the ciphertext is sealed by AES-GCM with the
+	// key ID as additional data.
+	LocalKMS struct {
+		mu   sync.RWMutex
+		keys map[string][]byte
+	}
+)
+
+// NewRSAMasterKey creates an RSAMasterKey from a private key
+func NewRSAMasterKey(key *rsa.PrivateKey, matDesc map[string]string) *RSAMasterKey {
+	return &RSAMasterKey{PublicKey: &key.PublicKey, PrivateKey: key, MatDesc: matDesc}
+}
+
+// Encrypt implements MasterKey
+func (k *RSAMasterKey) Encrypt(plaintext []byte) ([]byte, error) {
+	return rsa.EncryptPKCS1v15(rand.Reader, k.PublicKey, plaintext)
+}
+
+// Decrypt implements MasterKey
+func (k *RSAMasterKey) Decrypt(ciphertext []byte) ([]byte, error) {
+	if k.PrivateKey == nil {
+		return nil, ErrNoPrivateKey
+	}
+	return rsa.DecryptPKCS1v15(rand.Reader, k.PrivateKey, ciphertext)
+}
+
+// WrapAlgorithm implements MasterKey
+func (k *RSAMasterKey) WrapAlgorithm() string { return RSAWrapAlgorithm }
+
+// MaterialDescription implements MasterKey
+func (k *RSAMasterKey) MaterialDescription() map[string]string { return k.MatDesc }
+
+// Encrypt implements MasterKey
+func (k *KMSMasterKey) Encrypt(plaintext []byte) ([]byte, error) {
+	return k.Client.Encrypt(k.KeyID, plaintext)
+}
+
+// Decrypt implements MasterKey
+func (k *KMSMasterKey) Decrypt(ciphertext []byte) ([]byte, error) {
+	return k.Client.Decrypt(ciphertext)
+}
+
+// WrapAlgorithm implements MasterKey
+func (k *KMSMasterKey) WrapAlgorithm() string { return KMSWrapAlgorithm }
+
+// MaterialDescription implements MasterKey
+func (k *KMSMasterKey) MaterialDescription() map[string]string { return k.MatDesc }
+
+// NewLocalKMS creates an empty LocalKMS
+func NewLocalKMS() *LocalKMS {
+	return &LocalKMS{keys: make(map[string][]byte)}
+}
+
+// CreateKey generates a random key with the specified ID
+func (k *LocalKMS) CreateKey(keyID string) error {
+	key := make([]byte, 32)
+	if _, err := io.ReadFull(rand.Reader, key); err != nil {
+		return err
+	}
+	k.mu.Lock()
+	k.keys[keyID] = key
+	k.mu.Unlock()
+	return nil
+}
+
+// Encrypt implements KMSClient. The ciphertext is the key ID length, the key
+// ID, the nonce and the sealed plaintext.
+func (k *LocalKMS) Encrypt(keyID string, plaintext []byte) ([]byte, error) {
+	aead, err := k.aead(keyID)
+	if err != nil {
+		return nil, err
+	}
+	if len(keyID) > 255 {
+		return nil, ErrUnknownKMSKey
+	}
+	out := append([]byte{byte(len(keyID))}, keyID...)
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+	out = append(out, nonce...)
+	return aead.Seal(out, nonce, plaintext, []byte(keyID)), nil
+}
+
+// Decrypt implements KMSClient
+func (k *LocalKMS) Decrypt(ciphertext []byte) ([]byte, error) {
+	if len(ciphertext) == 0 || len(ciphertext) < 1+int(ciphertext[0]) {
+		return nil, ErrUnknownKMSKey
+	}
+	keyID := string(ciphertext[1 : 1+ciphertext[0]])
+	ciphertext = ciphertext[1+len(keyID):]
+	aead, err := k.aead(keyID)
+	if err != nil {
+		return nil, err
+	}
+	if len(ciphertext) < aead.NonceSize() {
+		return nil, errors.New("ciphertext too short")
+	}
+	nonce, ciphertext := ciphertext[:aead.NonceSize()], ciphertext[aead.NonceSize():]
+	return aead.Open(nil, nonce, ciphertext, []byte(keyID))
+}
+
+func (k *LocalKMS) aead(keyID string) (cipher.AEAD, error) {
+	k.mu.RLock()
+	key, ok := k.keys[keyID]
+	k.mu.RUnlock()
+	if !ok {
+		return nil, ErrUnknownKMSKey
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,144 @@
+package oss
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ObjectMeta is the metadata of an object parsed from the HTTP headers
+// returned by HeadObject, GetObject or GetObjectMeta
+type ObjectMeta struct {
+	// ContentLength is the size of the object, or of the returned range
+	ContentLength      int64
+	ContentType        string
+	ContentEncoding    string
+	ContentDisposition string
+	CacheControl       string
+	Expires            string
+	// ETag is unquoted
+	ETag                 string
+	LastModified         time.Time
+	LastAccessTime       time.Time
+	ObjectType           ObjectType
+	StorageClass         StorageClassType
+	ServerSideEncryption string
+	// NextAppendPosition is only set for an appendable object
+	NextAppendPosition AppendPosition
+	CRC64              uint64
+	// Restore is nil if the object has never been restored
+	Restore *RestoreStatus
+	// UserMeta contains X-Oss-Meta-* headers, the keys are lower case
+	// without the prefix
+	UserMeta map[string]string
+	// Header contains all the headers
+	Header Header
+}
+
+// HeadObjectMeta returns the metadata of an object like HeadObject but parsed
+// as an ObjectMeta
+func (a *API) HeadObjectMeta(bucket, object string, options ...Option) (res *ObjectMeta, _ error) {
+	return res, a.Do("HEAD", bucket, object, &res, options...)
+}
+
+// GetObjectMeta returns the basic metadata of an object (ETag, size, last
+// modified time, CRC-64 and last access time), which is lighter than
+// HeadObject
+func (a *API) GetObjectMeta(bucket, object string, options ...Option) (res *ObjectMeta, _ error) {
+	return res, a.Do("HEAD", bucket, object+"?objectMeta", &res, options...)
+}
+
+// GetObjectWithMeta writes an object to an io.Writer like GetObject and
+// returns its metadata parsed as an ObjectMeta
+func (a *API) GetObjectWithMeta(bucket, object string, w io.Writer, options ...Option) (res *ObjectMeta, _ error) {
+	return res, a.Do("GET", bucket, object, &bodyAndMeta{Writer: w, meta: &res}, append([]Option{crc64ResponseBody}, options...)...)
+}
+
+// Parse implements ResponseParser
+func (r *ObjectMeta) Parse(resp *http.Response) error {
+	meta, err := ParseObjectMeta(resp.Header)
+	if err != nil {
+		return err
+	}
+	meta.ContentLength = resp.ContentLength
+	*r = *meta
+	return nil
+}
+
+// ParseObjectMeta parses the HTTP headers of an object
+func ParseObjectMeta(h http.Header) (*ObjectMeta, error) {
+	meta := &ObjectMeta{
+		ContentLength:        -1,
+		ContentType:          h.Get("Content-Type"),
+		ContentEncoding:      h.Get("Content-Encoding"),
+		ContentDisposition:   h.Get("Content-Disposition"),
+		CacheControl:         h.Get("Cache-Control"),
+		Expires:              h.Get("Expires"),
+		ETag:                 strings.Trim(h.Get("ETag"), `"`),
+		ObjectType:           ObjectType(h.Get("X-Oss-Object-Type")),
+		StorageClass:         StorageClassType(h.Get("X-Oss-Storage-Class")),
+		ServerSideEncryption: h.Get("X-Oss-Server-Side-Encryption"),
+		UserMeta:             make(map[string]string),
+		Header:               copyHeader(h),
+	}
+	var err error
+	if v := h.Get("Content-Length"); v != "" {
+		if meta.ContentLength, err = strconv.ParseInt(v, 10, 64); err != nil {
+			return nil, err
+		}
+	}
+	if v := h.Get("Last-Modified"); v != "" {
+		if meta.LastModified, err = time.Parse(gmtTime, v); err != nil {
+			return nil, err
+		}
+	}
+	if v := h.Get("X-Oss-Last-Access-Time"); v != "" {
+		if meta.LastAccessTime, err = time.Parse(gmtTime, v); err != nil {
+			return nil, err
+		}
+	}
+	if v := h.Get("X-Oss-Next-Append-Position"); v != "" {
+		pos, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		meta.NextAppendPosition = AppendPosition(pos)
+	}
+	if v := h.Get("X-Oss-Hash-Crc64ecma"); v != "" {
+		if meta.CRC64, err = strconv.ParseUint(v, 10, 64); err != nil {
+			return nil, err
+		}
+	}
+	if v := h.Get("X-Oss-Restore"); v != "" {
+		if meta.Restore, err = ParseRestoreStatus(v); err != nil {
+			return nil, err
+		}
+	}
+	for k, v := range h {
+		if len(v) > 0 && len(k) > len(metaPrefix) && strings.EqualFold(k[:len(metaPrefix)], metaPrefix) {
+			meta.UserMeta[strings.ToLower(k[len(metaPrefix):])] = v[0]
+		}
+	}
+	return meta, nil
+}
+
+const metaPrefix = "X-Oss-Meta-"
+
+// bodyAndMeta writes http.Response.Body to an io.Writer and also saves the
+// ObjectMeta
+type bodyAndMeta struct {
+	io.Writer
+	meta **ObjectMeta
+}
+
+// Parse implements ResponseParser
+func (r *bodyAndMeta) Parse(resp *http.Response) error {
+	*r.meta = new(ObjectMeta)
+	if err := (*r.meta).Parse(resp); err != nil {
+		return err
+	}
+	_, err := io.Copy(r.Writer, resp.Body)
+	return err
+}
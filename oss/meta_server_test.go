@@ -0,0 +1,32 @@
+package oss_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/aliyun/aliyun-oss-go-sdk/oss"
+)
+
+func TestGetObjectWithMeta(t *testing.T) {
+	server := newTestServer(t)
+	defer server.Close()
+	api := server.API()
+	if err := api.PutObject(testBucketName, testObjectName, strings.NewReader("0123456789"), oss.Meta("Build-Id", "42"), oss.ContentType("text/plain")); err != nil {
+		t.Fatal(err)
+	}
+	buf := new(bytes.Buffer)
+	meta, err := api.GetObjectWithMeta(testBucketName, testObjectName, buf, oss.Range("bytes=2-5"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected, actual := "2345", buf.String(); actual != expected {
+		t.Fatalf(expectBut, expected, actual)
+	}
+	if meta.ContentLength != 4 || meta.ContentType != "text/plain" || meta.UserMeta["build-id"] != "42" {
+		t.Fatalf(expectBut, "parsed meta", meta)
+	}
+	if expected := strings.Trim(etagOf([]byte("0123456789")), `"`); meta.ETag != expected {
+		t.Fatalf(expectBut, expected, meta.ETag)
+	}
+}
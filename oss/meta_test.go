@@ -0,0 +1,21 @@
+package oss
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestParseObjectMetaError(t *testing.T) {
+	for _, h := range []http.Header{
+		{"Content-Length": []string{"x"}},
+		{"Last-Modified": []string{"yesterday"}},
+		{"X-Oss-Last-Access-Time": []string{"yesterday"}},
+		{"X-Oss-Next-Append-Position": []string{"x"}},
+		{"X-Oss-Hash-Crc64ecma": []string{"-1"}},
+		{"X-Oss-Restore": []string{"x"}},
+	} {
+		if _, err := ParseObjectMeta(h); err == nil {
+			t.Fatalf(testcaseExpectBut, h, "error", nil)
+		}
+	}
+}
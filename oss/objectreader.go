@@ -0,0 +1,352 @@
+package oss
+
+import (
+	"container/list"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+)
+
+// Defaults of an ObjectReader
+const (
+	DefaultReaderBlockSize   = 1 << 20
+	DefaultReaderCacheBlocks = 8
+)
+
+// ErrObjectChanged happens when an object opened by OpenObject is overwritten
+// while it is being read
+var ErrObjectChanged = errors.New("object changed while reading")
+
+type (
+	// streamParser is implemented by results that keep reading the response
+	// body after API.Do returns, parseStream is called for a 2xx or 304
+	// response. done must be called once with nil when the body is read to
+	// EOF or with an error when it is abandoned.
+	streamParser interface {
+		parseStream(resp *http.Response, done func(err error) error) error
+	}
+
+	// ObjectStream is the body of an object returned by GetObjectReader, it
+	// must be closed by the caller
+	ObjectStream struct {
+		// Meta is the metadata of the object, ContentLength is the size of
+		// the returned range
+		Meta     *ObjectMeta
+		body     io.ReadCloser
+		done     func(err error) error
+		finished bool
+	}
+
+	// ObjectReader reads an object with Range requests on demand. It
+	// implements io.ReadSeeker, io.ReaderAt and io.Closer. ReadAt can be
+	// called concurrently, Read and Seek cannot.
+	ObjectReader struct {
+		api            *API
+		bucket, object string
+		options        []Option
+		meta           *ObjectMeta
+		etag           string
+		blockSize      int64
+		readAhead      int
+		cacheBlocks    int
+		offset         int64
+
+		mu     sync.Mutex
+		blocks map[int64]*list.Element
+		lru    *list.List
+		next   int64 // block after the last fetched range
+		closed bool
+	}
+
+	cachedBlock struct {
+		index int64
+		data  []byte
+	}
+
+	// ReaderOption configures an ObjectReader
+	ReaderOption func(*ObjectReader)
+)
+
+// ReaderBlockSize sets the size of the blocks fetched and cached by an
+// ObjectReader
+func ReaderBlockSize(size int64) ReaderOption {
+	return func(r *ObjectReader) {
+		r.blockSize = size
+	}
+}
+
+// ReaderReadAhead sets the number of blocks fetched after the requested one
+// when reading sequentially. They are fetched in the same request and kept
+// in the cache.
+func ReaderReadAhead(blocks int) ReaderOption {
+	return func(r *ObjectReader) {
+		r.readAhead = blocks
+	}
+}
+
+// ReaderCacheBlocks sets the maximum number of blocks cached by an
+// ObjectReader, the least recently used block is dropped first
+func ReaderCacheBlocks(blocks int) ReaderOption {
+	return func(r *ObjectReader) {
+		r.cacheBlocks = blocks
+	}
+}
+
+// ReaderRequestOptions adds options to every GET request of an ObjectReader,
+// e.g. TrafficLimit
+func ReaderRequestOptions(options ...Option) ReaderOption {
+	return func(r *ObjectReader) {
+		r.options = append(r.options, options...)
+	}
+}
+
+// GetObjectReader returns the body of an object as a stream instead of
+// writing it to an io.Writer. With CRC64Check(true), the CRC-64 of a whole
+// object is verified when the stream reaches EOF.
+func (a *API) GetObjectReader(bucket, object string, options ...Option) (*ObjectStream, error) {
+	stream := &ObjectStream{}
+	if err := a.Do("GET", bucket, object, stream, append([]Option{crc64ResponseBody}, options...)...); err != nil {
+		return nil, err
+	}
+	return stream, nil
+}
+
+func (s *ObjectStream) parseStream(resp *http.Response, done func(err error) error) error {
+	if resp.StatusCode == http.StatusNotModified {
+		defer resp.Body.Close()
+		return done(parseError(resp))
+	}
+	meta, err := ParseObjectMeta(resp.Header)
+	if err != nil {
+		resp.Body.Close()
+		return done(err)
+	}
+	meta.ContentLength = resp.ContentLength
+	s.Meta, s.body, s.done = meta, resp.Body, done
+	return nil
+}
+
+// Read implements io.Reader
+func (s *ObjectStream) Read(p []byte) (int, error) {
+	n, err := s.body.Read(p)
+	if err == io.EOF && !s.finished {
+		s.finished = true
+		if err := s.done(nil); err != nil {
+			return n, err
+		}
+	}
+	return n, err
+}
+
+// Close implements io.Closer
+func (s *ObjectStream) Close() error {
+	if !s.finished {
+		s.finished = true
+		s.done(io.ErrUnexpectedEOF)
+	}
+	return s.body.Close()
+}
+
+// OpenObject returns an ObjectReader for an object. The object is not
+// downloaded until it is read, then it is fetched block by block with Range
+// requests pinned to the ETag returned by HeadObject, so ErrObjectChanged is
+// returned if the object is overwritten.
+func (a *API) OpenObject(bucket, object string, options ...ReaderOption) (*ObjectReader, error) {
+	meta, err := a.HeadObjectMeta(bucket, object)
+	if err != nil {
+		return nil, err
+	}
+	return newObjectReader(a, bucket, object, meta, options), nil
+}
+
+func newObjectReader(a *API, bucket, object string, meta *ObjectMeta, options []ReaderOption) *ObjectReader {
+	r := &ObjectReader{
+		api:         a,
+		bucket:      bucket,
+		object:      object,
+		meta:        meta,
+		etag:        http.Header(meta.Header).Get("ETag"),
+		blockSize:   DefaultReaderBlockSize,
+		cacheBlocks: DefaultReaderCacheBlocks,
+		blocks:      make(map[int64]*list.Element),
+		lru:         list.New(),
+	}
+	for _, option := range options {
+		option(r)
+	}
+	if r.blockSize <= 0 {
+		r.blockSize = DefaultReaderBlockSize
+	}
+	if r.readAhead < 0 {
+		r.readAhead = 0
+	}
+	if r.cacheBlocks <= r.readAhead {
+		r.cacheBlocks = r.readAhead + 1
+	}
+	return r
+}
+
+// Meta returns the metadata of the object when it was opened
+func (r *ObjectReader) Meta() *ObjectMeta {
+	return r.meta
+}
+
+// Size returns the size of the object
+func (r *ObjectReader) Size() int64 {
+	return r.meta.ContentLength
+}
+
+// Read implements io.Reader
+func (r *ObjectReader) Read(p []byte) (int, error) {
+	n, err := r.ReadAt(p, r.offset)
+	r.offset += int64(n)
+	if err == io.EOF && n > 0 {
+		err = nil
+	}
+	return n, err
+}
+
+// Seek implements io.Seeker
+func (r *ObjectReader) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += r.offset
+	case io.SeekEnd:
+		offset += r.Size()
+	default:
+		return 0, errors.New("invalid whence")
+	}
+	if offset < 0 {
+		return 0, errors.New("negative position")
+	}
+	r.offset = offset
+	return offset, nil
+}
+
+// ReadAt implements io.ReaderAt
+func (r *ObjectReader) ReadAt(p []byte, off int64) (n int, err error) {
+	if off < 0 {
+		return 0, errors.New("negative offset")
+	}
+	for n < len(p) {
+		if off >= r.Size() {
+			return n, io.EOF
+		}
+		index := off / r.blockSize
+		data, err := r.block(index)
+		if err != nil {
+			return n, err
+		}
+		copied := copy(p[n:], data[off-index*r.blockSize:])
+		n += copied
+		off += int64(copied)
+	}
+	return n, nil
+}
+
+// Close drops the cached blocks
+func (r *ObjectReader) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.closed = true
+	r.blocks, r.lru = nil, nil
+	return nil
+}
+
+// block returns a block from the cache or fetches it with the read-ahead
+// blocks if it follows the last fetched range
+func (r *ObjectReader) block(index int64) ([]byte, error) {
+	r.mu.Lock()
+	if r.closed {
+		r.mu.Unlock()
+		return nil, os.ErrClosed
+	}
+	if elem, ok := r.blocks[index]; ok {
+		r.lru.MoveToFront(elem)
+		r.mu.Unlock()
+		return elem.Value.(*cachedBlock).data, nil
+	}
+	count := int64(1)
+	if index == r.next {
+		count += int64(r.readAhead)
+	}
+	r.mu.Unlock()
+	if last := (r.Size() - 1) / r.blockSize; index+count-1 > last {
+		count = last - index + 1
+	}
+	start := index * r.blockSize
+	end := start + count*r.blockSize
+	if end > r.Size() {
+		end = r.Size()
+	}
+	buf := make([]byte, end-start)
+	if err := r.fetch(buf, start); err != nil {
+		return nil, err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.closed {
+		return nil, os.ErrClosed
+	}
+	r.next = index + count
+	// add the requested block last so that it is the most recently used
+	for i := count - 1; i >= 0; i-- {
+		blockEnd := (i + 1) * r.blockSize
+		if blockEnd > int64(len(buf)) {
+			blockEnd = int64(len(buf))
+		}
+		r.cache(index+i, buf[i*r.blockSize:blockEnd])
+	}
+	return r.blocks[index].Value.(*cachedBlock).data, nil
+}
+
+func (r *ObjectReader) cache(index int64, data []byte) {
+	if elem, ok := r.blocks[index]; ok {
+		r.lru.Remove(elem)
+	}
+	r.blocks[index] = r.lru.PushFront(&cachedBlock{index: index, data: data})
+	for r.lru.Len() > r.cacheBlocks {
+		elem := r.lru.Back()
+		r.lru.Remove(elem)
+		delete(r.blocks, elem.Value.(*cachedBlock).index)
+	}
+}
+
+// fetch reads len(buf) bytes of the object from offset start
+func (r *ObjectReader) fetch(buf []byte, start int64) error {
+	options := append([]Option{
+		Range(fmt.Sprintf("bytes=%d-%d", start, start+int64(len(buf))-1)),
+		IfMatch(r.etag),
+	}, r.options...)
+	stream, err := r.api.GetObjectReader(r.bucket, r.object, options...)
+	if err != nil {
+		if e, ok := err.(*Error); ok && e.HTTPStatusCode == http.StatusPreconditionFailed {
+			return ErrObjectChanged
+		}
+		return err
+	}
+	defer stream.Close()
+	if stream.Meta.ETag != r.meta.ETag {
+		return ErrObjectChanged
+	}
+	offset := int64(0)
+	if value := http.Header(stream.Meta.Header).Get("Content-Range"); value != "" {
+		if offset, _, _, err = parseContentRange(value); err != nil {
+			return err
+		}
+	}
+	if offset != start {
+		return fmt.Errorf("unexpected range offset %d, expect %d", offset, start)
+	}
+	if _, err := io.ReadFull(stream, buf); err != nil {
+		if err == io.ErrUnexpectedEOF || err == io.EOF {
+			return ErrObjectChanged
+		}
+		return err
+	}
+	return nil
+}
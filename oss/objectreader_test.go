@@ -0,0 +1,244 @@
+package oss_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"io/ioutil"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/aliyun/aliyun-oss-go-sdk/oss"
+)
+
+func TestGetObjectReader(t *testing.T) {
+	server := newTestServer(t)
+	defer server.Close()
+	transport := &testTransport{}
+	api := server.API(oss.CRC64Check(true), transport.client())
+	data := testPlaintext(1000)
+	if err := api.PutObject(testBucketName, testObjectName, bytes.NewReader(data), oss.Meta("Color", "red")); err != nil {
+		t.Fatal(err)
+	}
+	var events []oss.ProgressEventType
+	stream, err := api.GetObjectReader(testBucketName, testObjectName, oss.Progress(func(event oss.ProgressEvent) {
+		if event.Type != oss.ProgressTransferring {
+			events = append(events, event.Type)
+		}
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected, actual := "red", stream.Meta.UserMeta["color"]; actual != expected {
+		t.Fatalf(expectBut, expected, actual)
+	}
+	body, err := ioutil.ReadAll(stream)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stream.Close()
+	if !bytes.Equal(body, data) {
+		t.Fatalf(expectBut, data, body)
+	}
+	if expected := []oss.ProgressEventType{oss.ProgressStarted, oss.ProgressCompleted}; len(events) != 2 || events[0] != expected[0] || events[1] != expected[1] {
+		t.Fatalf(expectBut, expected, events)
+	}
+
+	stream, err = api.GetObjectReader(testBucketName, testObjectName, oss.Range("bytes=10-19"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ = ioutil.ReadAll(stream)
+	stream.Close()
+	if expected := data[10:20]; !bytes.Equal(body, expected) {
+		t.Fatalf(expectBut, expected, body)
+	}
+
+	_, err = api.GetObjectReader(testBucketName, testObjectName, oss.IfNoneMatch(etagOf(data)))
+	if e, ok := err.(*oss.Error); !ok || e.HTTPStatusCode != 304 {
+		t.Fatalf(expectBut, "304 error", err)
+	}
+
+	transport.setBadCRC(true)
+	api.PutObject(testBucketName, testObjectName, bytes.NewReader(data))
+	stream, err = api.GetObjectReader(testBucketName, testObjectName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stream.Close()
+	if _, err := ioutil.ReadAll(stream); !isCRCError(err) {
+		t.Fatalf(expectBut, "CRCError", err)
+	}
+	if _, err := api.GetObjectReader(testBucketName, "missing"); !oss.IsErrorCode(err, "NoSuchKey") {
+		t.Fatalf(expectBut, "NoSuchKey", err)
+	}
+}
+
+func TestOpenObjectZip(t *testing.T) {
+	server := newTestServer(t)
+	defer server.Close()
+	api := server.API()
+	buf := new(bytes.Buffer)
+	zw := zip.NewWriter(buf)
+	files := map[string][]byte{"a.txt": []byte("hello"), "b.bin": testPlaintext(5000)}
+	for name, data := range files {
+		w, _ := zw.Create(name)
+		w.Write(data)
+	}
+	zw.Close()
+	if err := api.PutObject(testBucketName, "test.zip", bytes.NewReader(buf.Bytes())); err != nil {
+		t.Fatal(err)
+	}
+	r, err := api.OpenObject(testBucketName, "test.zip", oss.ReaderBlockSize(512), oss.ReaderReadAhead(2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	zr, err := zip.NewReader(r, r.Size())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, file := range zr.File {
+		rc, err := file.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, err := ioutil.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expected := files[file.Name]; !bytes.Equal(data, expected) {
+			t.Fatalf(testcaseExpectBut, file.Name, expected, data)
+		}
+	}
+}
+
+func TestObjectReaderSeek(t *testing.T) {
+	server := newTestServer(t)
+	defer server.Close()
+	transport := &testTransport{}
+	api := server.API(transport.client())
+	data := testPlaintext(1000)
+	if err := api.PutObject(testBucketName, testObjectName, bytes.NewReader(data)); err != nil {
+		t.Fatal(err)
+	}
+	r, err := api.OpenObject(testBucketName, testObjectName, oss.ReaderBlockSize(100), oss.ReaderReadAhead(3), oss.ReaderCacheBlocks(4))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if transport.getCount() != 0 {
+		t.Fatalf(expectBut, 0, transport.getCount())
+	}
+	body, err := ioutil.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(body, data) {
+		t.Fatalf(expectBut, data, body)
+	}
+	// blocks 0-3, 4-7 and 8-9 with read-ahead
+	if expected := 3; transport.getCount() != expected {
+		t.Fatalf(expectBut, expected, transport.getCount())
+	}
+	// blocks 6-9 are cached
+	if _, err := r.Seek(-350, io.SeekEnd); err != nil {
+		t.Fatal(err)
+	}
+	p := make([]byte, 350)
+	if _, err := io.ReadFull(r, p); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(p, data[650:]) || transport.getCount() != 3 {
+		t.Fatalf(expectBut, data[650:], p)
+	}
+	if n, err := r.Read(p); n != 0 || err != io.EOF {
+		t.Fatalf(expectBut, io.EOF, err)
+	}
+	// a random read only fetches one block
+	if _, err := r.ReadAt(p[:10], 105); err != nil || !bytes.Equal(p[:10], data[105:115]) || transport.getCount() != 4 {
+		t.Fatalf(expectBut, data[105:115], p[:10])
+	}
+	if _, err := r.Seek(-1, io.SeekStart); err == nil {
+		t.Fatal("expect error but got nil")
+	}
+	r.Close()
+	if _, err := r.ReadAt(p, 0); err == nil {
+		t.Fatal("expect error but got nil")
+	}
+}
+
+func TestObjectReaderConcurrent(t *testing.T) {
+	server := newTestServer(t)
+	defer server.Close()
+	api := server.API()
+	data := testPlaintext(4096)
+	if err := api.PutObject(testBucketName, testObjectName, bytes.NewReader(data)); err != nil {
+		t.Fatal(err)
+	}
+	r, err := api.OpenObject(testBucketName, testObjectName, oss.ReaderBlockSize(256), oss.ReaderCacheBlocks(2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	var wg sync.WaitGroup
+	errs := make(chan error, 8)
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(off int64) {
+			defer wg.Done()
+			p := make([]byte, 300)
+			if _, err := r.ReadAt(p, off); err != nil {
+				errs <- err
+			} else if !bytes.Equal(p, data[off:off+300]) {
+				errs <- oss.ErrObjectChanged
+			}
+		}(int64(i * 450))
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Fatal(err)
+	}
+}
+
+func TestObjectReaderChanged(t *testing.T) {
+	server := newTestServer(t)
+	defer server.Close()
+	api := server.API()
+	if err := api.PutObject(testBucketName, testObjectName, strings.NewReader("version 1")); err != nil {
+		t.Fatal(err)
+	}
+	r, err := api.OpenObject(testBucketName, testObjectName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	if err := api.PutObject(testBucketName, testObjectName, strings.NewReader("version 2")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ioutil.ReadAll(r); err != oss.ErrObjectChanged {
+		t.Fatalf(expectBut, oss.ErrObjectChanged, err)
+	}
+}
+
+func TestObjectReaderEmpty(t *testing.T) {
+	server := newTestServer(t)
+	defer server.Close()
+	api := server.API()
+	if err := api.PutObject(testBucketName, testObjectName, strings.NewReader("")); err != nil {
+		t.Fatal(err)
+	}
+	r, err := api.OpenObject(testBucketName, testObjectName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	if body, err := ioutil.ReadAll(r); err != nil || len(body) != 0 {
+		t.Fatalf(expectBut, "empty body", body)
+	}
+	if _, err := api.OpenObject(testBucketName, "missing"); err == nil {
+		t.Fatal("expect error but got nil")
+	}
+}
@@ -1,10 +1,10 @@
 package oss
 
 import (
-	"net"
 	"net/http"
 	"strconv"
-	"strings"
+
+	"github.com/aliyun/aliyun-oss-go-sdk/oss/internal/endpoint"
 )
 
 type (
@@ -12,34 +12,14 @@ type (
 	Option func(*http.Request) error
 )
 
-var (
-	ossHosts = []string{
-		"aliyun-inc.com", "aliyuncs.com", "alibaba.net", "s3.amazonaws.com",
-	}
-)
-
 func bucketHost(bucket string) Option {
 	return func(req *http.Request) error {
-		if isOSSDomain(req.Host) {
+		if endpoint.IsOSSDomain(req.Host) {
 			req.Host = bucket + "." + req.Host
 		}
 		return nil
 	}
 }
-func isOSSDomain(hostPort string) bool {
-	host, _, err := net.SplitHostPort(hostPort)
-	if err != nil {
-		host = hostPort
-	}
-	for _, ossHost := range ossHosts {
-		if strings.HasSuffix(host, ossHost) {
-			return true
-		}
-	}
-	return false
-	// ip := net.ParseIP(host)
-	// return ip == nil
-}
 
 // ACL is an option to set X-Oss-Acl header
 func ACL(acl ACLType) Option {
@@ -143,6 +123,11 @@ func ObjectACL(acl ACLType) Option {
 	return setHeader("X-Oss-Object-Acl", string(acl))
 }
 
+// StorageClass is an option to set X-Oss-Storage-Class header
+func StorageClass(class StorageClassType) Option {
+	return setHeader("X-Oss-Storage-Class", string(class))
+}
+
 // ContentLength is an option to set Content-Length header
 func ContentLength(length int64) Option {
 	return func(req *http.Request) error {
@@ -186,9 +171,9 @@ func Marker(value string) Option {
 	return addParam("marker", value)
 }
 
-// MaxKeys is an option to set maxkeys parameter
+// MaxKeys is an option to set max-keys parameter
 func MaxKeys(value int) Option {
-	return addParam("maxkeys", strconv.Itoa(value))
+	return addParam("max-keys", strconv.Itoa(value))
 }
 
 // Prefix is an option to set prefix parameter
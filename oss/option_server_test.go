@@ -0,0 +1,26 @@
+package oss_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/aliyun/aliyun-oss-go-sdk/oss"
+)
+
+func TestMaxKeysListing(t *testing.T) {
+	server := newTestServer(t)
+	defer server.Close()
+	api := server.API()
+	for _, key := range []string{"a", "b/1", "b/2", "c"} {
+		if err := api.PutObject(testBucketName, key, strings.NewReader(key)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	res, err := api.GetBucket(testBucketName, oss.Delimiter("/"), oss.MaxKeys(2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := "b/"; len(res.Contents) != 1 || !res.IsTruncated || res.NextMarker != expected {
+		t.Fatalf(expectBut, expected, res)
+	}
+}
@@ -156,6 +156,16 @@ var headerTestcases = []optionTestCase{
 		key:    "X-Oss-Object-Acl",
 		value:  "private",
 	},
+	{
+		option: StorageClass(ArchiveStorage),
+		key:    "X-Oss-Storage-Class",
+		value:  "Archive",
+	},
+	{
+		option: TrafficLimit(MaxTrafficLimit),
+		key:    "X-Oss-Traffic-Limit",
+		value:  "838860800",
+	},
 	{
 		option: ServerSideEncryption(""),
 		key:    "X-Oss-Server-Side-Encryption",
@@ -191,7 +201,7 @@ var paramTestCases = []optionTestCase{
 	},
 	{
 		option: MaxKeys(150),
-		key:    "maxkeys",
+		key:    "max-keys",
 		value:  "150",
 	},
 	{
@@ -0,0 +1,282 @@
+package osstest
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/aliyun/aliyun-oss-go-sdk/oss"
+	"github.com/aliyun/aliyun-oss-go-sdk/oss/internal/listing"
+)
+
+// DefaultLocation is the location of a bucket created without one
+const DefaultLocation = "oss-cn-hangzhou"
+
+// bucketConfigs are the subresources of the bucket configurations saved as
+// XML, mapped to the error code of a missing configuration
+var bucketConfigs = map[string]string{
+	"cors":      "NoSuchCORSConfiguration",
+	"lifecycle": "NoSuchLifecycle",
+	"website":   "NoSuchWebsiteConfiguration",
+	"referer":   "",
+	"logging":   "",
+}
+
+// default configurations returned when they are not set
+var defaultConfigs = map[string]interface{}{
+	"referer": &oss.RefererConfiguration{AllowEmptyReferer: true},
+	"logging": &oss.BucketLoggingStatus{},
+}
+
+type (
+	createBucketConfiguration struct {
+		LocationConstraint string
+	}
+
+	deleteRequest struct {
+		Quiet  bool
+		Object []struct {
+			Key string
+		}
+	}
+)
+
+func (h *Handler) routeBucket(r *request) error {
+	for subresource := range bucketConfigs {
+		if r.has(subresource) {
+			return h.bucketConfig(r, subresource)
+		}
+	}
+	switch r.Method {
+	case "PUT":
+		if r.has("acl") {
+			return h.putBucketACL(r)
+		}
+		return h.putBucket(r)
+	case "GET":
+		switch {
+		case r.has("acl"):
+			return h.getBucketACL(r)
+		case r.has("location"):
+			return h.getBucketLocation(r)
+		case r.has("uploads"):
+			return h.listUploads(r)
+		}
+		return h.listObjects(r)
+	case "DELETE":
+		return h.deleteBucket(r)
+	case "POST":
+		if r.has("delete") {
+			return h.deleteObjects(r)
+		}
+		if r.isPostObject() {
+			return h.postObject(r)
+		}
+	}
+	return errNotImplemented
+}
+
+// owner returns the owner of all buckets, the first AccessKeyId in order
+func (h *Handler) owner() oss.Owner {
+	var owner string
+	for id := range h.credentials {
+		if owner == "" || id < owner {
+			owner = id
+		}
+	}
+	return oss.Owner{ID: owner, DisplayName: owner}
+}
+
+func (h *Handler) listBuckets(r *request) error {
+	buckets, err := h.storage.ListBuckets()
+	if err != nil {
+		return err
+	}
+	res := &oss.ListAllMyBucketsResult{Owner: h.owner()}
+	for _, bucket := range buckets {
+		res.Buckets = append(res.Buckets, oss.Bucket{Location: bucket.Location, Name: bucket.Name, CreationDate: bucket.CreationDate})
+	}
+	return r.writeXML(res)
+}
+
+func (h *Handler) putBucket(r *request) error {
+	config := &createBucketConfiguration{LocationConstraint: DefaultLocation}
+	if len(r.body) > 0 {
+		if err := r.decodeXML(config); err != nil {
+			return err
+		}
+	}
+	acl := oss.ACLType(r.Header.Get("X-Oss-Acl"))
+	if acl == "" {
+		acl = oss.PrivateACL
+	}
+	if !validACL(acl) {
+		return newError(http.StatusBadRequest, "InvalidArgument", "no such bucket access control exists")
+	}
+	err := h.storage.CreateBucket(&Bucket{
+		Name:         r.bucket,
+		Location:     config.LocationConstraint,
+		ACL:          acl,
+		CreationDate: h.now(),
+	})
+	if err == ErrBucketExists {
+		// creating an existing bucket of the same owner succeeds
+		return nil
+	}
+	return err
+}
+
+func validACL(acl oss.ACLType) bool {
+	return acl == oss.PrivateACL || acl == oss.PublicReadACL || acl == oss.PublicReadWriteACL
+}
+
+func (h *Handler) putBucketACL(r *request) error {
+	bucket, err := h.storage.GetBucket(r.bucket)
+	if err != nil {
+		return err
+	}
+	if bucket.ACL = oss.ACLType(r.Header.Get("X-Oss-Acl")); !validACL(bucket.ACL) {
+		return newError(http.StatusBadRequest, "InvalidArgument", "no such bucket access control exists")
+	}
+	return h.storage.UpdateBucket(bucket)
+}
+
+func (h *Handler) getBucketACL(r *request) error {
+	bucket, err := h.storage.GetBucket(r.bucket)
+	if err != nil {
+		return err
+	}
+	return r.writeXML(&oss.AccessControlPolicy{Owner: h.owner(), AccessControlList: oss.AccessControlList{Grant: string(bucket.ACL)}})
+}
+
+func (h *Handler) getBucketLocation(r *request) error {
+	bucket, err := h.storage.GetBucket(r.bucket)
+	if err != nil {
+		return err
+	}
+	return r.writeXML(&oss.LocationConstraint{Value: bucket.Location})
+}
+
+func (h *Handler) deleteBucket(r *request) error {
+	objects, err := h.storage.ListObjects(r.bucket, "")
+	if err != nil {
+		return err
+	}
+	if len(objects) > 0 {
+		return newError(http.StatusConflict, "BucketNotEmpty", "The bucket you tried to delete is not empty.")
+	}
+	if err := h.storage.DeleteBucket(r.bucket); err != nil {
+		return err
+	}
+	r.w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+// bucketConfig puts, gets or deletes a bucket configuration saved as XML
+func (h *Handler) bucketConfig(r *request, subresource string) error {
+	bucket, err := h.storage.GetBucket(r.bucket)
+	if err != nil {
+		return err
+	}
+	switch r.Method {
+	case "PUT":
+		var v struct{}
+		if err := r.decodeXML(&v); err != nil {
+			return err
+		}
+		if bucket.Configs == nil {
+			bucket.Configs = make(map[string][]byte)
+		}
+		bucket.Configs[subresource] = r.body
+		return h.storage.UpdateBucket(bucket)
+	case "GET":
+		config, ok := bucket.Configs[subresource]
+		if !ok {
+			if code := bucketConfigs[subresource]; code != "" {
+				return newError(http.StatusNotFound, code, "The configuration does not exist.")
+			}
+			return r.writeXML(defaultConfigs[subresource])
+		}
+		r.w.Header().Set("Content-Type", "application/xml")
+		r.w.WriteHeader(http.StatusOK)
+		_, err := r.w.Write(config)
+		return err
+	case "DELETE":
+		delete(bucket.Configs, subresource)
+		if err := h.storage.UpdateBucket(bucket); err != nil {
+			return err
+		}
+		r.w.WriteHeader(http.StatusNoContent)
+		return nil
+	}
+	return errNotImplemented
+}
+
+// listObjects implements GetBucket with the prefix, delimiter, marker and
+// max-keys parameters
+func (h *Handler) listObjects(r *request) error {
+	q := r.URL.Query()
+	prefix, delimiter, marker := q.Get("prefix"), q.Get("delimiter"), q.Get("marker")
+	maxKeys := 100
+	if value := q.Get("max-keys"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 1 || n > 1000 {
+			return newError(http.StatusBadRequest, "InvalidArgument", "max-keys must be between 1 and 1000")
+		}
+		maxKeys = n
+	}
+	objects, err := h.storage.ListObjects(r.bucket, prefix)
+	if err != nil {
+		return err
+	}
+	keys := make([]string, len(objects))
+	byKey := make(map[string]*Object, len(objects))
+	for i, obj := range objects {
+		keys[i] = obj.Key
+		byKey[obj.Key] = obj
+	}
+	page := listing.List(keys, prefix, delimiter, marker, maxKeys)
+	res := &oss.ListBucketResult{
+		Name:           r.bucket,
+		Prefix:         prefix,
+		Marker:         marker,
+		MaxKeys:        maxKeys,
+		Delimiter:      delimiter,
+		IsTruncated:    page.IsTruncated,
+		NextMarker:     page.NextMarker,
+		CommonPrefixes: page.CommonPrefixes,
+	}
+	owner := h.owner()
+	for _, key := range page.Keys {
+		obj := byKey[key]
+		res.Contents = append(res.Contents, oss.Content{
+			Key:          obj.Key,
+			LastModified: obj.ModTime,
+			ETag:         obj.ETag,
+			Type:         obj.Header.Get("X-Oss-Object-Type"),
+			Size:         obj.Size,
+			StorageClass: obj.Header.Get("X-Oss-Storage-Class"),
+			Owner:        owner,
+		})
+	}
+	return r.writeXML(res)
+}
+
+func (h *Handler) deleteObjects(r *request) error {
+	var del deleteRequest
+	if err := r.decodeXML(&del); err != nil {
+		return err
+	}
+	if len(del.Object) > 1000 {
+		return newError(http.StatusBadRequest, "MalformedXML", "At most 1000 objects can be deleted at a time.")
+	}
+	res := &oss.DeleteResult{}
+	for _, obj := range del.Object {
+		if err := h.storage.DeleteObject(r.bucket, obj.Key); err != nil {
+			return err
+		}
+		if !del.Quiet {
+			res.Deleted = append(res.Deleted, oss.Deleted{Key: obj.Key})
+		}
+	}
+	return r.writeXML(res)
+}
@@ -0,0 +1,307 @@
+package osstest
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"hash"
+	"io"
+	"io/ioutil"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aliyun/aliyun-oss-go-sdk/oss/internal/endpoint"
+)
+
+// scrubbed replaces the credentials and secrets in a recording
+const scrubbed = "SCRUBBED"
+
+// query parameters of presigned URLs and PostObject carrying credentials
+var credentialParams = []string{"OSSAccessKeyId", "Signature", "security-token"}
+
+type (
+	// Cassette is an http.RoundTripper recording OSS interactions to a file
+	// and replaying them later, used with the oss.HTTPClient option. A recording
+	// has no Authorization header, security token or the given secrets, and
+	// its requests are dated at the start of the recording. A replayed
+	// request matches the first unplayed interaction with the same method,
+	// canonical resource and body hash, and fails with a CassetteMissError
+	// if there is none. It is safe for concurrent use.
+	Cassette struct {
+		path      string
+		transport http.RoundTripper
+		secrets   []string
+		replay    bool
+
+		mu     sync.Mutex
+		file   cassetteFile
+		played []bool
+	}
+
+	// cassetteFile is the JSON file of a Cassette
+	cassetteFile struct {
+		// Time is the start of the recording
+		Time         time.Time
+		Interactions []*interaction
+	}
+	// interaction is a recorded request and its response
+	interaction struct {
+		Request  recordedRequest
+		Response recordedResponse
+	}
+	// recordedRequest is the part of a request kept in a Cassette
+	recordedRequest struct {
+		Method string
+		// Resource is "/bucket/object" with the sorted query parameters
+		Resource string
+		// BodyHash is the hex SHA-256 of the body
+		BodyHash string
+		Header   http.Header
+	}
+	// recordedResponse is a response kept in a Cassette
+	recordedResponse struct {
+		StatusCode    int
+		Header        http.Header
+		ContentLength int64
+		Body          []byte
+	}
+
+	// CassetteMissError happens when no recorded interaction matches a
+	// replayed request
+	CassetteMissError struct {
+		Method   string
+		Resource string
+		BodyHash string
+	}
+)
+
+// NewCassetteRecorder creates a Cassette recording the interactions sent
+// through transport, which is http.DefaultTransport if nil. The secrets,
+// e.g. the AccessKeySecret, are scrubbed from the recording. Save writes
+// the recording to path.
+func NewCassetteRecorder(path string, transport http.RoundTripper, secrets ...string) *Cassette {
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	return &Cassette{
+		path:      path,
+		transport: transport,
+		secrets:   secrets,
+		file:      cassetteFile{Time: time.Now().UTC().Truncate(time.Second)},
+	}
+}
+
+// LoadCassette loads a Cassette replaying the interactions recorded in path
+func LoadCassette(path string) (*Cassette, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	c := &Cassette{path: path, replay: true}
+	if err := json.Unmarshal(data, &c.file); err != nil {
+		return nil, err
+	}
+	c.played = make([]bool, len(c.file.Interactions))
+	return c, nil
+}
+
+// Now is the clock of an API using the Cassette, set by the oss.Clock
+// option.
+// It returns the current time while recording and the start of the
+// recording while replaying.
+func (c *Cassette) Now() time.Time {
+	if c.replay {
+		return c.file.Time
+	}
+	return time.Now()
+}
+
+// Save writes the recorded interactions to the file of the Cassette
+func (c *Cassette) Save() error {
+	c.mu.Lock()
+	data, err := json.MarshalIndent(&c.file, "", "\t")
+	c.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(c.path, data, 0644)
+}
+
+// Unplayed returns the number of recorded interactions not replayed yet
+func (c *Cassette) Unplayed() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	n := 0
+	for _, played := range c.played {
+		if !played {
+			n++
+		}
+	}
+	return n
+}
+
+// RoundTrip implements http.RoundTripper
+func (c *Cassette) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		body, err = ioutil.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+	recorded := recordedRequest{Method: req.Method, Resource: c.resource(req), BodyHash: bodyHash(req.Header, body)}
+	if c.replay {
+		return c.play(req, &recorded)
+	}
+	req = req.Clone(req.Context())
+	req.Body = ioutil.NopCloser(bytes.NewReader(body))
+	resp, err := c.transport.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	data, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = ioutil.NopCloser(bytes.NewReader(data))
+	recorded.Header = c.scrubHeader(req.Header)
+	recorded.Header.Set("Date", c.file.Time.Format(http.TimeFormat))
+	response := recordedResponse{
+		StatusCode:    resp.StatusCode,
+		Header:        c.scrubHeader(resp.Header),
+		ContentLength: resp.ContentLength,
+		Body:          []byte(c.scrub(string(data))),
+	}
+	// scrubbing changes the length of the body, but not the length of the
+	// empty body of a HEAD response
+	if len(response.Body) != len(data) {
+		response.ContentLength = int64(len(response.Body))
+		if response.Header.Get("Content-Length") != "" {
+			response.Header.Set("Content-Length", strconv.Itoa(len(response.Body)))
+		}
+	}
+	c.mu.Lock()
+	c.file.Interactions = append(c.file.Interactions, &interaction{Request: recorded, Response: response})
+	c.mu.Unlock()
+	return resp, nil
+}
+
+// play returns the response of the first unplayed interaction matching a
+// request
+func (c *Cassette) play(req *http.Request, recorded *recordedRequest) (*http.Response, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for i, interaction := range c.file.Interactions {
+		r := &interaction.Request
+		if c.played[i] || r.Method != recorded.Method || r.Resource != recorded.Resource || r.BodyHash != recorded.BodyHash {
+			continue
+		}
+		c.played[i] = true
+		resp := &interaction.Response
+		return &http.Response{
+			Status:        fmt.Sprintf("%d %s", resp.StatusCode, http.StatusText(resp.StatusCode)),
+			StatusCode:    resp.StatusCode,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        resp.Header.Clone(),
+			Body:          ioutil.NopCloser(bytes.NewReader(resp.Body)),
+			ContentLength: resp.ContentLength,
+			Request:       req,
+		}, nil
+	}
+	return nil, &CassetteMissError{Method: recorded.Method, Resource: recorded.Resource, BodyHash: recorded.BodyHash}
+}
+
+// resource returns "/bucket/object" and the sorted query parameters of a
+// request without credentials, the same for virtual-hosted and path-style
+// URLs
+func (c *Cassette) resource(req *http.Request) string {
+	bucket, object := endpoint.Target(req.URL)
+	q := req.URL.Query()
+	for _, param := range credentialParams {
+		q.Del(param)
+	}
+	resource := "/" + bucket
+	if bucket != "" {
+		resource += "/" + object
+	}
+	if len(q) > 0 {
+		resource += "?" + q.Encode()
+	}
+	return c.scrub(resource)
+}
+
+// bodyHash returns the hex SHA-256 of a request body. The fields of a
+// multipart form are hashed instead, without the random boundary and the
+// credentials of PostObject.
+func bodyHash(header http.Header, body []byte) string {
+	h := sha256.New()
+	if mediaType, params, err := mime.ParseMediaType(header.Get("Content-Type")); err == nil && mediaType == "multipart/form-data" {
+		if hashForm(h, multipart.NewReader(bytes.NewReader(body), params["boundary"])) == nil {
+			return hex.EncodeToString(h.Sum(nil))
+		}
+		h.Reset()
+	}
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func hashForm(h hash.Hash, r *multipart.Reader) error {
+	for {
+		part, err := r.NextPart()
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		name := part.FormName()
+		if name == "Signature" || name == "OSSAccessKeyId" || name == "x-oss-security-token" {
+			continue
+		}
+		fmt.Fprintf(h, "%q\n", name)
+		if _, err := io.Copy(h, part); err != nil {
+			return err
+		}
+	}
+}
+
+// scrubHeader returns a copy of header without credentials and secrets
+func (c *Cassette) scrubHeader(header http.Header) http.Header {
+	scrubbedHeader := make(http.Header, len(header))
+	for key, values := range header {
+		switch key {
+		case "Authorization":
+			continue
+		case "X-Oss-Security-Token":
+			values = []string{scrubbed}
+		}
+		for _, value := range values {
+			scrubbedHeader.Add(key, c.scrub(value))
+		}
+	}
+	return scrubbedHeader
+}
+
+func (c *Cassette) scrub(s string) string {
+	for _, secret := range c.secrets {
+		if secret != "" {
+			s = strings.Replace(s, secret, scrubbed, -1)
+		}
+	}
+	return s
+}
+
+func (e *CassetteMissError) Error() string {
+	return fmt.Sprintf("no recorded interaction matches %s %s (body SHA-256 %s)", e.Method, e.Resource, e.BodyHash)
+}
@@ -0,0 +1,100 @@
+package osstest
+
+import (
+	"bytes"
+	"io/ioutil"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/aliyun/aliyun-oss-go-sdk/oss"
+)
+
+func TestCassette(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cassette")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "cassette.json")
+
+	server, _ := newTestServer(t)
+	secret := server.AccessKeySecret
+	recorder := NewCassetteRecorder(path, nil, secret)
+	api := server.API(oss.HTTPClient(&http.Client{Transport: recorder}), oss.SecurityToken("token"), oss.Clock(recorder.Now))
+	session := func(api *oss.API) (string, error) {
+		for _, data := range []string{"v1", "v2 " + secret} {
+			if err := api.PutObject(testBucket, "a", strings.NewReader(data)); err != nil {
+				return "", err
+			}
+		}
+		var buf bytes.Buffer
+		_, err := api.GetObject(testBucket, "a", &buf)
+		return buf.String(), err
+	}
+	if data, err := session(api); err != nil || data != "v2 "+secret {
+		t.Fatalf(expectBut, "v2", []interface{}{data, err})
+	}
+	server.Close()
+	if err := recorder.Save(); err != nil {
+		t.Fatal(err)
+	}
+	recording, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, secret := range []string{"Authorization", server.AccessKeyID + ":", `"token"`, secret} {
+		if bytes.Contains(recording, []byte(secret)) {
+			t.Fatalf(expectBut, "no "+secret, string(recording))
+		}
+	}
+
+	cassette, err := LoadCassette(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the scrubbed body of GetObject has its own length
+	get := cassette.file.Interactions[2].Response
+	if length := strconv.Itoa(len(get.Body)); get.ContentLength != int64(len(get.Body)) || get.Header.Get("Content-Length") != length {
+		t.Fatalf(expectBut, length, []interface{}{get.ContentLength, get.Header.Get("Content-Length")})
+	}
+	api = oss.New("oss-cn-hangzhou.aliyuncs.com", server.AccessKeyID, secret, oss.HTTPClient(&http.Client{Transport: cassette}), oss.Clock(cassette.Now))
+	if cassette.Unplayed() != 3 {
+		t.Fatalf(expectBut, 3, cassette.Unplayed())
+	}
+	if data, err := session(api); err != nil || data != "v2 "+scrubbed {
+		t.Fatalf(expectBut, "v2 "+scrubbed, []interface{}{data, err})
+	}
+	if cassette.Unplayed() != 0 {
+		t.Fatalf(expectBut, 0, cassette.Unplayed())
+	}
+	err = api.PutObject(testBucket, "a", strings.NewReader("v3"))
+	if e, ok := err.(*url.Error); !ok || e.Err.(*CassetteMissError).Resource != "/"+testBucket+"/a" {
+		t.Fatalf(expectBut, "CassetteMissError", err)
+	}
+}
+
+func TestCassetteBodyHash(t *testing.T) {
+	form := func(boundary, signature string) (http.Header, []byte) {
+		var buf bytes.Buffer
+		w := multipart.NewWriter(&buf)
+		w.SetBoundary(boundary)
+		w.WriteField("key", "a")
+		w.WriteField("Signature", signature)
+		w.Close()
+		return http.Header{"Content-Type": {w.FormDataContentType()}}, buf.Bytes()
+	}
+	h1, b1 := form("boundary1", "signature1")
+	h2, b2 := form("boundary2", "signature2")
+	if bodyHash(h1, b1) != bodyHash(h2, b2) {
+		t.Fatalf(expectBut, bodyHash(h1, b1), bodyHash(h2, b2))
+	}
+	if bodyHash(nil, b1) == bodyHash(nil, b2) {
+		t.Fatalf(expectBut, "different hashes", bodyHash(nil, b1))
+	}
+}
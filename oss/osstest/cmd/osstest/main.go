@@ -0,0 +1,34 @@
+// Command osstest runs a fake OSS server for offline testing, e.g.
+//
+//	go run main.go -addr 127.0.0.1:8080 -dir /tmp/oss
+//
+// and point oss.New at "127.0.0.1:8080" with the same credentials.
+package main
+
+import (
+	"flag"
+	"log"
+	"net/http"
+
+	"github.com/aliyun/aliyun-oss-go-sdk/oss/osstest"
+)
+
+func main() {
+	var addr, dir, accessKeyID, accessKeySecret string
+	flag.StringVar(&addr, "addr", "127.0.0.1:8080", "listening address")
+	flag.StringVar(&dir, "dir", "", "storage directory, buckets are kept in memory if empty")
+	flag.StringVar(&accessKeyID, "id", osstest.DefaultAccessKeyID, "access key ID")
+	flag.StringVar(&accessKeySecret, "secret", osstest.DefaultAccessKeySecret, "access key Secret")
+	flag.Parse()
+
+	var storage osstest.Storage = osstest.NewMemoryStorage()
+	if dir != "" {
+		var err error
+		if storage, err = osstest.NewDirStorage(dir); err != nil {
+			log.Fatal(err)
+		}
+	}
+	handler := osstest.NewHandler(storage, map[string]string{accessKeyID: accessKeySecret})
+	log.Printf("osstest listening on %s", addr)
+	log.Fatal(http.ListenAndServe(addr, handler))
+}
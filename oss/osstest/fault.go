@@ -0,0 +1,265 @@
+package osstest
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/aliyun/aliyun-oss-go-sdk/oss"
+	"github.com/aliyun/aliyun-oss-go-sdk/oss/internal/endpoint"
+)
+
+// Status codes of the errors commonly injected by a FaultTransport
+var faultStatus = map[string]int{
+	"SlowDown":             http.StatusServiceUnavailable,
+	"InternalError":        http.StatusInternalServerError,
+	"RequestTimeTooSkewed": http.StatusForbidden,
+	"ServiceUnavailable":   http.StatusServiceUnavailable,
+}
+
+type (
+	// FaultTransport is an http.RoundTripper injecting failures into OSS
+	// requests for resilience testing, used with the oss.HTTPClient option.
+	// The first matching rule that fires applies to a request, and the
+	// random decisions come from a seeded generator, so that the same
+	// sequence of requests is faulted the same way. It is safe for
+	// concurrent use.
+	FaultTransport struct {
+		// Transport sends the requests, http.DefaultTransport if nil
+		Transport http.RoundTripper
+
+		mu    sync.Mutex
+		rules []*FaultRule
+		rand  *rand.Rand
+	}
+
+	// FaultRule injects a Fault into the requests it matches. An empty
+	// Method, Bucket, Object or Subresource matches any request, and an
+	// Object ending with "*" matches a key prefix.
+	FaultRule struct {
+		Method      string
+		Bucket      string
+		Object      string
+		Subresource string
+		// Probability of faulting a matching request, 0 means always
+		Probability float64
+		// Times limits the number of faulted requests, 0 means unlimited
+		Times int
+		Fault Fault
+
+		faulted int
+	}
+
+	// Fault describes how a request fails. Latency is added before the
+	// request is sent; if Code is set, an OSS XML error is returned without
+	// sending the request; otherwise the response body is truncated,
+	// corrupted or reset at a random offset.
+	Fault struct {
+		Latency time.Duration
+		// Code is the OSS error code, e.g. SlowDown, InternalError or
+		// RequestTimeTooSkewed
+		Code string
+		// StatusCode of the error, derived from Code if 0
+		StatusCode int
+		// Truncate ends the response body early without an error
+		Truncate bool
+		// Corrupt flips a byte of the response body
+		Corrupt bool
+		// Reset fails reading the response body with ECONNRESET, or the
+		// request itself if the body is empty
+		Reset bool
+	}
+
+	// faultBody is a response body failing with err after data is read
+	faultBody struct {
+		*bytes.Reader
+		err error
+	}
+)
+
+// NewFaultTransport creates a FaultTransport with a seed and rules
+func NewFaultTransport(seed int64, rules ...*FaultRule) *FaultTransport {
+	return &FaultTransport{rules: rules, rand: rand.New(rand.NewSource(seed))}
+}
+
+// AddRule appends a rule to the FaultTransport
+func (t *FaultTransport) AddRule(rule *FaultRule) {
+	t.mu.Lock()
+	t.rules = append(t.rules, rule)
+	t.mu.Unlock()
+}
+
+// Faulted returns the number of requests faulted by a rule
+func (t *FaultTransport) Faulted(rule *FaultRule) int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return rule.faulted
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *FaultTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	transport := t.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	fault, offset := t.match(req)
+	if fault == nil {
+		return transport.RoundTrip(req)
+	}
+	if fault.Latency > 0 {
+		timer := time.NewTimer(fault.Latency)
+		select {
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+		case <-timer.C:
+		}
+	}
+	if fault.Code != "" {
+		if req.Body != nil {
+			req.Body.Close()
+		}
+		return faultResponse(req, fault, offset), nil
+	}
+	if !fault.Truncate && !fault.Corrupt && !fault.Reset {
+		return transport.RoundTrip(req)
+	}
+	resp, err := transport.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	data, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	if len(data) == 0 {
+		if fault.Reset {
+			return nil, connectionReset("read")
+		}
+		resp.Body = ioutil.NopCloser(bytes.NewReader(data))
+		return resp, nil
+	}
+	offset %= len(data)
+	body := &faultBody{}
+	switch {
+	case fault.Reset:
+		// the connection fails before the length announced
+		body.err = connectionReset("read")
+		data = data[:offset]
+	case fault.Truncate:
+		data = data[:offset]
+		resp.ContentLength = int64(len(data))
+		if resp.Header.Get("Content-Length") != "" {
+			resp.Header.Set("Content-Length", strconv.Itoa(len(data)))
+		}
+	}
+	if fault.Corrupt && len(data) > 0 {
+		data[offset%len(data)] ^= 0xff
+	}
+	body.Reader = bytes.NewReader(data)
+	resp.Body = body
+	return resp, nil
+}
+
+// match returns the Fault of the first rule firing for a request and a
+// random offset into the response body
+func (t *FaultTransport) match(req *http.Request) (*Fault, int) {
+	bucket, object := endpoint.Target(req.URL)
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, rule := range t.rules {
+		if !rule.matches(req, bucket, object) || rule.Times > 0 && rule.faulted >= rule.Times {
+			continue
+		}
+		if rule.Probability > 0 && t.rand.Float64() >= rule.Probability {
+			continue
+		}
+		rule.faulted++
+		fault := rule.Fault
+		return &fault, t.rand.Intn(1 << 30)
+	}
+	return nil, 0
+}
+
+func (r *FaultRule) matches(req *http.Request, bucket, object string) bool {
+	if r.Method != "" && r.Method != req.Method || r.Bucket != "" && r.Bucket != bucket {
+		return false
+	}
+	if r.Object != "" {
+		if prefix := strings.TrimSuffix(r.Object, "*"); prefix != r.Object {
+			if !strings.HasPrefix(object, prefix) {
+				return false
+			}
+		} else if r.Object != object {
+			return false
+		}
+	}
+	if r.Subresource != "" {
+		if _, ok := req.URL.Query()[r.Subresource]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// faultResponse returns an OSS XML error response
+func faultResponse(req *http.Request, fault *Fault, id int) *http.Response {
+	status := fault.StatusCode
+	if status == 0 {
+		if status = faultStatus[fault.Code]; status == 0 {
+			status = http.StatusInternalServerError
+		}
+	}
+	requestID := fmt.Sprintf("FAULT%019d", id)
+	var body []byte
+	if req.Method != "HEAD" {
+		body, _ = xml.Marshal(&oss.Error{
+			Code:      fault.Code,
+			Message:   "Injected by FaultTransport.",
+			RequestID: requestID,
+			HostID:    req.URL.Host,
+		})
+	}
+	header := make(http.Header)
+	header.Set("Content-Type", "application/xml")
+	header.Set("X-Oss-Request-Id", requestID)
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", status, http.StatusText(status)),
+		StatusCode:    status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          ioutil.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}
+}
+
+func connectionReset(op string) error {
+	return &net.OpError{Op: op, Net: "tcp", Err: os.NewSyscallError(op, syscall.ECONNRESET)}
+}
+
+func (b *faultBody) Read(p []byte) (int, error) {
+	n, err := b.Reader.Read(p)
+	if err == io.EOF && b.err != nil {
+		err = b.err
+	}
+	return n, err
+}
+
+func (b *faultBody) Close() error {
+	return nil
+}
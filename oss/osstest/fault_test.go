@@ -0,0 +1,131 @@
+package osstest
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/aliyun/aliyun-oss-go-sdk/oss"
+	"github.com/aliyun/aliyun-oss-go-sdk/oss/internal/sign"
+)
+
+func TestFaultTransportError(t *testing.T) {
+	server, _ := newTestServer(t)
+	defer server.Close()
+	slowDown := &FaultRule{Method: "PUT", Object: "slow/*", Times: 2, Fault: Fault{Code: "SlowDown"}}
+	skewed := &FaultRule{Subresource: "acl", Fault: Fault{Code: "RequestTimeTooSkewed"}}
+	transport := NewFaultTransport(1, slowDown, skewed)
+	api := server.API(oss.HTTPClient(&http.Client{Transport: transport}))
+	for i := 0; i < 2; i++ {
+		err := api.PutObject(testBucket, "slow/a", strings.NewReader("a"))
+		if e, ok := err.(*oss.Error); !ok || e.Code != "SlowDown" || e.HTTPStatusCode != http.StatusServiceUnavailable {
+			t.Fatalf(expectBut, "SlowDown", err)
+		}
+	}
+	if err := api.PutObject(testBucket, "slow/a", strings.NewReader("a")); err != nil {
+		t.Fatal(err)
+	}
+	if err := api.PutObject(testBucket, "fast", strings.NewReader("a")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := api.GetObjectACL(testBucket, "fast"); !oss.IsErrorCode(err, "RequestTimeTooSkewed") {
+		t.Fatalf(expectBut, "RequestTimeTooSkewed", err)
+	}
+	if _, err := api.HeadObject(testBucket, "fast"); err != nil {
+		t.Fatal(err)
+	}
+	if transport.Faulted(slowDown) != 2 || transport.Faulted(skewed) != 1 {
+		t.Fatalf(expectBut, []int{2, 1}, []int{transport.Faulted(slowDown), transport.Faulted(skewed)})
+	}
+}
+
+func TestFaultTransportBody(t *testing.T) {
+	server, api := newTestServer(t)
+	defer server.Close()
+	data := bytes.Repeat([]byte("0123456789"), 100)
+	if err := api.PutObject(testBucket, "object", bytes.NewReader(data)); err != nil {
+		t.Fatal(err)
+	}
+	for _, testcase := range []struct {
+		fault Fault
+		check func(err error) bool
+	}{
+		{Fault{Truncate: true}, func(err error) bool { _, ok := err.(*oss.CRCError); return ok }},
+		{Fault{Corrupt: true}, func(err error) bool { _, ok := err.(*oss.CRCError); return ok }},
+		{Fault{Reset: true}, func(err error) bool { return errors.Is(err, syscall.ECONNRESET) }},
+	} {
+		transport := NewFaultTransport(1, &FaultRule{Method: "GET", Fault: testcase.fault})
+		api := server.API(oss.HTTPClient(&http.Client{Transport: transport}), oss.CRC64Check(true))
+		var buf bytes.Buffer
+		if _, err := api.GetObject(testBucket, "object", &buf); !testcase.check(err) {
+			t.Fatalf(expectBut, testcase.fault, err)
+		}
+	}
+
+	// a truncated response announces its new length
+	transport := NewFaultTransport(1, &FaultRule{Fault: Fault{Truncate: true}})
+	req, _ := http.NewRequest("GET", server.URL+"/"+testBucket+"/object", nil)
+	req.Header.Set("Date", time.Now().UTC().Format(http.TimeFormat))
+	req.Header.Set("Authorization", "OSS "+server.AccessKeyID+":"+sign.Signature(req, testBucket, []byte(server.AccessKeySecret)))
+	resp, err := transport.RoundTrip(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || len(body) >= len(data) || resp.ContentLength != int64(len(body)) || resp.Header.Get("Content-Length") != strconv.Itoa(len(body)) {
+		t.Fatalf(expectBut, len(body), []interface{}{resp.StatusCode, resp.ContentLength, resp.Header.Get("Content-Length")})
+	}
+}
+
+func TestFaultTransportSeed(t *testing.T) {
+	server, _ := newTestServer(t)
+	defer server.Close()
+	run := func(seed int64) (faulted []bool) {
+		transport := NewFaultTransport(seed, &FaultRule{Probability: 0.5, Fault: Fault{Code: "InternalError"}})
+		api := server.API(oss.HTTPClient(&http.Client{Transport: transport}))
+		for i := 0; i < 20; i++ {
+			err := api.PutObject(testBucket, "a", strings.NewReader("a"))
+			faulted = append(faulted, oss.IsErrorCode(err, "InternalError"))
+		}
+		return faulted
+	}
+	first := run(42)
+	if second := run(42); !reflect.DeepEqual(first, second) {
+		t.Fatalf(expectBut, first, second)
+	}
+	if reflect.DeepEqual(first, make([]bool, 20)) {
+		t.Fatalf(expectBut, "some faulted requests", first)
+	}
+}
+
+func TestFaultTransportLatency(t *testing.T) {
+	server, _ := newTestServer(t)
+	defer server.Close()
+	transport := NewFaultTransport(1, &FaultRule{Fault: Fault{Latency: time.Minute}})
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	req, _ := http.NewRequest("GET", server.URL+"/"+testBucket+"/a", nil)
+	if _, err := transport.RoundTrip(req.WithContext(ctx)); err != context.DeadlineExceeded {
+		t.Fatalf(expectBut, context.DeadlineExceeded, err)
+	}
+	transport = NewFaultTransport(1, &FaultRule{Fault: Fault{Latency: 10 * time.Millisecond}})
+	start := time.Now()
+	resp, err := transport.RoundTrip(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if elapsed := time.Since(start); elapsed < 10*time.Millisecond {
+		t.Fatalf(expectBut, "at least 10ms", elapsed)
+	}
+}
@@ -0,0 +1,196 @@
+package osstest
+
+import (
+	"bytes"
+	"crypto/md5"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aliyun/aliyun-oss-go-sdk/oss"
+)
+
+// minPartSize is the minimum size of a part except the last one
+const minPartSize = 100 * 1024
+
+type (
+	// upload is an ongoing multipart upload, kept in memory
+	upload struct {
+		bucket    string
+		key       string
+		id        string
+		header    http.Header
+		initiated time.Time
+		parts     map[int]*part
+	}
+
+	part struct {
+		data     []byte
+		etag     string
+		modified time.Time
+	}
+)
+
+// upload returns the upload of the uploadId parameter
+func (h *Handler) upload(r *request) (*upload, error) {
+	u, ok := h.uploads[r.URL.Query().Get("uploadId")]
+	if !ok || u.bucket != r.bucket || u.key != r.key {
+		return nil, newError(http.StatusNotFound, "NoSuchUpload", "The specified upload does not exist.")
+	}
+	return u, nil
+}
+
+func (h *Handler) initUpload(r *request) error {
+	if _, err := h.storage.GetBucket(r.bucket); err != nil {
+		return err
+	}
+	u := &upload{
+		bucket:    r.bucket,
+		key:       r.key,
+		id:        fmt.Sprintf("%032X", md5.Sum([]byte(fmt.Sprint(r.bucket, r.key, h.requestID)))),
+		header:    objectHeader(r.Header, oss.MultipartObject),
+		initiated: h.now(),
+		parts:     make(map[int]*part),
+	}
+	h.uploads[u.id] = u
+	return r.writeXML(&oss.InitiateMultipartUploadResult{Bucket: u.bucket, Key: u.key, UploadID: u.id})
+}
+
+func (h *Handler) uploadPart(r *request) error {
+	u, err := h.upload(r)
+	if err != nil {
+		return err
+	}
+	number, err := strconv.Atoi(r.URL.Query().Get("partNumber"))
+	if err != nil || number < 1 || number > 10000 {
+		return newError(http.StatusBadRequest, "InvalidArgument", "Part number must be an integer between 1 and 10000, inclusive.")
+	}
+	data := r.body
+	copied := r.Header.Get("X-Oss-Copy-Source") != ""
+	if copied {
+		source, err := h.copySource(r)
+		if err != nil {
+			return err
+		}
+		if data, err = copyRange(source.Data, r.Header.Get("X-Oss-Copy-Source-Range")); err != nil {
+			return err
+		}
+	}
+	p := &part{data: data, etag: fmt.Sprintf(`"%X"`, md5.Sum(data)), modified: h.now()}
+	u.parts[number] = p
+	if copied {
+		return r.writeXML(&oss.CopyPartResult{LastModified: &p.modified, ETag: p.etag})
+	}
+	r.w.Header().Set("ETag", p.etag)
+	r.w.Header().Set("X-Oss-Hash-Crc64ecma", strconv.FormatUint(oss.CRC64(data), 10))
+	r.w.WriteHeader(http.StatusOK)
+	return nil
+}
+
+// copyRange returns the data in a "bytes=first-last" range, the whole data
+// if the range is empty
+func copyRange(data []byte, value string) ([]byte, error) {
+	if value == "" {
+		return data, nil
+	}
+	var first, last int
+	if _, err := fmt.Sscanf(value, "bytes=%d-%d", &first, &last); err != nil || first > last || last >= len(data) {
+		return nil, newError(http.StatusRequestedRangeNotSatisfiable, "InvalidRange", "The requested range is not satisfiable.")
+	}
+	return data[first : last+1], nil
+}
+
+func (h *Handler) completeUpload(r *request) error {
+	u, err := h.upload(r)
+	if err != nil {
+		return err
+	}
+	var list oss.CompleteMultipartUpload
+	if err := r.decodeXML(&list); err != nil {
+		return err
+	}
+	if len(list.Part) == 0 {
+		return newError(http.StatusBadRequest, "MalformedXML", "The XML you provided was not well-formed.")
+	}
+	var data bytes.Buffer
+	sums := md5.New()
+	for i, listed := range list.Part {
+		if i > 0 && listed.PartNumber <= list.Part[i-1].PartNumber {
+			return newError(http.StatusBadRequest, "InvalidPartOrder", "The list of parts was not in ascending order.")
+		}
+		p, ok := u.parts[listed.PartNumber]
+		if !ok || !strings.EqualFold(strings.Trim(listed.ETag, `"`), strings.Trim(p.etag, `"`)) {
+			return newError(http.StatusBadRequest, "InvalidPart", "One or more of the specified parts could not be found.")
+		}
+		if i < len(list.Part)-1 && len(p.data) < minPartSize {
+			return newError(http.StatusBadRequest, "EntityTooSmall", "Your proposed upload is smaller than the minimum allowed size.")
+		}
+		sum := md5.Sum(p.data)
+		sums.Write(sum[:])
+		data.Write(p.data)
+	}
+	obj := h.newObject(u.key, data.Bytes(), u.header)
+	obj.ETag = fmt.Sprintf(`"%X-%d"`, sums.Sum(nil), len(list.Part))
+	if err := h.storage.PutObject(u.bucket, obj); err != nil {
+		return err
+	}
+	delete(h.uploads, u.id)
+	r.w.Header().Set("ETag", obj.ETag)
+	r.w.Header().Set("X-Oss-Hash-Crc64ecma", obj.Header.Get("X-Oss-Hash-Crc64ecma"))
+	return r.writeXML(&oss.CompleteMultipartUploadResult{
+		Location: "http://" + r.Host + "/" + u.bucket + "/" + u.key,
+		Bucket:   u.bucket,
+		Key:      u.key,
+		ETag:     obj.ETag,
+	})
+}
+
+func (h *Handler) abortUpload(r *request) error {
+	u, err := h.upload(r)
+	if err != nil {
+		return err
+	}
+	delete(h.uploads, u.id)
+	r.w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+// listUploads lists the uploads of a bucket sorted by key and initiation time
+func (h *Handler) listUploads(r *request) error {
+	if _, err := h.storage.GetBucket(r.bucket); err != nil {
+		return err
+	}
+	prefix := r.URL.Query().Get("prefix")
+	res := &oss.ListMultipartUploadsResult{Bucket: r.bucket, Prefix: prefix, MaxUploads: 1000}
+	for _, u := range h.uploads {
+		if u.bucket == r.bucket && strings.HasPrefix(u.key, prefix) {
+			res.Upload = append(res.Upload, oss.Upload{Key: u.key, UploadID: u.id, Initiated: u.initiated})
+		}
+	}
+	sort.Slice(res.Upload, func(i, j int) bool {
+		a, b := res.Upload[i], res.Upload[j]
+		if a.Key != b.Key {
+			return a.Key < b.Key
+		}
+		return a.Initiated.Before(b.Initiated) || a.Initiated.Equal(b.Initiated) && a.UploadID < b.UploadID
+	})
+	return r.writeXML(res)
+}
+
+// listParts lists the parts of an upload sorted by part number
+func (h *Handler) listParts(r *request) error {
+	u, err := h.upload(r)
+	if err != nil {
+		return err
+	}
+	res := &oss.ListPartsResult{Bucket: u.bucket, Key: u.key, UploadID: u.id, MaxParts: 1000}
+	for number, p := range u.parts {
+		modified := p.modified
+		res.Part = append(res.Part, oss.Part{PartNumber: number, LastModified: &modified, ETag: p.etag, Size: int64(len(p.data))})
+	}
+	sort.Slice(res.Part, func(i, j int) bool { return res.Part[i].PartNumber < res.Part[j].PartNumber })
+	return r.writeXML(res)
+}
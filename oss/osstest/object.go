@@ -0,0 +1,400 @@
+package osstest
+
+import (
+	"bytes"
+	"crypto/md5"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/aliyun/aliyun-oss-go-sdk/oss"
+)
+
+// storedHeaders are the request headers saved with an object besides
+// X-Oss-Meta-*
+var storedHeaders = []string{
+	"Cache-Control",
+	"Content-Disposition",
+	"Content-Encoding",
+	"Content-Language",
+	"Content-Type",
+	"Expires",
+	"X-Oss-Object-Acl",
+	"X-Oss-Server-Side-Encryption",
+	"X-Oss-Storage-Class",
+}
+
+func (h *Handler) routeObject(r *request) error {
+	switch r.Method {
+	case "PUT":
+		switch {
+		case r.has("acl"):
+			return h.putObjectACL(r)
+		case r.has("symlink"):
+			return h.putSymlink(r)
+		case r.has("uploadId"):
+			return h.uploadPart(r)
+		case r.Header.Get("X-Oss-Copy-Source") != "":
+			return h.copyObject(r)
+		}
+		return h.putObject(r)
+	case "GET", "HEAD":
+		switch {
+		case r.has("acl"):
+			return h.getObjectACL(r)
+		case r.has("symlink"):
+			return h.getSymlink(r)
+		case r.has("uploadId"):
+			return h.listParts(r)
+		}
+		return h.getObject(r)
+	case "POST":
+		switch {
+		case r.has("append"):
+			return h.appendObject(r)
+		case r.has("restore"):
+			return h.restoreObject(r)
+		case r.has("uploads"):
+			return h.initUpload(r)
+		case r.has("uploadId"):
+			return h.completeUpload(r)
+		}
+	case "DELETE":
+		if r.has("uploadId") {
+			return h.abortUpload(r)
+		}
+		return h.deleteObject(r)
+	case "OPTIONS":
+		return h.optionObject(r)
+	}
+	return errNotImplemented
+}
+
+// objectHeader returns the metadata of an object from request headers
+func objectHeader(src http.Header, objectType oss.ObjectType) http.Header {
+	header := make(http.Header)
+	for name, values := range src {
+		if strings.HasPrefix(name, "X-Oss-Meta-") {
+			header[name] = values
+		}
+	}
+	for _, name := range storedHeaders {
+		if value := src.Get(name); value != "" {
+			header.Set(name, value)
+		}
+	}
+	if header.Get("Content-Type") == "" {
+		header.Set("Content-Type", "application/octet-stream")
+	}
+	if header.Get("X-Oss-Storage-Class") == "" {
+		header.Set("X-Oss-Storage-Class", string(oss.StandardStorage))
+	}
+	header.Set("X-Oss-Object-Type", string(objectType))
+	return header
+}
+
+// newObject creates an object, its ETag is the MD5 of data
+func (h *Handler) newObject(key string, data []byte, header http.Header) *Object {
+	obj := &Object{
+		Key:     key,
+		Size:    int64(len(data)),
+		ETag:    fmt.Sprintf(`"%X"`, md5.Sum(data)),
+		ModTime: h.now(),
+		Header:  header,
+		Data:    data,
+	}
+	header.Set("X-Oss-Hash-Crc64ecma", strconv.FormatUint(oss.CRC64(data), 10))
+	return obj
+}
+
+// writeObjectResult writes the ETag and CRC-64 of a written object
+func (r *request) writeObjectResult(obj *Object) {
+	r.w.Header().Set("ETag", obj.ETag)
+	r.w.Header().Set("X-Oss-Hash-Crc64ecma", obj.Header.Get("X-Oss-Hash-Crc64ecma"))
+	r.w.WriteHeader(http.StatusOK)
+}
+
+func (h *Handler) putObject(r *request) error {
+	if _, err := h.storage.GetBucket(r.bucket); err != nil {
+		return err
+	}
+	obj := h.newObject(r.key, r.body, objectHeader(r.Header, oss.NormalObject))
+	if err := h.storage.PutObject(r.bucket, obj); err != nil {
+		return err
+	}
+	r.writeObjectResult(obj)
+	return nil
+}
+
+// copySource returns the source object of X-Oss-Copy-Source after checking
+// the X-Oss-Copy-Source-If-* conditions
+func (h *Handler) copySource(r *request) (*Object, error) {
+	source := r.Header.Get("X-Oss-Copy-Source")
+	if unescaped, err := url.PathUnescape(source); err == nil {
+		source = unescaped
+	}
+	parts := strings.SplitN(strings.TrimPrefix(source, "/"), "/", 2)
+	if len(parts) != 2 || parts[1] == "" {
+		return nil, newError(http.StatusBadRequest, "InvalidArgument", "Copy Source must mention the source bucket and key.")
+	}
+	obj, err := h.storage.GetObject(parts[0], parts[1])
+	if err != nil {
+		return nil, err
+	}
+	if !checkConditions(r.Header, "X-Oss-Copy-Source-", obj) {
+		return nil, newError(http.StatusPreconditionFailed, "PreconditionFailed", "At least one of the pre-conditions you specified did not hold.")
+	}
+	return obj, nil
+}
+
+// checkConditions checks the If-Match, If-None-Match, If-Modified-Since and
+// If-Unmodified-Since headers with a prefix
+func checkConditions(header http.Header, prefix string, obj *Object) bool {
+	if value := header.Get(prefix + "If-Match"); value != "" && value != obj.ETag {
+		return false
+	}
+	if value := header.Get(prefix + "If-None-Match"); value != "" && value == obj.ETag {
+		return false
+	}
+	if t, err := http.ParseTime(header.Get(prefix + "If-Modified-Since")); err == nil && !obj.ModTime.After(t) {
+		return false
+	}
+	if t, err := http.ParseTime(header.Get(prefix + "If-Unmodified-Since")); err == nil && obj.ModTime.After(t) {
+		return false
+	}
+	return true
+}
+
+func (h *Handler) copyObject(r *request) error {
+	source, err := h.copySource(r)
+	if err != nil {
+		return err
+	}
+	if _, err := h.storage.GetBucket(r.bucket); err != nil {
+		return err
+	}
+	header := source.Header.Clone()
+	if oss.MetadataDirectiveType(r.Header.Get("X-Oss-Metadata-Directive")) == oss.ReplaceMeta {
+		header = objectHeader(r.Header, oss.ObjectType(source.Header.Get("X-Oss-Object-Type")))
+	}
+	obj := h.newObject(r.key, source.Data, header)
+	if err := h.storage.PutObject(r.bucket, obj); err != nil {
+		return err
+	}
+	return r.writeXML(&oss.CopyObjectResult{LastModified: obj.ModTime.Format(isoTime), ETag: obj.ETag})
+}
+
+// isoTime is the time format in XML responses
+const isoTime = "2006-01-02T15:04:05.000Z"
+
+func (h *Handler) getObject(r *request) error {
+	obj, err := h.storage.GetObject(r.bucket, r.key)
+	if err != nil {
+		return err
+	}
+	if oss.ObjectType(obj.Header.Get("X-Oss-Object-Type")) == oss.SymlinkObject {
+		target, _ := url.QueryUnescape(obj.Header.Get("X-Oss-Symlink-Target"))
+		if obj, err = h.storage.GetObject(r.bucket, target); err == ErrNoSuchKey {
+			return newError(http.StatusNotFound, "SymlinkTargetNotExist", "The symlink target object does not exist.")
+		} else if err != nil {
+			return err
+		}
+	}
+	if err := h.restoreState(r.bucket, obj); err != nil {
+		return err
+	}
+	if r.Method == "GET" && !r.has("objectMeta") && !readable(obj) {
+		return newError(http.StatusForbidden, "InvalidObjectState", "The operation is not valid for the object's state.")
+	}
+	header := r.w.Header()
+	if r.has("objectMeta") {
+		header.Set("Content-Length", strconv.FormatInt(obj.Size, 10))
+		header.Set("X-Oss-Hash-Crc64ecma", obj.Header.Get("X-Oss-Hash-Crc64ecma"))
+	} else {
+		for name, values := range obj.Header {
+			if name != "X-Oss-Object-Acl" {
+				header[name] = values
+			}
+		}
+		if oss.ObjectType(obj.Header.Get("X-Oss-Object-Type")) == oss.AppendableObject {
+			header.Set("X-Oss-Next-Append-Position", strconv.FormatInt(obj.Size, 10))
+		}
+	}
+	header.Set("ETag", obj.ETag)
+	http.ServeContent(r.w, r.Request, "", obj.ModTime, bytes.NewReader(obj.Data))
+	return nil
+}
+
+func (h *Handler) deleteObject(r *request) error {
+	if err := h.storage.DeleteObject(r.bucket, r.key); err != nil {
+		return err
+	}
+	r.w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+func (h *Handler) putObjectACL(r *request) error {
+	obj, err := h.storage.GetObject(r.bucket, r.key)
+	if err != nil {
+		return err
+	}
+	acl := oss.ACLType(r.Header.Get("X-Oss-Object-Acl"))
+	if acl == "" {
+		acl = oss.ACLType(r.Header.Get("X-Oss-Acl"))
+	}
+	if !validACL(acl) && acl != "default" {
+		return newError(http.StatusBadRequest, "InvalidArgument", "no such object access control exists")
+	}
+	obj.Header.Set("X-Oss-Object-Acl", string(acl))
+	return h.storage.PutObject(r.bucket, obj)
+}
+
+func (h *Handler) getObjectACL(r *request) error {
+	obj, err := h.storage.GetObject(r.bucket, r.key)
+	if err != nil {
+		return err
+	}
+	acl := obj.Header.Get("X-Oss-Object-Acl")
+	if acl == "" {
+		acl = "default"
+	}
+	return r.writeXML(&oss.AccessControlPolicy{Owner: h.owner(), AccessControlList: oss.AccessControlList{Grant: acl}})
+}
+
+func (h *Handler) putSymlink(r *request) error {
+	if _, err := h.storage.GetBucket(r.bucket); err != nil {
+		return err
+	}
+	target := r.Header.Get("X-Oss-Symlink-Target")
+	if target == "" {
+		return newError(http.StatusBadRequest, "InvalidArgument", "x-oss-symlink-target is required.")
+	}
+	header := objectHeader(r.Header, oss.SymlinkObject)
+	header.Set("X-Oss-Symlink-Target", target)
+	obj := h.newObject(r.key, nil, header)
+	if err := h.storage.PutObject(r.bucket, obj); err != nil {
+		return err
+	}
+	r.writeObjectResult(obj)
+	return nil
+}
+
+func (h *Handler) getSymlink(r *request) error {
+	obj, err := h.storage.GetObject(r.bucket, r.key)
+	if err != nil {
+		return err
+	}
+	if oss.ObjectType(obj.Header.Get("X-Oss-Object-Type")) != oss.SymlinkObject {
+		return newError(http.StatusBadRequest, "NotSymlink", "The specified object is not a symlink.")
+	}
+	r.w.Header().Set("X-Oss-Symlink-Target", obj.Header.Get("X-Oss-Symlink-Target"))
+	r.w.Header().Set("ETag", obj.ETag)
+	r.w.Header().Set("Last-Modified", obj.ModTime.Format(http.TimeFormat))
+	r.w.WriteHeader(http.StatusOK)
+	return nil
+}
+
+func (h *Handler) appendObject(r *request) error {
+	if _, err := h.storage.GetBucket(r.bucket); err != nil {
+		return err
+	}
+	position, err := strconv.ParseInt(r.URL.Query().Get("position"), 10, 64)
+	if err != nil || position < 0 {
+		return newError(http.StatusBadRequest, "InvalidArgument", "position is invalid.")
+	}
+	var data []byte
+	header := objectHeader(r.Header, oss.AppendableObject)
+	obj, err := h.storage.GetObject(r.bucket, r.key)
+	switch {
+	case err == ErrNoSuchKey:
+	case err != nil:
+		return err
+	case oss.ObjectType(obj.Header.Get("X-Oss-Object-Type")) != oss.AppendableObject:
+		return newError(http.StatusConflict, "ObjectNotAppendable", "The object is not appendable.")
+	default:
+		data, header = obj.Data, obj.Header
+	}
+	if position != int64(len(data)) {
+		r.w.Header().Set("X-Oss-Next-Append-Position", strconv.Itoa(len(data)))
+		return newError(http.StatusConflict, "PositionNotEqualToLength", "Position is not equal to file length.")
+	}
+	obj = h.newObject(r.key, append(data, r.body...), header)
+	if err := h.storage.PutObject(r.bucket, obj); err != nil {
+		return err
+	}
+	r.w.Header().Set("X-Oss-Next-Append-Position", strconv.FormatInt(obj.Size, 10))
+	r.writeObjectResult(obj)
+	return nil
+}
+
+// optionObject answers a CORS preflight request with the first matching
+// rule of the bucket CORS configuration
+func (h *Handler) optionObject(r *request) error {
+	forbidden := newError(http.StatusForbidden, "AccessForbidden", "CORSResponse: This CORS request is not allowed.")
+	bucket, err := h.storage.GetBucket(r.bucket)
+	if err != nil {
+		return err
+	}
+	config := new(oss.CORSConfiguration)
+	data, ok := bucket.Configs["cors"]
+	if !ok {
+		return forbidden
+	}
+	if err := xml.Unmarshal(data, config); err != nil {
+		return err
+	}
+	origin, method := r.Header.Get("Origin"), r.Header.Get("Access-Control-Request-Method")
+	var headers []string
+	if value := r.Header.Get("Access-Control-Request-Headers"); value != "" {
+		for _, header := range strings.Split(value, ",") {
+			headers = append(headers, strings.TrimSpace(header))
+		}
+	}
+	for _, rule := range config.CORSRule {
+		if !matchAny(rule.AllowedOrigin, origin) || !matchAny(rule.AllowedMethod, method) {
+			continue
+		}
+		allowed := true
+		for _, header := range headers {
+			allowed = allowed && matchAny(rule.AllowedHeader, strings.ToLower(header))
+		}
+		if !allowed {
+			continue
+		}
+		w := r.w.Header()
+		w.Set("Access-Control-Allow-Origin", origin)
+		w.Set("Access-Control-Allow-Methods", strings.Join(rule.AllowedMethod, ", "))
+		if len(headers) > 0 {
+			w.Set("Access-Control-Allow-Headers", strings.Join(headers, ", "))
+		}
+		if len(rule.ExposeHeader) > 0 {
+			w.Set("Access-Control-Expose-Headers", strings.Join(rule.ExposeHeader, ", "))
+		}
+		if rule.MaxAgeSeconds > 0 {
+			w.Set("Access-Control-Max-Age", strconv.Itoa(rule.MaxAgeSeconds))
+		}
+		r.w.WriteHeader(http.StatusOK)
+		return nil
+	}
+	return forbidden
+}
+
+// matchAny matches a value against patterns containing at most one "*",
+// case-insensitively
+func matchAny(patterns []string, value string) bool {
+	value = strings.ToLower(value)
+	for _, pattern := range patterns {
+		pattern = strings.ToLower(pattern)
+		if i := strings.Index(pattern, "*"); i >= 0 {
+			if len(value) >= len(pattern)-1 && strings.HasPrefix(value, pattern[:i]) && strings.HasSuffix(value, pattern[i+1:]) {
+				return true
+			}
+		} else if pattern == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,74 @@
+package osstest
+
+import (
+	"bytes"
+	"io/ioutil"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"net/url"
+	"strconv"
+
+	"github.com/aliyun/aliyun-oss-go-sdk/oss"
+)
+
+// postObject uploads the file of a multipart form, the other form fields
+// are the key, the credentials and the object headers
+func (h *Handler) postObject(r *request) error {
+	_, params, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil {
+		return newError(http.StatusBadRequest, "InvalidArgument", "Content-Type is invalid.")
+	}
+	fields := make(http.Header)
+	var data []byte
+	mr := multipart.NewReader(bytes.NewReader(r.body), params["boundary"])
+	for {
+		p, err := mr.NextPart()
+		if err != nil {
+			break
+		}
+		value, err := ioutil.ReadAll(p)
+		if err != nil {
+			return newError(http.StatusBadRequest, "MalformedPOSTRequest", "The body of your POST request is not well-formed multipart/form-data.")
+		}
+		if p.FormName() == "file" {
+			data = value
+			break
+		}
+		fields.Add(textproto.CanonicalMIMEHeaderKey(p.FormName()), string(value))
+	}
+	key := fields.Get("Key")
+	if key == "" || data == nil {
+		return newError(http.StatusBadRequest, "InvalidArgument", "The key and file form fields are required.")
+	}
+	if id := fields.Get("Ossaccesskeyid"); id != "" {
+		secret, ok := h.credentials[id]
+		if !ok {
+			return newError(http.StatusForbidden, "InvalidAccessKeyId", "The OSS Access Key Id you provided does not exist in our records.")
+		}
+		if fields.Get("Signature") != postSignature(fields.Get("Policy"), secret) {
+			return newError(http.StatusForbidden, "SignatureDoesNotMatch", "The request signature we calculated does not match the signature you provided.")
+		}
+	} else if bucket, err := h.storage.GetBucket(r.bucket); err != nil {
+		return err
+	} else if bucket.ACL != oss.PublicReadWriteACL {
+		return newError(http.StatusForbidden, "AccessDenied", "You have no right to access this object.")
+	}
+	obj := h.newObject(key, data, objectHeader(fields, oss.NormalObject))
+	if err := h.storage.PutObject(r.bucket, obj); err != nil {
+		return err
+	}
+	status := http.StatusNoContent
+	if value, err := strconv.Atoi(fields.Get("Success_action_status")); err == nil && (value == http.StatusOK || value == http.StatusCreated) {
+		status = value
+	}
+	if redirect := fields.Get("Success_action_redirect"); redirect != "" {
+		http.Redirect(r.w, r.Request, redirect+"?"+url.Values{"bucket": {r.bucket}, "key": {key}, "etag": {obj.ETag}}.Encode(), http.StatusSeeOther)
+		return nil
+	}
+	r.w.Header().Set("ETag", obj.ETag)
+	r.w.Header().Set("X-Oss-Hash-Crc64ecma", obj.Header.Get("X-Oss-Hash-Crc64ecma"))
+	r.w.WriteHeader(status)
+	return nil
+}
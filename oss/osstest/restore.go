@@ -0,0 +1,90 @@
+package osstest
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/aliyun/aliyun-oss-go-sdk/oss"
+)
+
+// restore is an ongoing restore of an archived object, kept in memory
+type restore struct {
+	etag string
+	done time.Time
+	days int
+}
+
+// Values of the X-Oss-Restore header
+const (
+	restoreOngoing  = `ongoing-request="true"`
+	restoreFinished = `ongoing-request="false", expiry-date="%s"`
+)
+
+func isArchive(obj *Object) bool {
+	class := oss.StorageClassType(obj.Header.Get("X-Oss-Storage-Class"))
+	return class == oss.ArchiveStorage || class == oss.ColdArchiveStorage
+}
+
+// restoreState finishes the ongoing restore of an object when its time has
+// come, an object overwritten since the restore started is left as it is
+func (h *Handler) restoreState(bucket string, obj *Object) error {
+	name := bucket + "/" + obj.Key
+	rs, ok := h.restores[name]
+	if !ok {
+		return nil
+	}
+	if rs.etag != obj.ETag || obj.Header.Get("X-Oss-Restore") != restoreOngoing {
+		delete(h.restores, name)
+		return nil
+	}
+	if h.now().Before(rs.done) {
+		return nil
+	}
+	delete(h.restores, name)
+	expiry := rs.done.Add(time.Duration(rs.days) * 24 * time.Hour)
+	obj.Header.Set("X-Oss-Restore", fmt.Sprintf(restoreFinished, expiry.Format(http.TimeFormat)))
+	return h.storage.PutObject(bucket, obj)
+}
+
+// readable reports whether the data of an object can be read, an archived
+// object must be restored first
+func readable(obj *Object) bool {
+	if !isArchive(obj) {
+		return true
+	}
+	status, err := oss.ParseRestoreStatus(obj.Header.Get("X-Oss-Restore"))
+	return err == nil && !status.Ongoing
+}
+
+func (h *Handler) restoreObject(r *request) error {
+	obj, err := h.storage.GetObject(r.bucket, r.key)
+	if err != nil {
+		return err
+	}
+	if !isArchive(obj) {
+		return newError(http.StatusBadRequest, "OperationNotSupported", "The operation is not supported for this resource.")
+	}
+	if err := h.restoreState(r.bucket, obj); err != nil {
+		return err
+	}
+	if obj.Header.Get("X-Oss-Restore") == restoreOngoing {
+		return newError(http.StatusConflict, "RestoreAlreadyInProgress", "The restore operation is in progress.")
+	}
+	request := oss.RestoreRequest{Days: 1}
+	if len(r.body) > 0 {
+		if err := r.decodeXML(&request); err != nil {
+			return err
+		}
+	}
+	if request.Days < 1 {
+		request.Days = 1
+	}
+	obj.Header.Set("X-Oss-Restore", restoreOngoing)
+	if err := h.storage.PutObject(r.bucket, obj); err != nil {
+		return err
+	}
+	h.restores[r.bucket+"/"+r.key] = &restore{etag: obj.ETag, done: h.now().Add(h.RestoreTime), days: request.Days}
+	r.w.WriteHeader(http.StatusAccepted)
+	return nil
+}
@@ -0,0 +1,347 @@
+// Package osstest provides an in-process fake OSS server for testing code
+// using the oss package without network access or an OSS account.
+//
+// The Handler emulates the REST API the oss package speaks: buckets and
+// their ACL, CORS, lifecycle, website, referer and logging configurations,
+// objects, ACL, symbolic links, append, copy, delete-multiple, multipart
+// upload, the restore of archived objects and PostObject. Requests must be
+// path-style ("/bucket/object"), which is what oss.API sends to an IP or
+// localhost endpoint, and their signatures are verified.
+package osstest
+
+import (
+	"crypto/md5"
+	"encoding/base64"
+	"encoding/xml"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aliyun/aliyun-oss-go-sdk/oss"
+	"github.com/aliyun/aliyun-oss-go-sdk/oss/internal/sign"
+)
+
+// Credentials of a Server
+const (
+	DefaultAccessKeyID     = "osstest-access-key-id"
+	DefaultAccessKeySecret = "osstest-access-key-secret"
+)
+
+type (
+	// Handler is an http.Handler emulating OSS
+	Handler struct {
+		storage     Storage
+		credentials map[string]string
+		// Now returns the current time, it can be replaced in tests
+		Now func() time.Time
+		// RestoreTime is how long restoring an archived object takes, 0
+		// finishes the restore at the next request
+		RestoreTime time.Duration
+
+		mu        sync.Mutex
+		uploads   map[string]*upload
+		restores  map[string]*restore
+		requestID int
+	}
+
+	// Server is an httptest.Server running a Handler
+	Server struct {
+		*httptest.Server
+		Handler         *Handler
+		AccessKeyID     string
+		AccessKeySecret string
+	}
+
+	// request is a request being handled
+	request struct {
+		*http.Request
+		w      http.ResponseWriter
+		bucket string
+		key    string
+		body   []byte
+	}
+
+	// apiError is written as the XML error of OSS
+	apiError struct {
+		status  int
+		code    string
+		message string
+	}
+
+	errorResponse struct {
+		XMLName   xml.Name `xml:"Error"`
+		Code      string
+		Message   string
+		RequestID string `xml:"RequestId"`
+		HostID    string `xml:"HostId"`
+	}
+)
+
+// NewHandler creates a Handler with a Storage and credentials mapping
+// AccessKeyIds to AccessKeySecrets
+func NewHandler(storage Storage, credentials map[string]string) *Handler {
+	return &Handler{
+		storage:     storage,
+		credentials: credentials,
+		Now:         time.Now,
+		uploads:     make(map[string]*upload),
+		restores:    make(map[string]*restore),
+	}
+}
+
+// NewServer starts a Server with the default credentials, a nil storage is
+// replaced by a MemoryStorage. The caller should call Close when finished.
+func NewServer(storage Storage) *Server {
+	if storage == nil {
+		storage = NewMemoryStorage()
+	}
+	s := &Server{
+		Handler:         NewHandler(storage, map[string]string{DefaultAccessKeyID: DefaultAccessKeySecret}),
+		AccessKeyID:     DefaultAccessKeyID,
+		AccessKeySecret: DefaultAccessKeySecret,
+	}
+	s.Server = httptest.NewServer(s.Handler)
+	return s
+}
+
+// API returns an oss.API sending requests to the Server
+func (s *Server) API(options ...oss.APIOption) *oss.API {
+	return oss.New(strings.TrimPrefix(s.URL, "http://"), s.AccessKeyID, s.AccessKeySecret, options...)
+}
+
+func (e *apiError) Error() string {
+	return e.code + ": " + e.message
+}
+
+// now returns the current time in the precision of the Last-Modified header
+func (h *Handler) now() time.Time {
+	return h.Now().UTC().Truncate(time.Second)
+}
+
+func newError(status int, code, message string) *apiError {
+	return &apiError{status: status, code: code, message: message}
+}
+
+// storageError converts the errors of a Storage
+func storageError(err error) error {
+	switch err {
+	case ErrNoSuchBucket:
+		return newError(http.StatusNotFound, "NoSuchBucket", "The specified bucket does not exist.")
+	case ErrNoSuchKey:
+		return newError(http.StatusNotFound, "NoSuchKey", "The specified key does not exist.")
+	case ErrBucketExists:
+		return newError(http.StatusConflict, "BucketAlreadyExists", "The requested bucket name is not available.")
+	}
+	return err
+}
+
+// ServeHTTP implements http.Handler
+func (h *Handler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	r := &request{Request: req, w: w}
+	parts := strings.SplitN(strings.TrimPrefix(req.URL.Path, "/"), "/", 2)
+	r.bucket = parts[0]
+	if len(parts) == 2 {
+		r.key = parts[1]
+	}
+	// the body is read before locking, so that a slow upload does not block
+	// the other requests
+	bodyErr := r.readBody()
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.requestID++
+	requestID := fmt.Sprintf("%024X", h.requestID)
+	w.Header().Set("X-Oss-Request-Id", requestID)
+	w.Header().Set("Server", "AliyunOSS")
+	w.Header().Set("Date", h.Now().UTC().Format(http.TimeFormat))
+	var err error
+	if r.bucket != "" && !rxBucketName.MatchString(r.bucket) {
+		// the name is checked before it reaches the storage, e.g. ".."
+		err = newError(http.StatusBadRequest, "InvalidBucketName", "The specified bucket is not valid.")
+	}
+	if err == nil {
+		err = h.authenticate(r)
+	}
+	if err == nil {
+		err = bodyErr
+	}
+	if err == nil {
+		err = h.route(r)
+	}
+	if err == nil {
+		return
+	}
+	e, ok := storageError(err).(*apiError)
+	if !ok {
+		e = newError(http.StatusInternalServerError, "InternalError", err.Error())
+	}
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(e.status)
+	if req.Method != "HEAD" {
+		xml.NewEncoder(w).Encode(&errorResponse{Code: e.code, Message: e.message, RequestID: requestID, HostID: req.Host})
+	}
+}
+
+// authenticate verifies the signature in the Authorization header, an
+// anonymous request is checked against the bucket and object ACL
+func (h *Handler) authenticate(r *request) error {
+	if r.URL.Query().Get("Signature") != "" {
+		return h.authenticateURL(r)
+	}
+	auth := r.Header.Get("Authorization")
+	if auth == "" {
+		if r.Method == "OPTIONS" || r.isPostObject() || h.allowAnonymous(r) {
+			return nil
+		}
+		return newError(http.StatusForbidden, "AccessDenied", "You have no right to access this object.")
+	}
+	idSignature := strings.SplitN(strings.TrimPrefix(auth, "OSS "), ":", 2)
+	if !strings.HasPrefix(auth, "OSS ") || len(idSignature) != 2 {
+		return newError(http.StatusBadRequest, "InvalidArgument", "Authorization header is invalid.")
+	}
+	secret, ok := h.credentials[idSignature[0]]
+	if !ok {
+		return newError(http.StatusForbidden, "InvalidAccessKeyId", "The OSS Access Key Id you provided does not exist in our records.")
+	}
+	signed := r.Request
+	if r.bucket == "" {
+		// GetService is signed without a path
+		signed = r.Request.Clone(r.Context())
+		signed.URL.Path = ""
+	}
+	if sign.Signature(signed, r.bucket, []byte(secret)) != idSignature[1] {
+		return newError(http.StatusForbidden, "SignatureDoesNotMatch", "The request signature we calculated does not match the signature you provided.")
+	}
+	return nil
+}
+
+// authenticateURL verifies the signature of a URL signed by oss.API.SignURL
+func (h *Handler) authenticateURL(r *request) error {
+	q := r.URL.Query()
+	secret, ok := h.credentials[q.Get("OSSAccessKeyId")]
+	if !ok {
+		return newError(http.StatusForbidden, "InvalidAccessKeyId", "The OSS Access Key Id you provided does not exist in our records.")
+	}
+	expires, err := strconv.ParseInt(q.Get("Expires"), 10, 64)
+	if err != nil {
+		return newError(http.StatusForbidden, "AccessDenied", "Expires is invalid.")
+	}
+	if h.now().Unix() > expires {
+		return newError(http.StatusForbidden, "AccessDenied", "Request has expired.")
+	}
+	signed := r.Request.Clone(r.Context())
+	var params []string
+	for _, param := range strings.Split(r.URL.RawQuery, "&") {
+		key := strings.SplitN(param, "=", 2)[0]
+		switch key {
+		case "OSSAccessKeyId", "Expires", "Signature":
+		case "security-token":
+			// the token is signed unescaped
+			params = append(params, key+"="+q.Get(key))
+		default:
+			params = append(params, param)
+		}
+	}
+	signed.URL.RawQuery = strings.Join(params, "&")
+	signed.Header.Set("Date", q.Get("Expires"))
+	if sign.Signature(signed, r.bucket, []byte(secret)) != q.Get("Signature") {
+		return newError(http.StatusForbidden, "SignatureDoesNotMatch", "The request signature we calculated does not match the signature you provided.")
+	}
+	return nil
+}
+
+func (h *Handler) allowAnonymous(r *request) bool {
+	if r.bucket == "" {
+		return false
+	}
+	bucket, err := h.storage.GetBucket(r.bucket)
+	if err != nil {
+		return false
+	}
+	acl := bucket.ACL
+	if r.key != "" {
+		if obj, err := h.storage.GetObject(r.bucket, r.key); err == nil {
+			if objectACL := oss.ACLType(obj.Header.Get("X-Oss-Object-Acl")); objectACL != "" && objectACL != "default" {
+				acl = objectACL
+			}
+		}
+	}
+	switch acl {
+	case oss.PublicReadWriteACL:
+		return r.key != "" || r.Method == "GET" || r.Method == "HEAD"
+	case oss.PublicReadACL:
+		return r.Method == "GET" || r.Method == "HEAD"
+	}
+	return false
+}
+
+// postSignature is the signature of PostObject
+func postSignature(policy, secret string) string {
+	return sign.HMACSHA1([]byte(policy), []byte(secret))
+}
+
+func (r *request) has(subresource string) bool {
+	_, ok := r.URL.Query()[subresource]
+	return ok
+}
+
+func (r *request) isPostObject() bool {
+	return r.Method == "POST" && r.key == "" && strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data")
+}
+
+// route dispatches a request by its method and subresource
+func (h *Handler) route(r *request) error {
+	switch {
+	case r.bucket == "":
+		if r.Method == "GET" {
+			return h.listBuckets(r)
+		}
+	case r.key == "":
+		return h.routeBucket(r)
+	default:
+		return h.routeObject(r)
+	}
+	return errNotImplemented
+}
+
+// rxBucketName is the bucket name rule of the oss package
+var rxBucketName = regexp.MustCompile(`\A[a-z0-9][a-z0-9\-]{2,62}\z`)
+
+var errNotImplemented = newError(http.StatusNotImplemented, "NotImplemented", "The operation is not supported by osstest.")
+
+// writeXML writes a 200 response with an XML body
+func (r *request) writeXML(v interface{}) error {
+	r.w.Header().Set("Content-Type", "application/xml")
+	r.w.WriteHeader(http.StatusOK)
+	fmt.Fprint(r.w, xml.Header)
+	return xml.NewEncoder(r.w).Encode(v)
+}
+
+// readBody reads the request body and verifies its Content-Md5
+func (r *request) readBody() error {
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return err
+	}
+	r.body = body
+	if value := r.Header.Get("Content-Md5"); value != "" {
+		sum := md5.Sum(body)
+		if value != base64.StdEncoding.EncodeToString(sum[:]) {
+			return newError(http.StatusBadRequest, "InvalidDigest", "The Content-MD5 you specified was invalid.")
+		}
+	}
+	return nil
+}
+
+// decodeXML decodes the request body
+func (r *request) decodeXML(v interface{}) error {
+	if err := xml.Unmarshal(r.body, v); err != nil {
+		return newError(http.StatusBadRequest, "MalformedXML", "The XML you provided was not well-formed: "+err.Error())
+	}
+	return nil
+}
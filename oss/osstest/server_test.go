@@ -0,0 +1,360 @@
+package osstest
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aliyun/aliyun-oss-go-sdk/oss"
+)
+
+const (
+	expectBut  = "\n--- EXPECT ---\n%v\n--- BUT GOT ---\n%v"
+	testBucket = "bucket"
+)
+
+func newTestServer(t *testing.T) (*Server, *oss.API) {
+	server := NewServer(nil)
+	api := server.API(oss.CRC64Check(true))
+	if err := api.PutBucket(testBucket, oss.PrivateACL); err != nil {
+		server.Close()
+		t.Fatal(err)
+	}
+	return server, api
+}
+
+func errorCode(err error) string {
+	if e, ok := err.(*oss.Error); ok {
+		return e.Code
+	}
+	return ""
+}
+
+func TestServerBucket(t *testing.T) {
+	server, api := newTestServer(t)
+	defer server.Close()
+	if err := api.PutBucket("other", oss.PublicReadACL); err != nil {
+		t.Fatal(err)
+	}
+	service, err := api.GetService()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(service.Buckets) != 2 || service.Buckets[0].Name != testBucket || service.Buckets[0].Location != DefaultLocation {
+		t.Fatalf(expectBut, "2 buckets", service.Buckets)
+	}
+	if acl, err := api.GetBucketACL("other"); err != nil || acl.AccessControlList.Grant != string(oss.PublicReadACL) {
+		t.Fatalf(expectBut, oss.PublicReadACL, []interface{}{acl, err})
+	}
+	if _, err := api.GetBucketCORS(testBucket); errorCode(err) != "NoSuchCORSConfiguration" {
+		t.Fatalf(expectBut, "NoSuchCORSConfiguration", err)
+	}
+	cors := &oss.CORSConfiguration{CORSRule: []oss.CORSRule{{
+		AllowedOrigin: []string{"http://*.example.com"},
+		AllowedMethod: []string{"GET", "PUT"},
+		AllowedHeader: []string{"*"},
+		MaxAgeSeconds: 100,
+	}}}
+	if err := api.PutBucketCORS(testBucket, cors); err != nil {
+		t.Fatal(err)
+	}
+	if actual, err := api.GetBucketCORS(testBucket); err != nil || !reflect.DeepEqual(actual.CORSRule, cors.CORSRule) {
+		t.Fatalf(expectBut, cors, []interface{}{actual, err})
+	}
+	if err := api.PutObject(testBucket, "a", strings.NewReader("a")); err != nil {
+		t.Fatal(err)
+	}
+	header, err := api.OptionObject(testBucket, "a", oss.Origin("http://www.example.com"), oss.AccessControlRequestMethod("PUT"))
+	if err != nil || http.Header(header).Get("Access-Control-Allow-Origin") != "http://www.example.com" {
+		t.Fatalf(expectBut, "CORS allowed", []interface{}{header, err})
+	}
+	if _, err := api.OptionObject(testBucket, "a", oss.Origin("http://evil.com"), oss.AccessControlRequestMethod("PUT")); errorCode(err) != "AccessForbidden" {
+		t.Fatalf(expectBut, "AccessForbidden", err)
+	}
+	if referer, err := api.GetBucketReferer(testBucket); err != nil || !referer.AllowEmptyReferer {
+		t.Fatalf(expectBut, "default referer configuration", []interface{}{referer, err})
+	}
+	if err := api.DeleteBucket(testBucket); errorCode(err) != "BucketNotEmpty" {
+		t.Fatalf(expectBut, "BucketNotEmpty", err)
+	}
+	if err := api.DeleteBucket("other"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := api.GetBucketACL("other"); errorCode(err) != "NoSuchBucket" {
+		t.Fatalf(expectBut, "NoSuchBucket", err)
+	}
+}
+
+func TestServerInvalidBucketName(t *testing.T) {
+	dir, err := ioutil.TempDir("", "osstest")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	storage, err := NewDirStorage(filepath.Join(dir, "root"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := NewServer(storage)
+	defer server.Close()
+	for _, method := range []string{"PUT", "DELETE", "GET"} {
+		for _, name := range []string{"..", ".", "Upper", "ab"} {
+			req, _ := http.NewRequest(method, server.URL+"/"+name, nil)
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			body, _ := ioutil.ReadAll(resp.Body)
+			resp.Body.Close()
+			if resp.StatusCode != http.StatusBadRequest || !strings.Contains(string(body), "<Code>InvalidBucketName</Code>") {
+				t.Fatalf(expectBut, "InvalidBucketName", []interface{}{method, name, resp.StatusCode, string(body)})
+			}
+		}
+	}
+	// nothing is written outside of the root
+	if files, err := ioutil.ReadDir(dir); err != nil || len(files) != 1 {
+		t.Fatalf(expectBut, "root only", []interface{}{files, err})
+	}
+}
+
+func TestServerObject(t *testing.T) {
+	server, api := newTestServer(t)
+	defer server.Close()
+	if err := api.PutObject(testBucket, "dir/a.txt", strings.NewReader("hello"), oss.ContentType("text/plain"), oss.Meta("color", "red")); err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	header, err := api.GetObject(testBucket, "dir/a.txt", &buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != "hello" || http.Header(header).Get("Content-Type") != "text/plain" || http.Header(header).Get("X-Oss-Meta-Color") != "red" {
+		t.Fatalf(expectBut, "hello", []interface{}{buf.String(), header})
+	}
+	buf.Reset()
+	if _, err := api.GetObject(testBucket, "dir/a.txt", &buf, oss.Range("bytes=1-3")); err != nil || buf.String() != "ell" {
+		t.Fatalf(expectBut, "ell", []interface{}{buf.String(), err})
+	}
+	if _, err := api.CopyObject(testBucket, "dir/a.txt", testBucket, "dir/b.txt"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := api.CopyObject(testBucket, "dir/a.txt", testBucket, "c.txt", oss.CopySourceIfMatch(`"other"`)); errorCode(err) != "PreconditionFailed" {
+		t.Fatalf(expectBut, "PreconditionFailed", err)
+	}
+	if err := api.PutSymlink(testBucket, "link", "dir/b.txt"); err != nil {
+		t.Fatal(err)
+	}
+	buf.Reset()
+	if _, err := api.GetObject(testBucket, "link", &buf); err != nil || buf.String() != "hello" {
+		t.Fatalf(expectBut, "hello", []interface{}{buf.String(), err})
+	}
+	if _, err := api.GetSymlink(testBucket, "dir/a.txt"); errorCode(err) != "NotSymlink" {
+		t.Fatalf(expectBut, "NotSymlink", err)
+	}
+	position, err := api.AppendObject(testBucket, "log", strings.NewReader("12"), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := api.AppendObject(testBucket, "log", strings.NewReader("34"), position); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := api.AppendObject(testBucket, "log", strings.NewReader("56"), 0); errorCode(err) != "PositionNotEqualToLength" {
+		t.Fatalf(expectBut, "PositionNotEqualToLength", err)
+	}
+	list, err := api.GetBucket(testBucket, oss.Delimiter("/"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var keys []string
+	for _, content := range list.Contents {
+		keys = append(keys, content.Key)
+	}
+	if !reflect.DeepEqual(keys, []string{"link", "log"}) || !reflect.DeepEqual(list.CommonPrefixes, []string{"dir/"}) {
+		t.Fatalf(expectBut, "link, log and dir/", list)
+	}
+	if _, err := api.DeleteObjects(testBucket, false, "dir/a.txt", "dir/b.txt"); err != nil {
+		t.Fatal(err)
+	}
+	// HEAD responses have no error body
+	if _, err := api.HeadObject(testBucket, "dir/a.txt"); err == nil || err.(*oss.Error).HTTPStatusCode != http.StatusNotFound {
+		t.Fatalf(expectBut, http.StatusNotFound, err)
+	}
+}
+
+func TestServerMultipart(t *testing.T) {
+	server, api := newTestServer(t)
+	defer server.Close()
+	data := bytes.Repeat([]byte("0123456789"), 20*1024)
+	if err := api.PutObject(testBucket, "source", bytes.NewReader(data[:minPartSize])); err != nil {
+		t.Fatal(err)
+	}
+	upload, err := api.InitUpload(testBucket, "object")
+	if err != nil {
+		t.Fatal(err)
+	}
+	copied, err := api.UploadPartCopy(testBucket, "object", upload.UploadID, 1, testBucket, "source")
+	if err != nil {
+		t.Fatal(err)
+	}
+	part, err := api.UploadPart(testBucket, "object", upload.UploadID, 2, bytes.NewReader(data[minPartSize:]), int64(len(data)-minPartSize))
+	if err != nil {
+		t.Fatal(err)
+	}
+	parts, err := api.ListParts(testBucket, "object", upload.UploadID)
+	if err != nil || len(parts.Part) != 2 || parts.Part[1].Size != int64(len(data)-minPartSize) {
+		t.Fatalf(expectBut, "2 parts", []interface{}{parts, err})
+	}
+	uploads, err := api.ListUploads(testBucket, "")
+	if err != nil || len(uploads.Upload) != 1 || uploads.Upload[0].UploadID != upload.UploadID {
+		t.Fatalf(expectBut, upload.UploadID, []interface{}{uploads, err})
+	}
+	list := &oss.CompleteMultipartUpload{Part: []oss.Part{{PartNumber: 2, ETag: part.ETag}, {PartNumber: 1, ETag: copied.ETag}}}
+	if _, err := api.CompleteUpload(testBucket, "object", upload.UploadID, list); errorCode(err) != "InvalidPartOrder" {
+		t.Fatalf(expectBut, "InvalidPartOrder", err)
+	}
+	list.Part[0], list.Part[1] = list.Part[1], list.Part[0]
+	list.Part[1].CRC64, list.Part[1].Size = part.CRC64, int64(len(data)-minPartSize)
+	copiedCRC := oss.CRC64(data[:minPartSize])
+	list.Part[0].CRC64, list.Part[0].Size = &copiedCRC, minPartSize
+	if _, err := api.CompleteUpload(testBucket, "object", upload.UploadID, list); err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	header, err := api.GetObject(testBucket, "object", &buf)
+	if err != nil || !bytes.Equal(buf.Bytes(), data) || oss.Header(header).ObjectType() != oss.MultipartObject {
+		t.Fatalf(expectBut, "the multipart object", []interface{}{header, err})
+	}
+	if err := api.AbortUpload(testBucket, "object", upload.UploadID); errorCode(err) != "NoSuchUpload" {
+		t.Fatalf(expectBut, "NoSuchUpload", err)
+	}
+}
+
+func TestServerRestore(t *testing.T) {
+	server, api := newTestServer(t)
+	defer server.Close()
+	now := time.Date(2017, 4, 14, 8, 12, 33, 0, time.UTC)
+	server.Handler.Now = func() time.Time { return now }
+	server.Handler.RestoreTime = time.Hour
+	if err := api.PutObject(testBucket, "cold", strings.NewReader("cold"), oss.StorageClass(oss.ColdArchiveStorage)); err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if _, err := api.GetObject(testBucket, "cold", &buf); errorCode(err) != "InvalidObjectState" {
+		t.Fatalf(expectBut, "InvalidObjectState", err)
+	}
+	request := &oss.RestoreRequest{Days: 2, JobParameters: &oss.JobParameters{Tier: oss.BulkTier}}
+	if err := api.RestoreObject(testBucket, "cold", request); err != nil {
+		t.Fatal(err)
+	}
+	if err := api.RestoreObject(testBucket, "cold", request); errorCode(err) != "RestoreAlreadyInProgress" {
+		t.Fatalf(expectBut, "RestoreAlreadyInProgress", err)
+	}
+	if header, err := api.HeadObject(testBucket, "cold"); err != nil || http.Header(header).Get("X-Oss-Restore") != `ongoing-request="true"` {
+		t.Fatalf(expectBut, "ongoing", []interface{}{header, err})
+	}
+	now = now.Add(time.Hour)
+	header, err := api.HeadObject(testBucket, "cold")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status, err := header.RestoreStatus(); err != nil || status.Ongoing || !status.ExpiryDate.Equal(now.AddDate(0, 0, 2)) {
+		t.Fatalf(expectBut, "restored for 2 days", []interface{}{status, err})
+	}
+	if _, err := api.GetObject(testBucket, "cold", &buf); err != nil || buf.String() != "cold" {
+		t.Fatalf(expectBut, "cold", []interface{}{buf.String(), err})
+	}
+	if err := api.PutObject(testBucket, "hot", strings.NewReader("hot")); err != nil {
+		t.Fatal(err)
+	}
+	if err := api.RestoreObject(testBucket, "hot", nil); errorCode(err) != "OperationNotSupported" {
+		t.Fatalf(expectBut, "OperationNotSupported", err)
+	}
+}
+
+func TestServerAuthentication(t *testing.T) {
+	server, api := newTestServer(t)
+	defer server.Close()
+	if err := api.PutObject(testBucket, "a", strings.NewReader("a")); err != nil {
+		t.Fatal(err)
+	}
+	bad := oss.New(strings.TrimPrefix(server.URL, "http://"), server.AccessKeyID, "wrong")
+	if _, err := bad.GetBucket(testBucket); errorCode(err) != "SignatureDoesNotMatch" {
+		t.Fatalf(expectBut, "SignatureDoesNotMatch", err)
+	}
+	resp, err := http.Get(server.URL + "/" + testBucket + "/a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusForbidden {
+		t.Fatalf(expectBut, http.StatusForbidden, resp.StatusCode)
+	}
+	if err := api.PutObjectACL(testBucket, "a", oss.PublicReadACL); err != nil {
+		t.Fatal(err)
+	}
+	resp, err = http.Get(server.URL + "/" + testBucket + "/a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || string(body) != "a" {
+		t.Fatalf(expectBut, "a", []interface{}{resp.StatusCode, string(body)})
+	}
+
+	dir, err := ioutil.TempDir("", "osstest")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	filename := filepath.Join(dir, "post.txt")
+	if err := ioutil.WriteFile(filename, []byte("posted"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := api.PostObject(testBucket, "posted", filename, `{"conditions":[]}`, oss.PostContentType("text/plain")); err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	header, err := api.GetObject(testBucket, "posted", &buf)
+	if err != nil || buf.String() != "posted" || http.Header(header).Get("Content-Type") != "text/plain" {
+		t.Fatalf(expectBut, "posted", []interface{}{buf.String(), header, err})
+	}
+}
+
+func TestServerSignURL(t *testing.T) {
+	server, api := newTestServer(t)
+	defer server.Close()
+	if err := api.PutObject(testBucket, "a b", strings.NewReader("signed")); err != nil {
+		t.Fatal(err)
+	}
+	for _, testcase := range []struct {
+		api     *oss.API
+		expires time.Time
+		status  int
+	}{
+		{api, time.Now().Add(time.Minute), http.StatusOK},
+		{api, time.Now().Add(-time.Minute), http.StatusForbidden},
+		{oss.New(strings.TrimPrefix(server.URL, "http://"), server.AccessKeyID, "wrong"), time.Now().Add(time.Minute), http.StatusForbidden},
+		{server.API(oss.SecurityToken("CAIS+token/with==")), time.Now().Add(time.Minute), http.StatusOK},
+	} {
+		signed, err := testcase.api.SignURL("GET", testBucket, "a b", testcase.expires, oss.Process("image/info"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp, err := http.Get(signed)
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, _ := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		if resp.StatusCode != testcase.status {
+			t.Fatalf(expectBut, testcase.status, []interface{}{resp.StatusCode, signed, string(body)})
+		}
+	}
+}
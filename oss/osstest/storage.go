@@ -0,0 +1,421 @@
+package osstest
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aliyun/aliyun-oss-go-sdk/oss"
+)
+
+// Errors returned by a Storage
+var (
+	ErrNoSuchBucket = errors.New("no such bucket")
+	ErrBucketExists = errors.New("bucket already exists")
+	ErrNoSuchKey    = errors.New("no such key")
+)
+
+type (
+	// Storage persists the buckets and objects of a Handler. The Handler
+	// serializes the calls, and the returned values are not modified by the
+	// Handler.
+	Storage interface {
+		ListBuckets() ([]*Bucket, error)
+		// GetBucket returns ErrNoSuchBucket if the bucket does not exist
+		GetBucket(name string) (*Bucket, error)
+		// CreateBucket returns ErrBucketExists if the bucket exists
+		CreateBucket(bucket *Bucket) error
+		// UpdateBucket saves the ACL and configurations of a bucket
+		UpdateBucket(bucket *Bucket) error
+		DeleteBucket(name string) error
+		// ListObjects returns the objects with a key prefix sorted by key,
+		// their Data is not loaded
+		ListObjects(bucket, prefix string) ([]*Object, error)
+		// GetObject returns ErrNoSuchKey if the object does not exist
+		GetObject(bucket, key string) (*Object, error)
+		PutObject(bucket string, object *Object) error
+		DeleteObject(bucket, key string) error
+	}
+
+	// Bucket is a bucket saved in a Storage
+	Bucket struct {
+		Name         string
+		Location     string
+		ACL          oss.ACLType
+		CreationDate time.Time
+		// Configs are the XML configurations of the bucket keyed by
+		// subresource, e.g. "cors" or "lifecycle"
+		Configs map[string][]byte
+	}
+
+	// Object is an object saved in a Storage
+	Object struct {
+		Key     string
+		Size    int64
+		ETag    string
+		ModTime time.Time
+		// Header is the metadata returned with the object, e.g.
+		// Content-Type, X-Oss-Meta-* and X-Oss-Object-Type
+		Header http.Header
+		Data   []byte `json:"-"`
+	}
+
+	// MemoryStorage is a Storage in memory
+	MemoryStorage struct {
+		mu      sync.Mutex
+		buckets map[string]*memoryBucket
+	}
+
+	memoryBucket struct {
+		*Bucket
+		objects map[string]*Object
+	}
+
+	// DirStorage is a Storage in a local directory. Each bucket is a
+	// subdirectory with a bucket.json file and an objects directory, where
+	// an object is saved as a data file and a JSON metadata file named by
+	// its escaped key.
+	DirStorage struct {
+		root string
+	}
+)
+
+// NewMemoryStorage creates an empty MemoryStorage
+func NewMemoryStorage() *MemoryStorage {
+	return &MemoryStorage{buckets: make(map[string]*memoryBucket)}
+}
+
+// ListBuckets implements Storage
+func (s *MemoryStorage) ListBuckets() ([]*Bucket, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var buckets []*Bucket
+	for _, b := range s.buckets {
+		buckets = append(buckets, b.Bucket.clone())
+	}
+	sort.Slice(buckets, func(i, j int) bool { return buckets[i].Name < buckets[j].Name })
+	return buckets, nil
+}
+
+// GetBucket implements Storage
+func (s *MemoryStorage) GetBucket(name string) (*Bucket, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	b, ok := s.buckets[name]
+	if !ok {
+		return nil, ErrNoSuchBucket
+	}
+	return b.Bucket.clone(), nil
+}
+
+// CreateBucket implements Storage
+func (s *MemoryStorage) CreateBucket(bucket *Bucket) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.buckets[bucket.Name]; ok {
+		return ErrBucketExists
+	}
+	s.buckets[bucket.Name] = &memoryBucket{Bucket: bucket.clone(), objects: make(map[string]*Object)}
+	return nil
+}
+
+// UpdateBucket implements Storage
+func (s *MemoryStorage) UpdateBucket(bucket *Bucket) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	b, ok := s.buckets[bucket.Name]
+	if !ok {
+		return ErrNoSuchBucket
+	}
+	b.Bucket = bucket.clone()
+	return nil
+}
+
+// DeleteBucket implements Storage
+func (s *MemoryStorage) DeleteBucket(name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.buckets[name]; !ok {
+		return ErrNoSuchBucket
+	}
+	delete(s.buckets, name)
+	return nil
+}
+
+// ListObjects implements Storage
+func (s *MemoryStorage) ListObjects(bucket, prefix string) ([]*Object, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	b, ok := s.buckets[bucket]
+	if !ok {
+		return nil, ErrNoSuchBucket
+	}
+	var objects []*Object
+	for key, obj := range b.objects {
+		if strings.HasPrefix(key, prefix) {
+			obj = obj.clone()
+			obj.Data = nil
+			objects = append(objects, obj)
+		}
+	}
+	sort.Slice(objects, func(i, j int) bool { return objects[i].Key < objects[j].Key })
+	return objects, nil
+}
+
+// GetObject implements Storage
+func (s *MemoryStorage) GetObject(bucket, key string) (*Object, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	b, ok := s.buckets[bucket]
+	if !ok {
+		return nil, ErrNoSuchBucket
+	}
+	obj, ok := b.objects[key]
+	if !ok {
+		return nil, ErrNoSuchKey
+	}
+	return obj.clone(), nil
+}
+
+// PutObject implements Storage
+func (s *MemoryStorage) PutObject(bucket string, object *Object) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	b, ok := s.buckets[bucket]
+	if !ok {
+		return ErrNoSuchBucket
+	}
+	b.objects[object.Key] = object.clone()
+	return nil
+}
+
+// DeleteObject implements Storage
+func (s *MemoryStorage) DeleteObject(bucket, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	b, ok := s.buckets[bucket]
+	if !ok {
+		return ErrNoSuchBucket
+	}
+	delete(b.objects, key)
+	return nil
+}
+
+func (b *Bucket) clone() *Bucket {
+	c := *b
+	c.Configs = make(map[string][]byte, len(b.Configs))
+	for k, v := range b.Configs {
+		c.Configs[k] = append([]byte(nil), v...)
+	}
+	return &c
+}
+
+func (o *Object) clone() *Object {
+	c := *o
+	c.Header = o.Header.Clone()
+	if o.Data != nil {
+		c.Data = append([]byte{}, o.Data...)
+	}
+	return &c
+}
+
+// NewDirStorage creates a DirStorage in root, which is created if it does
+// not exist
+func NewDirStorage(root string) (*DirStorage, error) {
+	if err := os.MkdirAll(root, 0755); err != nil {
+		return nil, err
+	}
+	return &DirStorage{root: root}, nil
+}
+
+// ListBuckets implements Storage
+func (s *DirStorage) ListBuckets() ([]*Bucket, error) {
+	dirs, err := ioutil.ReadDir(s.root)
+	if err != nil {
+		return nil, err
+	}
+	var buckets []*Bucket
+	for _, dir := range dirs {
+		if !dir.IsDir() {
+			continue
+		}
+		bucket, err := s.GetBucket(dir.Name())
+		if err == ErrNoSuchBucket {
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+		buckets = append(buckets, bucket)
+	}
+	return buckets, nil
+}
+
+// GetBucket implements Storage
+func (s *DirStorage) GetBucket(name string) (*Bucket, error) {
+	bucket := new(Bucket)
+	if err := readJSON(filepath.Join(s.root, name, "bucket.json"), bucket); os.IsNotExist(err) {
+		return nil, ErrNoSuchBucket
+	} else if err != nil {
+		return nil, err
+	}
+	return bucket, nil
+}
+
+// CreateBucket implements Storage
+func (s *DirStorage) CreateBucket(bucket *Bucket) error {
+	if _, err := s.GetBucket(bucket.Name); err == nil {
+		return ErrBucketExists
+	}
+	if err := os.MkdirAll(filepath.Join(s.root, bucket.Name, "objects"), 0755); err != nil {
+		return err
+	}
+	return writeJSON(filepath.Join(s.root, bucket.Name, "bucket.json"), bucket)
+}
+
+// UpdateBucket implements Storage
+func (s *DirStorage) UpdateBucket(bucket *Bucket) error {
+	if _, err := s.GetBucket(bucket.Name); err != nil {
+		return err
+	}
+	return writeJSON(filepath.Join(s.root, bucket.Name, "bucket.json"), bucket)
+}
+
+// DeleteBucket implements Storage
+func (s *DirStorage) DeleteBucket(name string) error {
+	if _, err := s.GetBucket(name); err != nil {
+		return err
+	}
+	return os.RemoveAll(filepath.Join(s.root, name))
+}
+
+// ListObjects implements Storage
+func (s *DirStorage) ListObjects(bucket, prefix string) ([]*Object, error) {
+	if _, err := s.GetBucket(bucket); err != nil {
+		return nil, err
+	}
+	files, err := ioutil.ReadDir(filepath.Join(s.root, bucket, "objects"))
+	if err != nil {
+		return nil, err
+	}
+	var objects []*Object
+	for _, file := range files {
+		name := file.Name()
+		if !strings.HasSuffix(name, ".json") {
+			continue
+		}
+		// the key of a hashed file name is only known from its metadata
+		if !strings.HasPrefix(name, hashedNamePrefix) {
+			key, err := url.QueryUnescape(strings.TrimSuffix(name, ".json"))
+			if err != nil || !strings.HasPrefix(key, prefix) {
+				continue
+			}
+		}
+		obj := new(Object)
+		if err := readJSON(filepath.Join(s.root, bucket, "objects", name), obj); err != nil {
+			return nil, err
+		}
+		if strings.HasPrefix(obj.Key, prefix) {
+			objects = append(objects, obj)
+		}
+	}
+	sort.Slice(objects, func(i, j int) bool { return objects[i].Key < objects[j].Key })
+	return objects, nil
+}
+
+// GetObject implements Storage
+func (s *DirStorage) GetObject(bucket, key string) (*Object, error) {
+	if _, err := s.GetBucket(bucket); err != nil {
+		return nil, err
+	}
+	path := s.objectPath(bucket, key)
+	obj := new(Object)
+	if err := readJSON(path+".json", obj); os.IsNotExist(err) {
+		return nil, ErrNoSuchKey
+	} else if err != nil {
+		return nil, err
+	}
+	data, err := ioutil.ReadFile(path + ".data")
+	if err != nil {
+		return nil, err
+	}
+	obj.Data = data
+	return obj, nil
+}
+
+// PutObject implements Storage, the data file is written before the
+// metadata file
+func (s *DirStorage) PutObject(bucket string, object *Object) error {
+	if _, err := s.GetBucket(bucket); err != nil {
+		return err
+	}
+	path := s.objectPath(bucket, object.Key)
+	if err := ioutil.WriteFile(path+".data", object.Data, 0644); err != nil {
+		return err
+	}
+	return writeJSON(path+".json", object)
+}
+
+// DeleteObject implements Storage
+func (s *DirStorage) DeleteObject(bucket, key string) error {
+	if _, err := s.GetBucket(bucket); err != nil {
+		return err
+	}
+	path := s.objectPath(bucket, key)
+	for _, file := range []string{path + ".json", path + ".data"} {
+		if err := os.Remove(file); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// File names of the objects: the escaped key, or hashedNamePrefix and the
+// SHA-256 of the key if the escaped key with the extensions would exceed
+// the 255 bytes allowed by most file systems, or if it is "." or "..", which
+// QueryEscape leaves as they are. QueryEscape escapes "=", so the two cannot
+// collide.
+const (
+	maxEscapedKey    = 240
+	hashedNamePrefix = "="
+)
+
+// objectPath returns the path of an object without its extension
+func (s *DirStorage) objectPath(bucket, key string) string {
+	name := url.QueryEscape(key)
+	if len(name) > maxEscapedKey || name == "." || name == ".." {
+		sum := sha256.Sum256([]byte(key))
+		name = hashedNamePrefix + hex.EncodeToString(sum[:])
+	}
+	return filepath.Join(s.root, bucket, "objects", name)
+}
+
+func readJSON(path string, v interface{}) error {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// writeJSON writes a temporary file and renames it, so that a reader never
+// sees a partial file
+func writeJSON(path string, v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(path+".tmp", data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(path+".tmp", path)
+}
@@ -0,0 +1,106 @@
+package osstest
+
+import (
+	"io/ioutil"
+	"net/http"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testStorage(t *testing.T, s Storage) {
+	bucket := &Bucket{Name: testBucket, Location: DefaultLocation, ACL: "private", CreationDate: time.Unix(1500000000, 0).UTC()}
+	if err := s.CreateBucket(bucket); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.CreateBucket(bucket); err != ErrBucketExists {
+		t.Fatalf(expectBut, ErrBucketExists, err)
+	}
+	bucket.Configs = map[string][]byte{"cors": []byte("<CORSConfiguration/>")}
+	if err := s.UpdateBucket(bucket); err != nil {
+		t.Fatal(err)
+	}
+	if actual, err := s.GetBucket(testBucket); err != nil || !reflect.DeepEqual(actual, bucket) {
+		t.Fatalf(expectBut, bucket, []interface{}{actual, err})
+	}
+	if _, err := s.GetBucket("missing"); err != ErrNoSuchBucket {
+		t.Fatalf(expectBut, ErrNoSuchBucket, err)
+	}
+	for _, key := range []string{"b/c", "a", "b/a%2F?"} {
+		obj := &Object{Key: key, Size: 1, ETag: `"E"`, ModTime: bucket.CreationDate, Header: http.Header{"Content-Type": {"text/plain"}}, Data: []byte("x")}
+		if err := s.PutObject(testBucket, obj); err != nil {
+			t.Fatal(err)
+		}
+	}
+	objects, err := s.ListObjects(testBucket, "b/")
+	if err != nil || len(objects) != 2 || objects[0].Key != "b/a%2F?" || objects[1].Key != "b/c" || objects[0].Data != nil {
+		t.Fatalf(expectBut, "b/a%2F? and b/c", []interface{}{objects, err})
+	}
+	obj, err := s.GetObject(testBucket, "b/a%2F?")
+	if err != nil || string(obj.Data) != "x" || obj.Header.Get("Content-Type") != "text/plain" {
+		t.Fatalf(expectBut, "x", []interface{}{obj, err})
+	}
+	// a key too long for a file name
+	long := &Object{Key: "long/" + strings.Repeat("%", 200), Size: 1, Header: http.Header{}, Data: []byte("y")}
+	if err := s.PutObject(testBucket, long); err != nil {
+		t.Fatal(err)
+	}
+	if objects, err := s.ListObjects(testBucket, "long/"); err != nil || len(objects) != 1 || objects[0].Key != long.Key {
+		t.Fatalf(expectBut, long.Key, []interface{}{objects, err})
+	}
+	if obj, err := s.GetObject(testBucket, long.Key); err != nil || string(obj.Data) != "y" {
+		t.Fatalf(expectBut, "y", []interface{}{obj, err})
+	}
+	// keys which are special file names
+	dots := []string{".", "..", "a/../b"}
+	for _, key := range dots {
+		if err := s.PutObject(testBucket, &Object{Key: key, Size: int64(len(key)), Header: http.Header{}, Data: []byte(key)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, key := range dots {
+		if obj, err := s.GetObject(testBucket, key); err != nil || string(obj.Data) != key {
+			t.Fatalf(expectBut, key, []interface{}{obj, err})
+		}
+	}
+	if objects, err := s.ListObjects(testBucket, "."); err != nil || len(objects) != 2 || objects[0].Key != "." || objects[1].Key != ".." {
+		t.Fatalf(expectBut, ". and ..", []interface{}{objects, err})
+	}
+	if objects, err := s.ListObjects(testBucket, "a/"); err != nil || len(objects) != 1 || objects[0].Key != "a/../b" {
+		t.Fatalf(expectBut, "a/../b", []interface{}{objects, err})
+	}
+	if actual, err := s.GetBucket(testBucket); err != nil || !reflect.DeepEqual(actual, bucket) {
+		t.Fatalf(expectBut, bucket, []interface{}{actual, err})
+	}
+	if err := s.DeleteObject(testBucket, "a"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.GetObject(testBucket, "a"); err != ErrNoSuchKey {
+		t.Fatalf(expectBut, ErrNoSuchKey, err)
+	}
+	if err := s.DeleteBucket(testBucket); err != nil {
+		t.Fatal(err)
+	}
+	if buckets, err := s.ListBuckets(); err != nil || len(buckets) != 0 {
+		t.Fatalf(expectBut, "no buckets", []interface{}{buckets, err})
+	}
+}
+
+func TestMemoryStorage(t *testing.T) {
+	testStorage(t, NewMemoryStorage())
+}
+
+func TestDirStorage(t *testing.T) {
+	dir, err := ioutil.TempDir("", "osstest")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	s, err := NewDirStorage(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testStorage(t, s)
+}
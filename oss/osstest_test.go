@@ -0,0 +1,101 @@
+package oss_test
+
+import (
+	"bytes"
+	"crypto/md5"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/aliyun/aliyun-oss-go-sdk/oss"
+	"github.com/aliyun/aliyun-oss-go-sdk/oss/osstest"
+)
+
+// The tests of this package run against the fake OSS of osstest, which
+// imports the oss package and so cannot be used by its internal tests.
+
+const (
+	expectBut         = "\n--- EXPECT ---\n%v\n--- BUT GOT ---\n%v"
+	testcaseExpectBut = "testcase %v:\n--- EXPECT ---\n%v\n--- BUT GOT ---\n%v"
+	testBucketName    = "bucket-name"
+	testObjectName    = "object/name"
+)
+
+// newTestServer starts an osstest.Server with the test bucket
+func newTestServer(t *testing.T) *osstest.Server {
+	server := osstest.NewServer(nil)
+	if err := server.API().PutBucket(testBucketName, oss.PrivateACL); err != nil {
+		server.Close()
+		t.Fatal(err)
+	}
+	return server
+}
+
+func testPlaintext(n int) []byte {
+	buf := make([]byte, n)
+	for i := range buf {
+		buf[i] = byte(i * 7)
+	}
+	return buf
+}
+
+func etagOf(data []byte) string {
+	return fmt.Sprintf(`"%X"`, md5.Sum(data))
+}
+
+// getObjectData returns the data of an object as stored
+func getObjectData(t *testing.T, api *oss.API, object string) []byte {
+	var buf bytes.Buffer
+	if _, err := api.GetObject(testBucketName, object, &buf); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func isCRCError(err error) bool {
+	_, ok := err.(*oss.CRCError)
+	return ok
+}
+
+// testTransport is the transport of an API under test, counting its GET
+// requests and returning wrong X-Oss-Hash-Crc64ecma headers if badCRC is set
+type testTransport struct {
+	mu     sync.Mutex
+	gets   int
+	badCRC bool
+}
+
+func (t *testTransport) client() oss.APIOption {
+	return oss.HTTPClient(&http.Client{Transport: t})
+}
+
+func (t *testTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.mu.Lock()
+	if req.Method == "GET" {
+		t.gets++
+	}
+	badCRC := t.badCRC
+	t.mu.Unlock()
+	resp, err := http.DefaultTransport.RoundTrip(req)
+	if err != nil || !badCRC {
+		return resp, err
+	}
+	if crc, err := strconv.ParseUint(resp.Header.Get("X-Oss-Hash-Crc64ecma"), 10, 64); err == nil {
+		resp.Header.Set("X-Oss-Hash-Crc64ecma", strconv.FormatUint(crc+1, 10))
+	}
+	return resp, nil
+}
+
+func (t *testTransport) setBadCRC(bad bool) {
+	t.mu.Lock()
+	t.badCRC = bad
+	t.mu.Unlock()
+}
+
+func (t *testTransport) getCount() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.gets
+}
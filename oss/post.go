@@ -18,23 +18,30 @@ type PostOption func(*multipart.Writer) error
 
 // PostObject posts an object to OSS in MIME multipart format
 func (a *API) PostObject(bucket, object, filename, policy string, options ...PostOption) (res Header, _ error) {
+	return a.PostObjectWithOptions(bucket, object, filename, policy, options)
+}
+
+// PostObjectWithOptions posts an object like PostObject, options are added
+// to the HTTP request rather than the form, e.g. Progress whose total size
+// includes the other form fields besides the file
+func (a *API) PostObjectWithOptions(bucket, object, filename, policy string, postOptions []PostOption, options ...Option) (res Header, _ error) {
 	buf := new(bytes.Buffer)
 	w := multipart.NewWriter(buf)
 	policy = base64.StdEncoding.EncodeToString([]byte(policy))
-	options = append(options, []PostOption{
+	postOptions = append(postOptions, []PostOption{
 		postObjectName(object),
 		postAccessKeyID(a.accessKeyID),
 		postPolicy(policy),
 		postSignature(hmacSHA1([]byte(policy), []byte(a.accessKeySecret))),
 		postFile(filename),
 	}...)
-	for _, option := range options {
+	for _, option := range postOptions {
 		if err := option(w); err != nil {
 			return nil, err
 		}
 	}
 	w.Close()
-	return res, a.Do("POST", bucket, "", &res, []Option{ContentType(w.FormDataContentType()), HTTPBody(buf)}...)
+	return res, a.Do("POST", bucket, "", &res, append([]Option{ContentType(w.FormDataContentType()), HTTPBody(buf)}, options...)...)
 }
 
 func setMultipartBoundary(boundary string) PostOption {
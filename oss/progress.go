@@ -0,0 +1,199 @@
+package oss
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"sync"
+)
+
+// ProgressEventType is the type of a ProgressEvent
+type ProgressEventType int
+
+const (
+	// ProgressStarted is sent before the first byte is transferred
+	ProgressStarted ProgressEventType = iota
+	// ProgressTransferring is sent whenever a chunk of bytes is transferred
+	ProgressTransferring
+	// ProgressCompleted is sent after the request succeeds
+	ProgressCompleted
+	// ProgressFailed is sent after the request fails
+	ProgressFailed
+)
+
+type (
+	// ProgressEvent describes the progress of an upload or a download
+	ProgressEvent struct {
+		Type ProgressEventType
+		// ConsumedBytes is the number of bytes transferred so far
+		ConsumedBytes int64
+		// TotalBytes is the size of the body, -1 if unknown
+		TotalBytes int64
+		// RWBytes is the number of bytes transferred since the last event
+		RWBytes int64
+		// Err is set for a ProgressFailed event
+		Err error
+	}
+
+	// ProgressListener receives the progress events of a transfer. It is
+	// called synchronously from the goroutine reading the body, so it
+	// should return quickly.
+	ProgressListener func(event ProgressEvent)
+
+	// ProgressGroup aggregates the progress of concurrent transfers, e.g. the
+	// parts of a multipart upload, and reports it to a single listener
+	ProgressGroup struct {
+		mu          sync.Mutex
+		listener    ProgressListener
+		total       int64
+		consumed    int64
+		started     bool
+		completed   bool
+		activeParts int
+	}
+
+	progressKey struct{}
+
+	// progressTracker reports the progress of a request sent by API.Do
+	progressTracker struct {
+		listener ProgressListener
+		upload   bool
+		reader   *progressReader
+	}
+
+	progressReader struct {
+		io.ReadCloser
+		listener ProgressListener
+		consumed int64
+		total    int64
+	}
+)
+
+// Progress is an option to receive the progress of the request body
+// (PutObject, AppendObject, UploadPart) or the response body (GetObject)
+func Progress(listener ProgressListener) Option {
+	return func(req *http.Request) error {
+		*req = *req.WithContext(context.WithValue(req.Context(), progressKey{}, listener))
+		return nil
+	}
+}
+
+// newProgressTracker returns nil if there is no listener, otherwise it starts
+// tracking the request body if it has one
+func newProgressTracker(req *http.Request) *progressTracker {
+	listener, _ := req.Context().Value(progressKey{}).(ProgressListener)
+	if listener == nil {
+		return nil
+	}
+	t := &progressTracker{listener: listener}
+	if req.Body != nil && req.Body != http.NoBody {
+		t.upload = true
+		total := req.ContentLength
+		if total <= 0 {
+			total = -1
+		}
+		t.start(&req.Body, total)
+	}
+	return t
+}
+
+func (t *progressTracker) start(body *io.ReadCloser, total int64) {
+	t.reader = &progressReader{ReadCloser: *body, listener: t.listener, total: total}
+	*body = t.reader
+	t.listener(ProgressEvent{Type: ProgressStarted, TotalBytes: total})
+}
+
+// wrapResponse starts tracking the response body of a download
+func (t *progressTracker) wrapResponse(resp *http.Response) {
+	if t == nil || t.upload || resp.StatusCode/100 != 2 {
+		return
+	}
+	total := resp.ContentLength
+	if total < 0 {
+		total = -1
+	}
+	t.start(&resp.Body, total)
+}
+
+// finish sends the last event and returns err as it is
+func (t *progressTracker) finish(err error) error {
+	if t == nil {
+		return err
+	}
+	event := ProgressEvent{Type: ProgressCompleted, TotalBytes: -1, Err: err}
+	if t.reader != nil {
+		event.ConsumedBytes, event.TotalBytes = t.reader.consumed, t.reader.total
+	}
+	if err != nil {
+		event.Type = ProgressFailed
+	}
+	t.listener(event)
+	return err
+}
+
+func (r *progressReader) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	if n > 0 {
+		r.consumed += int64(n)
+		r.listener(ProgressEvent{
+			Type:          ProgressTransferring,
+			ConsumedBytes: r.consumed,
+			TotalBytes:    r.total,
+			RWBytes:       int64(n),
+		})
+	}
+	return n, err
+}
+
+// NewProgressGroup creates a ProgressGroup for transfers of total bytes
+func NewProgressGroup(total int64, listener ProgressListener) *ProgressGroup {
+	return &ProgressGroup{listener: listener, total: total}
+}
+
+// Listener returns the ProgressListener for one of the transfers, it should
+// be passed to the Progress option of a single request. The bytes of a
+// failed transfer are subtracted so that it can be retried with a new
+// listener.
+func (g *ProgressGroup) Listener() ProgressListener {
+	var (
+		consumed int64
+		started  bool
+	)
+	return func(event ProgressEvent) {
+		g.mu.Lock()
+		defer g.mu.Unlock()
+		switch event.Type {
+		case ProgressStarted:
+			started = true
+			g.activeParts++
+			if !g.started {
+				g.started = true
+				g.listener(ProgressEvent{Type: ProgressStarted, TotalBytes: g.total})
+			}
+		case ProgressTransferring:
+			consumed += event.RWBytes
+			g.consumed += event.RWBytes
+			g.listener(ProgressEvent{
+				Type:          ProgressTransferring,
+				ConsumedBytes: g.consumed,
+				TotalBytes:    g.total,
+				RWBytes:       event.RWBytes,
+			})
+		case ProgressCompleted:
+			started = false
+			g.activeParts--
+			if !g.completed && g.activeParts == 0 && g.consumed >= g.total {
+				g.completed = true
+				g.listener(ProgressEvent{Type: ProgressCompleted, ConsumedBytes: g.consumed, TotalBytes: g.total})
+			}
+		case ProgressFailed:
+			if started {
+				started = false
+				g.activeParts--
+			}
+			g.consumed -= consumed
+			consumed = 0
+			g.listener(ProgressEvent{Type: ProgressFailed, ConsumedBytes: g.consumed, TotalBytes: g.total, Err: event.Err})
+		}
+	}
+}
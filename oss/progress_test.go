@@ -0,0 +1,127 @@
+package oss_test
+
+import (
+	"bytes"
+	"sync"
+	"testing"
+
+	"github.com/aliyun/aliyun-oss-go-sdk/oss"
+)
+
+type progressRecorder struct {
+	mu     sync.Mutex
+	events []oss.ProgressEvent
+}
+
+func (r *progressRecorder) listener(event oss.ProgressEvent) {
+	r.mu.Lock()
+	r.events = append(r.events, event)
+	r.mu.Unlock()
+}
+
+func (r *progressRecorder) check(t *testing.T, name string, total int64, last oss.ProgressEventType) {
+	if len(r.events) < 2 {
+		t.Fatalf(testcaseExpectBut, name, "at least 2 events", r.events)
+	}
+	if first := r.events[0]; first.Type != oss.ProgressStarted || first.TotalBytes != total {
+		t.Fatalf(testcaseExpectBut, name, oss.ProgressEvent{Type: oss.ProgressStarted, TotalBytes: total}, first)
+	}
+	var consumed int64
+	for _, event := range r.events[1 : len(r.events)-1] {
+		consumed += event.RWBytes
+		if event.Type != oss.ProgressTransferring || event.ConsumedBytes != consumed || event.TotalBytes != total {
+			t.Fatalf(testcaseExpectBut, name, "transferring event", event)
+		}
+	}
+	if end := r.events[len(r.events)-1]; end.Type != last || end.ConsumedBytes != consumed {
+		t.Fatalf(testcaseExpectBut, name, last, end)
+	}
+	if last == oss.ProgressCompleted && consumed != total {
+		t.Fatalf(testcaseExpectBut, name, total, consumed)
+	}
+}
+
+func TestProgress(t *testing.T) {
+	server := newTestServer(t)
+	defer server.Close()
+	api := server.API()
+	data := testPlaintext(100000)
+	{
+		r := &progressRecorder{}
+		if err := api.PutObject(testBucketName, testObjectName, bytes.NewReader(data), oss.Progress(r.listener)); err != nil {
+			t.Fatal(err)
+		}
+		r.check(t, "PutObject", int64(len(data)), oss.ProgressCompleted)
+	}
+	{
+		r := &progressRecorder{}
+		if _, err := api.GetObject(testBucketName, testObjectName, new(bytes.Buffer), oss.Progress(r.listener)); err != nil {
+			t.Fatal(err)
+		}
+		r.check(t, "GetObject", int64(len(data)), oss.ProgressCompleted)
+	}
+	{
+		r := &progressRecorder{}
+		if _, err := api.AppendObject(testBucketName, "append", bytes.NewReader(data[:10]), 0, oss.Progress(r.listener)); err != nil {
+			t.Fatal(err)
+		}
+		r.check(t, "AppendObject", 10, oss.ProgressCompleted)
+	}
+	{
+		r := &progressRecorder{}
+		if _, err := api.GetObject(testBucketName, "missing", new(bytes.Buffer), oss.Progress(r.listener)); err == nil {
+			t.Fatal("expect error but got nil")
+		}
+		if len(r.events) != 1 || r.events[0].Type != oss.ProgressFailed || r.events[0].Err == nil {
+			t.Fatalf(expectBut, "a failed event", r.events)
+		}
+	}
+	{
+		r := &progressRecorder{}
+		if _, err := api.PostObjectWithOptions(testBucketName, testObjectName, "testdata/test", "", nil, oss.Progress(r.listener)); err != nil {
+			t.Fatal(err)
+		}
+		r.check(t, "PostObject", r.events[0].TotalBytes, oss.ProgressCompleted)
+		if r.events[0].TotalBytes <= 16 {
+			t.Fatalf(expectBut, "form size", r.events[0].TotalBytes)
+		}
+	}
+}
+
+func TestProgressGroup(t *testing.T) {
+	server := newTestServer(t)
+	defer server.Close()
+	api := server.API()
+	data := testPlaintext(100000)
+	upload, err := api.InitUpload(testBucketName, testObjectName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := &progressRecorder{}
+	group := oss.NewProgressGroup(int64(len(data)), r.listener)
+	// a failed attempt does not count
+	if _, err := api.UploadPart(testBucketName, testObjectName, "missing", 1, bytes.NewReader(data), 10, oss.Progress(group.Listener())); err == nil {
+		t.Fatal("expect error but got nil")
+	}
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			part := data[i*25000 : (i+1)*25000]
+			if _, err := api.UploadPart(testBucketName, testObjectName, upload.UploadID, i+1, bytes.NewReader(part), int64(len(part)), oss.Progress(group.Listener())); err != nil {
+				t.Error(err)
+			}
+		}(i)
+	}
+	wg.Wait()
+	end := r.events[len(r.events)-1]
+	if end.Type != oss.ProgressCompleted || end.ConsumedBytes != int64(len(data)) {
+		t.Fatalf(expectBut, "completed event", end)
+	}
+	for _, event := range r.events {
+		if event.ConsumedBytes > int64(len(data)) {
+			t.Fatalf(expectBut, "consumed bytes within total", event)
+		}
+	}
+}
@@ -0,0 +1,128 @@
+package oss
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Range of x-oss-traffic-limit in bit/s: 100KB/s to 100MB/s
+const (
+	MinTrafficLimit = 100 * 1024 * 8
+	MaxTrafficLimit = 100 * 1024 * 1024 * 8
+)
+
+// ErrInvalidTrafficLimit happens when the traffic limit is out of the range
+// supported by OSS
+var ErrInvalidTrafficLimit = errors.New("traffic limit must be between 819200 and 838860800 bit/s")
+
+// TrafficLimit is an option to set X-Oss-Traffic-Limit header, which asks OSS
+// to limit the speed of PutObject, GetObject, UploadPart, AppendObject and
+// CopyObject to bitsPerSecond
+func TrafficLimit(bitsPerSecond int64) Option {
+	return func(req *http.Request) error {
+		if bitsPerSecond < MinTrafficLimit || bitsPerSecond > MaxTrafficLimit {
+			return ErrInvalidTrafficLimit
+		}
+		req.Header.Set("X-Oss-Traffic-Limit", strconv.FormatInt(bitsPerSecond, 10))
+		return nil
+	}
+}
+
+type (
+	// RateLimiter is a token bucket limiting the bytes per second read from
+	// request and response bodies. It is safe for concurrent use, so a
+	// single RateLimiter shared by an API object limits all its concurrent
+	// transfers collectively.
+	RateLimiter struct {
+		mu     sync.Mutex
+		rate   float64 // bytes per second
+		burst  int64
+		tokens float64
+		last   time.Time
+		now    func() time.Time
+		sleep  func(time.Duration)
+	}
+
+	rateLimitedReader struct {
+		io.ReadCloser
+		limiter *RateLimiter
+	}
+)
+
+// NewRateLimiter creates a RateLimiter allowing bytesPerSecond on average
+// and bursts of up to burst bytes. burst is set to bytesPerSecond if it is
+// not positive. A bytesPerSecond which is not positive means no limit, the
+// nil RateLimiter is returned.
+func NewRateLimiter(bytesPerSecond, burst int64) *RateLimiter {
+	if bytesPerSecond <= 0 {
+		return nil
+	}
+	if burst <= 0 {
+		burst = bytesPerSecond
+	}
+	return &RateLimiter{
+		rate:   float64(bytesPerSecond),
+		burst:  burst,
+		tokens: float64(burst),
+		now:    time.Now,
+		sleep:  time.Sleep,
+	}
+}
+
+// RateLimit sets a client-side bandwidth limit for all the request and
+// response bodies transferred by the API object
+func RateLimit(limiter *RateLimiter) APIOption {
+	return func(a *API) {
+		a.rateLimiter = limiter
+	}
+}
+
+// WaitN blocks until n bytes can be transferred. Tokens are reserved before
+// waiting, so concurrent callers are served in turn. A nil RateLimiter
+// never blocks.
+func (l *RateLimiter) WaitN(n int) {
+	if l == nil {
+		return
+	}
+	l.mu.Lock()
+	now := l.now()
+	if !l.last.IsZero() {
+		l.tokens += now.Sub(l.last).Seconds() * l.rate
+		if l.tokens > float64(l.burst) {
+			l.tokens = float64(l.burst)
+		}
+	}
+	l.last = now
+	l.tokens -= float64(n)
+	var wait time.Duration
+	if l.tokens < 0 {
+		wait = time.Duration(-l.tokens / l.rate * float64(time.Second))
+	}
+	l.mu.Unlock()
+	if wait > 0 {
+		l.sleep(wait)
+	}
+}
+
+// reader returns rc limited by l, or rc itself if l is nil
+func (l *RateLimiter) reader(rc io.ReadCloser) io.ReadCloser {
+	if l == nil || rc == nil || rc == http.NoBody {
+		return rc
+	}
+	return &rateLimitedReader{ReadCloser: rc, limiter: l}
+}
+
+func (r *rateLimitedReader) Read(p []byte) (int, error) {
+	if int64(len(p)) > r.limiter.burst {
+		p = p[:r.limiter.burst]
+	}
+	n, err := r.ReadCloser.Read(p)
+	if n > 0 {
+		r.limiter.WaitN(n)
+	}
+	return n, err
+}
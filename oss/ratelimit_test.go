@@ -0,0 +1,111 @@
+package oss_test
+
+import (
+	"bytes"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aliyun/aliyun-oss-go-sdk/oss"
+)
+
+func TestTrafficLimit(t *testing.T) {
+	req, _ := http.NewRequest("GET", "", nil)
+	if err := oss.TrafficLimit(oss.MinTrafficLimit)(req); err != nil {
+		t.Fatal(err)
+	}
+	if expected, actual := "819200", req.Header.Get("X-Oss-Traffic-Limit"); actual != expected {
+		t.Fatalf(expectBut, expected, actual)
+	}
+	for _, limit := range []int64{0, oss.MinTrafficLimit - 1, oss.MaxTrafficLimit + 1} {
+		if err := oss.TrafficLimit(limit)(req); err != oss.ErrInvalidTrafficLimit {
+			t.Fatalf(testcaseExpectBut, limit, oss.ErrInvalidTrafficLimit, err)
+		}
+	}
+}
+
+// fakeClock advances only when sleeping
+type fakeClock struct {
+	mu    sync.Mutex
+	t     time.Time
+	slept time.Duration
+}
+
+func (c *fakeClock) now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.t
+}
+
+func (c *fakeClock) sleep(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.t = c.t.Add(d)
+	c.slept += d
+}
+
+func TestRateLimiter(t *testing.T) {
+	clock := &fakeClock{t: time.Date(2015, 10, 21, 15, 56, 35, 0, time.UTC)}
+	l := oss.NewRateLimiter(1000, 0)
+	l.SetClock(clock.now, clock.sleep)
+	for i := 0; i < 10; i++ {
+		l.WaitN(500)
+	}
+	// the first 1000 bytes are the burst
+	if expected := 4 * time.Second; clock.slept != expected {
+		t.Fatalf(expectBut, expected, clock.slept)
+	}
+}
+
+func TestNonPositiveRateLimiter(t *testing.T) {
+	server := newTestServer(t)
+	defer server.Close()
+	for _, rate := range []int64{0, -1} {
+		limiter := oss.NewRateLimiter(rate, 1000)
+		if limiter != nil {
+			t.Fatalf(testcaseExpectBut, rate, "no limit", limiter)
+		}
+		limiter.WaitN(1 << 30)
+		api := server.API(oss.RateLimit(limiter))
+		if err := api.PutObject(testBucketName, testObjectName, bytes.NewReader([]byte("unlimited"))); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestRateLimitedTransfers(t *testing.T) {
+	server := newTestServer(t)
+	defer server.Close()
+	clock := &fakeClock{t: time.Date(2015, 10, 21, 15, 56, 35, 0, time.UTC)}
+	limiter := oss.NewRateLimiter(10000, 1000)
+	limiter.SetClock(clock.now, clock.sleep)
+	api := server.API(oss.RateLimit(limiter))
+	data := testPlaintext(20000)
+	var wg sync.WaitGroup
+	for i := 0; i < 2; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			object := testObjectName + string(rune('0'+i))
+			if err := api.PutObject(testBucketName, object, bytes.NewReader(data)); err != nil {
+				t.Error(err)
+			}
+		}(i)
+	}
+	wg.Wait()
+	// 40000 bytes shared by both uploads, less the initial burst
+	if min := 3900 * time.Millisecond; clock.slept < min {
+		t.Fatalf(expectBut, min, clock.slept)
+	}
+	buf := new(bytes.Buffer)
+	if _, err := api.GetObject(testBucketName, testObjectName+"0", buf); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(buf.Bytes(), data) {
+		t.Fatal("downloaded data mismatch")
+	}
+	if min := 5900 * time.Millisecond; clock.slept < min {
+		t.Fatalf(expectBut, min, clock.slept)
+	}
+}
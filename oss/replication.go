@@ -0,0 +1,183 @@
+package oss
+
+import (
+	"encoding/xml"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// Values of ReplicationRule
+const (
+	// ReplicationActionAll replicates creations, overwrites and deletions,
+	// Action can also be a comma separated list of PUT, DELETE and ABORT
+	ReplicationActionAll = "ALL"
+	ReplicationActionPut = "PUT"
+
+	// TransferTypeInternal replicates over the OSS network,
+	// TransferTypeAcceleration over the transfer acceleration network
+	TransferTypeInternal     = "internal"
+	TransferTypeAcceleration = "oss_acc"
+
+	// ReplicationEnabled and ReplicationDisabled are the values of
+	// HistoricalObjectReplication and RTC
+	ReplicationEnabled  = "enabled"
+	ReplicationDisabled = "disabled"
+
+	// Statuses of a rule returned by GetBucketReplication
+	ReplicationStarting = "starting"
+	ReplicationDoing    = "doing"
+	ReplicationClosing  = "closing"
+)
+
+type (
+	// ReplicationConfiguration is the input for PutBucketReplication API and
+	// returned by GetBucketReplication API
+	ReplicationConfiguration struct {
+		Rule []ReplicationRule
+	}
+	// ReplicationRule replicates the objects with one of the prefixes, or all
+	// the objects if Prefix is empty, to the Destination
+	ReplicationRule struct {
+		ID          string   `xml:"ID,omitempty"`
+		Prefix      []string `xml:"PrefixSet>Prefix,omitempty"`
+		Action      string   `xml:"Action,omitempty"`
+		Destination ReplicationDestination
+		// Status is returned by GetBucketReplication, e.g. ReplicationDoing
+		Status string `xml:"Status,omitempty"`
+		// HistoricalObjectReplication is ReplicationEnabled to replicate the
+		// objects existing before the rule
+		HistoricalObjectReplication string `xml:"HistoricalObjectReplication,omitempty"`
+		// SyncRole is the RAM role replicating SSE-KMS encrypted objects
+		SyncRole                string                              `xml:"SyncRole,omitempty"`
+		SourceSelectionCriteria *ReplicationSourceSelectionCriteria `xml:"SourceSelectionCriteria,omitempty"`
+		EncryptionConfiguration *ReplicationEncryptionConfiguration `xml:"EncryptionConfiguration,omitempty"`
+		RTC                     *ReplicationRTC                     `xml:"RTC,omitempty"`
+		// Progress is returned by GetBucketReplicationProgress
+		Progress *ReplicationRuleProgress `xml:"Progress,omitempty"`
+	}
+	// ReplicationDestination is where a rule replicates to
+	ReplicationDestination struct {
+		Bucket       string
+		Location     string
+		TransferType string `xml:"TransferType,omitempty"`
+	}
+	// ReplicationSourceSelectionCriteria selects the objects to replicate
+	// besides the prefixes
+	ReplicationSourceSelectionCriteria struct {
+		SseKmsEncryptedObjects SseKmsEncryptedObjects
+	}
+	// SseKmsEncryptedObjects replicates the SSE-KMS encrypted objects if
+	// Status is Enabled, they are skipped if it is Disabled
+	SseKmsEncryptedObjects struct {
+		Status string
+	}
+	// ReplicationEncryptionConfiguration is the KMS key encrypting the
+	// replicas of SSE-KMS encrypted objects
+	ReplicationEncryptionConfiguration struct {
+		ReplicaKmsKeyID string
+	}
+	// ReplicationRTC is the replication time control of a rule, whose
+	// Status is ReplicationEnabled or ReplicationDisabled, or enabling while
+	// it is turned on
+	ReplicationRTC struct {
+		Status string
+	}
+	// ReplicationRuleProgress is the progress of a rule
+	ReplicationRuleProgress struct {
+		// HistoricalObject is the ratio of the existing objects replicated,
+		// from 0 to 1
+		HistoricalObject float64 `xml:"HistoricalObject,omitempty"`
+		// NewObject is the time before which all the new objects are
+		// replicated
+		NewObject *time.Time `xml:"NewObject,omitempty"`
+	}
+
+	// ReplicationLocation is returned by GetBucketReplicationLocation API
+	ReplicationLocation struct {
+		// Location are the regions the bucket can replicate to
+		Location []string
+		// TransferTypes are the transfer types other than internal of the
+		// locations supporting them
+		TransferTypes []LocationTransferType `xml:"LocationTransferTypeConstraint>LocationTransferType"`
+		// RTCLocations are the locations supporting RTC
+		RTCLocations []string `xml:"LocationRTCConstraint>Location"`
+	}
+	// LocationTransferType is the transfer types supported by a location
+	LocationTransferType struct {
+		Location      string
+		TransferTypes []string `xml:"TransferTypes>Type"`
+	}
+
+	// ReplicationProgress is returned by GetBucketReplicationProgress API
+	ReplicationProgress struct {
+		Rule []ReplicationRule
+	}
+
+	replicationRules struct {
+		XMLName xml.Name `xml:"ReplicationRules"`
+		ID      string
+	}
+	replicationRTCRule struct {
+		XMLName xml.Name `xml:"ReplicationRule"`
+		RTC     ReplicationRTC
+		ID      string
+	}
+)
+
+// PutBucketReplication adds a replication rule to a bucket
+func (a *API) PutBucketReplication(bucket string, config *ReplicationConfiguration) error {
+	return a.Do("POST", bucket, "?comp=add&replication", nil, XMLBody(config))
+}
+
+// GetBucketReplication returns the replication rules of a bucket
+func (a *API) GetBucketReplication(bucket string) (res *ReplicationConfiguration, _ error) {
+	return res, a.Do("GET", bucket, "?replication", &res)
+}
+
+// GetBucketReplicationLocation returns the locations a bucket can
+// replicate to
+func (a *API) GetBucketReplicationLocation(bucket string) (res *ReplicationLocation, _ error) {
+	return res, a.Do("GET", bucket, "?replicationLocation", &res)
+}
+
+// GetBucketReplicationProgress returns the progress of a replication rule
+func (a *API) GetBucketReplicationProgress(bucket, ruleID string) (res *ReplicationProgress, _ error) {
+	return res, a.Do("GET", bucket, "?replicationProgress&rule-id="+url.QueryEscape(ruleID), &res)
+}
+
+// DeleteBucketReplication stops and deletes a replication rule, the
+// replicated objects are kept
+func (a *API) DeleteBucketReplication(bucket, ruleID string) error {
+	return a.Do("POST", bucket, "?comp=delete&replication", nil, XMLBody(&replicationRules{ID: ruleID}))
+}
+
+// PutBucketRTC turns the replication time control of a rule on or off,
+// status is ReplicationEnabled or ReplicationDisabled
+func (a *API) PutBucketRTC(bucket, ruleID, status string) error {
+	return a.Do("PUT", bucket, "?rtc", nil, XMLBody(&replicationRTCRule{RTC: ReplicationRTC{Status: status}, ID: ruleID}))
+}
+
+// Lag returns how long the new objects written before now may wait for
+// replication, or 0 if the progress of the new objects is unknown
+func (p *ReplicationRuleProgress) Lag(now time.Time) time.Duration {
+	if p == nil || p.NewObject == nil || now.Before(*p.NewObject) {
+		return 0
+	}
+	return now.Sub(*p.NewObject)
+}
+
+// Parse implements ResponseParser
+func (r *ReplicationConfiguration) Parse(resp *http.Response) error {
+	return xml.NewDecoder(resp.Body).Decode(r)
+}
+
+// Parse implements ResponseParser
+func (r *ReplicationLocation) Parse(resp *http.Response) error {
+	return xml.NewDecoder(resp.Body).Decode(r)
+}
+
+// Parse implements ResponseParser
+func (r *ReplicationProgress) Parse(resp *http.Response) error {
+	return xml.NewDecoder(resp.Body).Decode(r)
+}
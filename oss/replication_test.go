@@ -0,0 +1,270 @@
+package oss
+
+import (
+	"testing"
+	"time"
+)
+
+var replicationTestcases = []testcase{
+	{
+		name: "PutBucketReplication",
+		request: func(a *API) (interface{}, error) {
+			return nil, a.PutBucketReplication(testBucketName, &ReplicationConfiguration{
+				Rule: []ReplicationRule{
+					{
+						Prefix:                      []string{"source1", "video"},
+						Action:                      ReplicationActionPut,
+						Destination:                 ReplicationDestination{Bucket: "destbucket", Location: "oss-cn-beijing", TransferType: TransferTypeAcceleration},
+						HistoricalObjectReplication: ReplicationEnabled,
+						SyncRole:                    "aliyunramrole",
+						SourceSelectionCriteria:     &ReplicationSourceSelectionCriteria{SseKmsEncryptedObjects{Status: "Enabled"}},
+						EncryptionConfiguration:     &ReplicationEncryptionConfiguration{ReplicaKmsKeyID: "c4d49f85-ee30-426b-a5ed-95e9139d****"},
+						RTC:                         &ReplicationRTC{Status: ReplicationEnabled},
+					},
+				},
+			})
+		},
+		expectedRequest: `POST /?comp=add&replication HTTP/1.1
+Host: bucket-name.oss-cn-hangzhou.aliyuncs.com
+User-Agent: %s
+Content-Length: 661
+Accept-Encoding: identity
+Authorization: OSS ayahghai0juiSie:i/7rq+SdU3gAyHsLSPUz/q/Uwxk=
+Date: %s
+
+<ReplicationConfiguration><Rule><PrefixSet><Prefix>source1</Prefix><Prefix>video</Prefix></PrefixSet><Action>PUT</Action><Destination><Bucket>destbucket</Bucket><Location>oss-cn-beijing</Location><TransferType>oss_acc</TransferType></Destination><HistoricalObjectReplication>enabled</HistoricalObjectReplication><SyncRole>aliyunramrole</SyncRole><SourceSelectionCriteria><SseKmsEncryptedObjects><Status>Enabled</Status></SseKmsEncryptedObjects></SourceSelectionCriteria><EncryptionConfiguration><ReplicaKmsKeyID>c4d49f85-ee30-426b-a5ed-95e9139d****</ReplicaKmsKeyID></EncryptionConfiguration><RTC><Status>enabled</Status></RTC></Rule></ReplicationConfiguration>`,
+		response: `HTTP/1.1 200 OK
+x-oss-request-id: 534B371674E88A4D8906****
+Date: Tue, 17 Jan 2016 13:53:21 GMT
+Content-Length: 0
+Connection: close
+Server: AliyunOSS
+`,
+		expectedResponse: nil,
+	},
+
+	{
+		name: "GetBucketReplication",
+		request: func(a *API) (interface{}, error) {
+			r, err := a.GetBucketReplication(testBucketName)
+			return r, err
+		},
+		expectedRequest: `GET /?replication HTTP/1.1
+Host: bucket-name.oss-cn-hangzhou.aliyuncs.com
+User-Agent: %s
+Accept-Encoding: identity
+Authorization: OSS ayahghai0juiSie:Zy5/MnqDh7/PiMTHPew8eE2jCkM=
+Date: %s`,
+		response: `HTTP/1.1 200 OK
+x-oss-request-id: 534B371674E88A4D8906****
+Date: Tue, 17 Jan 2016 13:53:21 GMT
+Content-Type: application/xml
+Content-Length: 542
+Connection: close
+Server: AliyunOSS
+
+<?xml version="1.0" encoding="UTF-8"?>
+<ReplicationConfiguration>
+  <Rule>
+    <ID>test_replication_1</ID>
+    <PrefixSet>
+      <Prefix>source1</Prefix>
+    </PrefixSet>
+    <Action>ALL</Action>
+    <Destination>
+      <Bucket>destbucket</Bucket>
+      <Location>oss-cn-beijing</Location>
+      <TransferType>internal</TransferType>
+    </Destination>
+    <Status>doing</Status>
+    <HistoricalObjectReplication>disabled</HistoricalObjectReplication>
+    <RTC>
+      <Status>enabling</Status>
+    </RTC>
+  </Rule>
+</ReplicationConfiguration>`,
+		expectedResponse: &ReplicationConfiguration{
+			Rule: []ReplicationRule{
+				{
+					ID:                          "test_replication_1",
+					Prefix:                      []string{"source1"},
+					Action:                      ReplicationActionAll,
+					Destination:                 ReplicationDestination{Bucket: "destbucket", Location: "oss-cn-beijing", TransferType: TransferTypeInternal},
+					Status:                      ReplicationDoing,
+					HistoricalObjectReplication: ReplicationDisabled,
+					RTC:                         &ReplicationRTC{Status: "enabling"},
+				},
+			},
+		},
+	},
+
+	{
+		name: "GetBucketReplicationLocation",
+		request: func(a *API) (interface{}, error) {
+			r, err := a.GetBucketReplicationLocation(testBucketName)
+			return r, err
+		},
+		expectedRequest: `GET /?replicationLocation HTTP/1.1
+Host: bucket-name.oss-cn-hangzhou.aliyuncs.com
+User-Agent: %s
+Accept-Encoding: identity
+Authorization: OSS ayahghai0juiSie:6DobBpWWd7SkgKaOd4bhpiOzSEw=
+Date: %s`,
+		response: `HTTP/1.1 200 OK
+x-oss-request-id: 534B371674E88A4D8906****
+Date: Tue, 17 Jan 2016 13:53:21 GMT
+Content-Type: application/xml
+Content-Length: 492
+Connection: close
+Server: AliyunOSS
+
+<?xml version="1.0" encoding="UTF-8"?>
+<ReplicationLocation>
+  <Location>oss-cn-beijing</Location>
+  <Location>oss-us-west-1</Location>
+  <LocationTransferTypeConstraint>
+    <LocationTransferType>
+      <Location>oss-us-west-1</Location>
+      <TransferTypes>
+        <Type>oss_acc</Type>
+      </TransferTypes>
+    </LocationTransferType>
+  </LocationTransferTypeConstraint>
+  <LocationRTCConstraint>
+    <Location>oss-cn-beijing</Location>
+  </LocationRTCConstraint>
+</ReplicationLocation>`,
+		expectedResponse: &ReplicationLocation{
+			Location:      []string{"oss-cn-beijing", "oss-us-west-1"},
+			TransferTypes: []LocationTransferType{{Location: "oss-us-west-1", TransferTypes: []string{TransferTypeAcceleration}}},
+			RTCLocations:  []string{"oss-cn-beijing"},
+		},
+	},
+
+	{
+		name: "GetBucketReplicationProgress",
+		request: func(a *API) (interface{}, error) {
+			r, err := a.GetBucketReplicationProgress(testBucketName, "test_replication_1")
+			return r, err
+		},
+		expectedRequest: `GET /?replicationProgress&rule-id=test_replication_1 HTTP/1.1
+Host: bucket-name.oss-cn-hangzhou.aliyuncs.com
+User-Agent: %s
+Accept-Encoding: identity
+Authorization: OSS ayahghai0juiSie:MZ08hSxuR4WdRToqgbsdRfBIgQw=
+Date: %s`,
+		response: `HTTP/1.1 200 OK
+x-oss-request-id: 534B371674E88A4D8906****
+Date: Tue, 17 Jan 2016 13:53:21 GMT
+Content-Type: application/xml
+Content-Length: 610
+Connection: close
+Server: AliyunOSS
+
+<?xml version="1.0" encoding="UTF-8"?>
+<ReplicationProgress>
+  <Rule>
+    <ID>test_replication_1</ID>
+    <PrefixSet>
+      <Prefix>source1</Prefix>
+    </PrefixSet>
+    <Action>PUT</Action>
+    <Destination>
+      <Bucket>destbucket</Bucket>
+      <Location>oss-cn-beijing</Location>
+      <TransferType>oss_acc</TransferType>
+    </Destination>
+    <Status>doing</Status>
+    <HistoricalObjectReplication>enabled</HistoricalObjectReplication>
+    <Progress>
+      <HistoricalObject>0.85</HistoricalObject>
+      <NewObject>2015-09-24T15:28:14.000Z</NewObject>
+    </Progress>
+  </Rule>
+</ReplicationProgress>`,
+		expectedResponse: &ReplicationProgress{
+			Rule: []ReplicationRule{
+				{
+					ID:                          "test_replication_1",
+					Prefix:                      []string{"source1"},
+					Action:                      ReplicationActionPut,
+					Destination:                 ReplicationDestination{Bucket: "destbucket", Location: "oss-cn-beijing", TransferType: TransferTypeAcceleration},
+					Status:                      ReplicationDoing,
+					HistoricalObjectReplication: ReplicationEnabled,
+					Progress:                    &ReplicationRuleProgress{HistoricalObject: 0.85, NewObject: parseTimePtr(time.RFC3339, "2015-09-24T15:28:14.000Z")},
+				},
+			},
+		},
+	},
+
+	{
+		name: "DeleteBucketReplication",
+		request: func(a *API) (interface{}, error) {
+			return nil, a.DeleteBucketReplication(testBucketName, "test_replication_1")
+		},
+		expectedRequest: `POST /?comp=delete&replication HTTP/1.1
+Host: bucket-name.oss-cn-hangzhou.aliyuncs.com
+User-Agent: %s
+Content-Length: 64
+Accept-Encoding: identity
+Authorization: OSS ayahghai0juiSie:PHfk2xtHEb0bXrM6/s6FZj+t2jU=
+Date: %s
+
+<ReplicationRules><ID>test_replication_1</ID></ReplicationRules>`,
+		response: `HTTP/1.1 200 OK
+x-oss-request-id: 534B371674E88A4D8906****
+Date: Tue, 17 Jan 2016 13:53:21 GMT
+Content-Length: 0
+Connection: close
+Server: AliyunOSS
+`,
+		expectedResponse: nil,
+	},
+
+	{
+		name: "PutBucketRTC",
+		request: func(a *API) (interface{}, error) {
+			return nil, a.PutBucketRTC(testBucketName, "test_replication_1", ReplicationEnabled)
+		},
+		expectedRequest: `PUT /?rtc HTTP/1.1
+Host: bucket-name.oss-cn-hangzhou.aliyuncs.com
+User-Agent: %s
+Content-Length: 97
+Accept-Encoding: identity
+Authorization: OSS ayahghai0juiSie:eQ3YPRke5sTtpGC6xBIC/cBqSXQ=
+Date: %s
+
+<ReplicationRule><RTC><Status>enabled</Status></RTC><ID>test_replication_1</ID></ReplicationRule>`,
+		response: `HTTP/1.1 200 OK
+x-oss-request-id: 534B371674E88A4D8906****
+Date: Tue, 17 Jan 2016 13:53:21 GMT
+Content-Length: 0
+Connection: close
+Server: AliyunOSS
+`,
+		expectedResponse: nil,
+	},
+}
+
+func TestReplicationAPI(t *testing.T) {
+	for i := range replicationTestcases {
+		testAPI(t, &replicationTestcases[i])
+	}
+}
+
+func TestReplicationLag(t *testing.T) {
+	now := parseTime(time.RFC3339, "2015-09-24T15:30:00Z")
+	for _, testcase := range []struct {
+		progress *ReplicationRuleProgress
+		expected time.Duration
+	}{
+		{nil, 0},
+		{&ReplicationRuleProgress{HistoricalObject: 0.5}, 0},
+		{&ReplicationRuleProgress{NewObject: parseTimePtr(time.RFC3339, "2015-09-24T15:28:14Z")}, 106 * time.Second},
+		{&ReplicationRuleProgress{NewObject: parseTimePtr(time.RFC3339, "2015-09-24T15:31:00Z")}, 0},
+	} {
+		if actual := testcase.progress.Lag(now); actual != testcase.expected {
+			t.Fatalf(testcaseExpectBut, testcase.progress, testcase.expected, actual)
+		}
+	}
+}
@@ -0,0 +1,134 @@
+package oss
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"regexp"
+	"time"
+)
+
+// Tiers of restoring a Cold Archive object
+const (
+	ExpeditedTier = "Expedited"
+	StandardTier  = "Standard"
+	BulkTier      = "Bulk"
+)
+
+type (
+	// RestoreRequest is the input for RestoreObject API. It is only required
+	// by Cold Archive objects.
+	RestoreRequest struct {
+		XMLName       xml.Name       `xml:"RestoreRequest"`
+		Days          int            `xml:"Days,omitempty"`
+		JobParameters *JobParameters `xml:"JobParameters,omitempty"`
+	}
+	// JobParameters is the container for the restore tier
+	JobParameters struct {
+		Tier string
+	}
+
+	// RestoreStatus is parsed from X-Oss-Restore header
+	RestoreStatus struct {
+		// Ongoing is true while the object is being restored
+		Ongoing bool
+		// ExpiryDate is when the restored copy will be removed
+		ExpiryDate time.Time
+	}
+
+	// Backoff returns the delay before the nth retry, n starts from 0
+	Backoff func(n int) time.Duration
+)
+
+// RestoreObject restores an Archive or Cold Archive object so that it can be
+// read. request can be nil for Archive objects.
+func (a *API) RestoreObject(bucket, object string, request *RestoreRequest) error {
+	var options []Option
+	if request != nil {
+		options = append(options, XMLBody(request))
+	}
+	return a.Do("POST", bucket, object+"?restore", nil, options...)
+}
+
+// RestoreObjectAndWait restores an object and polls HeadObject until the
+// object is readable or ctx is done, backoff can be nil for
+// ExponentialBackoff(time.Second, time.Minute). An ongoing restore started
+// earlier is waited for as well. The headers of the restored object are
+// returned.
+func (a *API) RestoreObjectAndWait(ctx context.Context, bucket, object string, request *RestoreRequest, backoff Backoff) (Header, error) {
+	if backoff == nil {
+		backoff = ExponentialBackoff(time.Second, time.Minute)
+	}
+	if err := a.RestoreObject(bucket, object, request); err != nil && !IsErrorCode(err, "RestoreAlreadyInProgress") {
+		return nil, err
+	}
+	for n := 0; ; n++ {
+		header, err := a.HeadObject(bucket, object)
+		if err != nil {
+			return nil, err
+		}
+		status, err := header.RestoreStatus()
+		if err != nil {
+			return nil, err
+		}
+		if status != nil && !status.Ongoing {
+			return header, nil
+		}
+		timer := time.NewTimer(backoff(n))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// ExponentialBackoff returns a Backoff doubling from initial up to max
+func ExponentialBackoff(initial, max time.Duration) Backoff {
+	return func(n int) time.Duration {
+		d := initial
+		for i := 0; i < n && d < max; i++ {
+			d *= 2
+		}
+		if d > max {
+			d = max
+		}
+		return d
+	}
+}
+
+// RestoreStatus parses X-Oss-Restore header, nil is returned if the object
+// has never been restored
+func (h Header) RestoreStatus() (*RestoreStatus, error) {
+	value := http.Header(h).Get("X-Oss-Restore")
+	if value == "" {
+		return nil, nil
+	}
+	return ParseRestoreStatus(value)
+}
+
+var (
+	rxOngoingRequest = regexp.MustCompile(`ongoing-request="(\w+)"`)
+	rxExpiryDate     = regexp.MustCompile(`expiry-date="([^"]+)"`)
+)
+
+// ParseRestoreStatus parses the value of X-Oss-Restore header, e.g.
+//
+//	ongoing-request="false", expiry-date="Sun, 16 Apr 2017 08:12:33 GMT"
+func ParseRestoreStatus(value string) (*RestoreStatus, error) {
+	m := rxOngoingRequest.FindStringSubmatch(value)
+	if m == nil {
+		return nil, fmt.Errorf("invalid X-Oss-Restore %q", value)
+	}
+	status := &RestoreStatus{Ongoing: m[1] == "true"}
+	if m := rxExpiryDate.FindStringSubmatch(value); m != nil {
+		t, err := time.Parse(gmtTime, m[1])
+		if err != nil {
+			return nil, fmt.Errorf("invalid X-Oss-Restore %q: %v", value, err)
+		}
+		status.ExpiryDate = t
+	}
+	return status, nil
+}
@@ -0,0 +1,48 @@
+package oss_test
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aliyun/aliyun-oss-go-sdk/oss"
+)
+
+func TestRestoreObjectAndWait(t *testing.T) {
+	server := newTestServer(t)
+	defer server.Close()
+	server.Handler.RestoreTime = 10 * time.Millisecond
+	api := server.API()
+	if err := api.PutObject(testBucketName, testObjectName, strings.NewReader("cold"), oss.StorageClass(oss.ColdArchiveStorage)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := api.GetObject(testBucketName, testObjectName, new(bytes.Buffer)); !oss.IsErrorCode(err, "InvalidObjectState") {
+		t.Fatalf(expectBut, "InvalidObjectState", err)
+	}
+	request := &oss.RestoreRequest{Days: 2, JobParameters: &oss.JobParameters{Tier: oss.BulkTier}}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := api.RestoreObjectAndWait(ctx, testBucketName, testObjectName, request, oss.ExponentialBackoff(time.Millisecond, time.Millisecond)); err != context.Canceled {
+		t.Fatalf(expectBut, context.Canceled, err)
+	}
+	// the restore started above is still ongoing
+	header, err := api.RestoreObjectAndWait(context.Background(), testBucketName, testObjectName, request, oss.ExponentialBackoff(time.Millisecond, time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status, _ := header.RestoreStatus(); status == nil || status.Ongoing {
+		t.Fatalf(expectBut, "restored", status)
+	}
+	buf := new(bytes.Buffer)
+	if _, err := api.GetObject(testBucketName, testObjectName, buf); err != nil {
+		t.Fatal(err)
+	}
+	if err := api.PutObject(testBucketName, "hot", strings.NewReader("hot")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := api.RestoreObjectAndWait(context.Background(), testBucketName, "hot", nil, nil); !oss.IsErrorCode(err, "OperationNotSupported") {
+		t.Fatalf(expectBut, "OperationNotSupported", err)
+	}
+}
@@ -0,0 +1,44 @@
+package oss
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseRestoreStatus(t *testing.T) {
+	for _, testcase := range []struct {
+		value    string
+		expected *RestoreStatus
+	}{
+		{`ongoing-request="true"`, &RestoreStatus{Ongoing: true}},
+		{`ongoing-request="false", expiry-date="Sun, 16 Apr 2017 08:12:33 GMT"`, &RestoreStatus{
+			ExpiryDate: parseTime(gmtTime, "Sun, 16 Apr 2017 08:12:33 GMT"),
+		}},
+	} {
+		status, err := ParseRestoreStatus(testcase.value)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(status, testcase.expected) {
+			t.Fatalf(testcaseExpectBut, testcase.value, testcase.expected, status)
+		}
+	}
+	for _, value := range []string{"", `ongoing-request="false", expiry-date="yesterday"`} {
+		if _, err := ParseRestoreStatus(value); err == nil {
+			t.Fatalf(testcaseExpectBut, value, "error", nil)
+		}
+	}
+	if status, err := (Header{}).RestoreStatus(); status != nil || err != nil {
+		t.Fatalf(expectBut, nil, status)
+	}
+}
+
+func TestExponentialBackoff(t *testing.T) {
+	backoff := ExponentialBackoff(time.Second, 5*time.Second)
+	for n, expected := range []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second, 5 * time.Second} {
+		if actual := backoff(n); actual != expected {
+			t.Fatalf(testcaseExpectBut, n, expected, actual)
+		}
+	}
+}
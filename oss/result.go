@@ -4,6 +4,7 @@ import (
 	"encoding/xml"
 	"io"
 	"net/http"
+	"net/url"
 	"strconv"
 	"time"
 )
@@ -40,6 +41,7 @@ type (
 		MaxKeys        int
 		Delimiter      string
 		IsTruncated    bool
+		NextMarker     string
 		Contents       []Content
 		CommonPrefixes []string `xml:"CommonPrefixes>Prefix"`
 	}
@@ -149,7 +151,69 @@ type (
 		PartNumber   int
 		LastModified *time.Time `xml:"LastModified,omitempty"`
 		ETag         string
-		Size         int64 `xml:"Size,omitempty"`
+		Size         int64   `xml:"Size,omitempty"`
+		CRC64        *uint64 `xml:"-"`
+	}
+
+	// CreateLiveChannelResult is returned by PutLiveChannel API
+	CreateLiveChannelResult struct {
+		PublishURLs []string `xml:"PublishUrls>Url"`
+		PlayURLs    []string `xml:"PlayUrls>Url"`
+	}
+
+	// LiveChannelStat is returned by GetLiveChannelStat API, Video and
+	// Audio are nil unless the channel is live
+	LiveChannelStat struct {
+		// Status is LiveChannelLive, LiveChannelIdle or LiveChannelDisabled
+		Status        string
+		ConnectedTime *time.Time
+		RemoteAddr    string
+		Video         *LiveChannelVideo
+		Audio         *LiveChannelAudio
+	}
+	// LiveChannelVideo is the video of a live stream, Bandwidth is in B/s
+	LiveChannelVideo struct {
+		Width     int
+		Height    int
+		FrameRate int
+		Bandwidth int
+		Codec     string
+	}
+	// LiveChannelAudio is the audio of a live stream, Bandwidth is in B/s
+	LiveChannelAudio struct {
+		Bandwidth  int
+		SampleRate int
+		Codec      string
+	}
+
+	// LiveChannelHistory is returned by GetLiveChannelHistory API
+	LiveChannelHistory struct {
+		LiveRecord []LiveRecord
+	}
+	// LiveRecord is a past live stream of a channel
+	LiveRecord struct {
+		StartTime  time.Time
+		EndTime    time.Time
+		RemoteAddr string
+	}
+
+	// ListLiveChannelResult is returned by ListLiveChannel API
+	ListLiveChannelResult struct {
+		Prefix      string
+		Marker      string
+		MaxKeys     int
+		IsTruncated bool
+		NextMarker  string
+		LiveChannel []LiveChannel
+	}
+	// LiveChannel is the information of a channel
+	LiveChannel struct {
+		Name         string
+		Description  string
+		Status       string
+		LastModified time.Time
+		PublishURLs  []string `xml:"PublishUrls>Url"`
+		PlayURLs     []string `xml:"PlayUrls>Url"`
 	}
 )
 
@@ -238,6 +302,31 @@ func (r *RefererConfiguration) Parse(resp *http.Response) error {
 	return xml.NewDecoder(resp.Body).Decode(r)
 }
 
+// Parse implements ResponseParser
+func (r *LiveChannelConfiguration) Parse(resp *http.Response) error {
+	return xml.NewDecoder(resp.Body).Decode(r)
+}
+
+// Parse implements ResponseParser
+func (r *CreateLiveChannelResult) Parse(resp *http.Response) error {
+	return xml.NewDecoder(resp.Body).Decode(r)
+}
+
+// Parse implements ResponseParser
+func (r *LiveChannelStat) Parse(resp *http.Response) error {
+	return xml.NewDecoder(resp.Body).Decode(r)
+}
+
+// Parse implements ResponseParser
+func (r *LiveChannelHistory) Parse(resp *http.Response) error {
+	return xml.NewDecoder(resp.Body).Decode(r)
+}
+
+// Parse implements ResponseParser
+func (r *ListLiveChannelResult) Parse(resp *http.Response) error {
+	return xml.NewDecoder(resp.Body).Decode(r)
+}
+
 // Parse implements ResponseParser
 func (r *CopyPartResult) Parse(resp *http.Response) error {
 	return xml.NewDecoder(resp.Body).Decode(r)
@@ -249,6 +338,17 @@ func (r *Header) Parse(resp *http.Response) error {
 	return nil
 }
 
+// ObjectType returns the value of X-Oss-Object-Type header, a symbolic link
+// is SymlinkObject whereas the other headers are those of the target object
+func (h Header) ObjectType() ObjectType {
+	return ObjectType(http.Header(h).Get("X-Oss-Object-Type"))
+}
+
+// IsSymlink returns whether the headers are returned for a symbolic link
+func (h Header) IsSymlink() bool {
+	return h.ObjectType() == SymlinkObject
+}
+
 func copyHeader(h http.Header) Header {
 	header := make(Header)
 	for k, v := range h {
@@ -274,13 +374,62 @@ func (r *bodyAndHeader) Parse(resp *http.Response) error {
 	return err
 }
 
-// UploadPartResult is the container of the ETag returned by UploadPart API
+// UploadPartResult is the container of the ETag returned by UploadPart API,
+// CRC64 is nil if OSS did not return it
 type UploadPartResult struct {
-	ETag string
+	ETag  string
+	CRC64 *uint64
 }
 
 // Parse implements ResponseParser
 func (r *UploadPartResult) Parse(resp *http.Response) error {
 	r.ETag = resp.Header.Get("ETag")
+	if resp.Header.Get("X-Oss-Hash-Crc64ecma") == "" {
+		return nil
+	}
+	r.CRC64 = new(uint64)
+	return parseCRC64(resp, r.CRC64)
+}
+
+// AppendObjectResult is returned by AppendObjectWithCRC API
+type AppendObjectResult struct {
+	NextPosition AppendPosition
+	CRC64        uint64
+}
+
+// Parse implements ResponseParser
+func (r *AppendObjectResult) Parse(resp *http.Response) error {
+	if err := r.NextPosition.Parse(resp); err != nil {
+		return err
+	}
+	return parseCRC64(resp, &r.CRC64)
+}
+
+func parseCRC64(resp *http.Response, crc *uint64) error {
+	value := resp.Header.Get("X-Oss-Hash-Crc64ecma")
+	if value == "" {
+		return nil
+	}
+	var err error
+	*crc, err = strconv.ParseUint(value, 10, 64)
+	return err
+}
+
+// SymlinkResult is returned by GetSymlink API
+type SymlinkResult struct {
+	Target       string
+	ETag         string
+	LastModified string
+}
+
+// Parse implements ResponseParser
+func (r *SymlinkResult) Parse(resp *http.Response) error {
+	target, err := url.QueryUnescape(resp.Header.Get("X-Oss-Symlink-Target"))
+	if err != nil {
+		return err
+	}
+	r.Target = target
+	r.ETag = resp.Header.Get("ETag")
+	r.LastModified = resp.Header.Get("Last-Modified")
 	return nil
 }
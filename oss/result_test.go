@@ -82,3 +82,18 @@ func TestAppendPosition(t *testing.T) {
 		t.Fatalf(expectBut, "error", err)
 	}
 }
+
+func TestHeaderObjectType(t *testing.T) {
+	h := Header{"X-Oss-Object-Type": []string{"Symlink"}}
+	if !h.IsSymlink() {
+		t.Fatalf(expectBut, SymlinkObject, h.ObjectType())
+	}
+	h = Header{"X-Oss-Object-Type": []string{"Appendable"}}
+	if h.IsSymlink() || h.ObjectType() != AppendableObject {
+		t.Fatalf(expectBut, AppendableObject, h.ObjectType())
+	}
+	var r SymlinkResult
+	if err := r.Parse(&http.Response{Header: http.Header{"X-Oss-Symlink-Target": []string{"%zz"}}}); err == nil {
+		t.Fatal("expect error but got nil")
+	}
+}
@@ -0,0 +1,423 @@
+package oss
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"net/http"
+	"strconv"
+)
+
+// Values of SelectRequest fields
+const (
+	// CompressionType of the object
+	CompressionNone = "None"
+	CompressionGzip = "GZIP"
+
+	// FileHeaderInfo of a CSV object: the first line is not a header, a
+	// header to skip, or a header naming the columns in the SQL expression
+	CSVHeaderNone   = "NONE"
+	CSVHeaderIgnore = "IGNORE"
+	CSVHeaderUse    = "USE"
+
+	// Type of a JSON object: a single document or one document per line
+	JSONDocument = "DOCUMENT"
+	JSONLines    = "LINES"
+)
+
+// Types of the frames returned by SelectObject and CreateSelectObjectMeta
+const (
+	selectDataFrame       = 0x800001
+	selectContinuousFrame = 0x800004
+	selectEndFrame        = 0x800005
+	selectCSVMetaEndFrame = 0x800006
+	selectJSONMetaFrame   = 0x800007
+)
+
+var (
+	// ErrSelectChecksum happens when the CRC-32 of a select frame payload
+	// does not match its checksum
+	ErrSelectChecksum = errors.New("select frame checksum mismatch")
+	// ErrSelectFrame happens when a select response is not a valid frame
+	// stream
+	ErrSelectFrame = errors.New("invalid select frame")
+)
+
+type (
+	// SelectRequest is the input for SelectObject API. The expression and
+	// the delimiter and quote characters are plain strings, they are
+	// base64-encoded when sent.
+	SelectRequest struct {
+		// Expression is the SQL statement, e.g. "select * from ossobject"
+		Expression          string
+		InputSerialization  InputSerialization
+		OutputSerialization OutputSerialization
+		Options             *SelectOptions `xml:"Options,omitempty"`
+	}
+	// InputSerialization describes the format of the object, either CSV or
+	// JSON is set
+	InputSerialization struct {
+		CompressionType string     `xml:"CompressionType,omitempty"`
+		CSV             *CSVInput  `xml:"CSV,omitempty"`
+		JSON            *JSONInput `xml:"JSON,omitempty"`
+	}
+	// CSVInput is the format of a CSV object
+	CSVInput struct {
+		FileHeaderInfo   string `xml:"FileHeaderInfo,omitempty"`
+		RecordDelimiter  string `xml:"RecordDelimiter,omitempty"`
+		FieldDelimiter   string `xml:"FieldDelimiter,omitempty"`
+		QuoteCharacter   string `xml:"QuoteCharacter,omitempty"`
+		CommentCharacter string `xml:"CommentCharacter,omitempty"`
+		// Range is a LineRange or SplitRange, which requires the meta
+		// created by CreateSelectObjectMeta
+		Range                      string `xml:"Range,omitempty"`
+		AllowQuotedRecordDelimiter bool   `xml:"AllowQuotedRecordDelimiter,omitempty"`
+	}
+	// JSONInput is the format of a JSON object
+	JSONInput struct {
+		Type                    string
+		Range                   string `xml:"Range,omitempty"`
+		ParseJSONNumberAsString bool   `xml:"ParseJsonNumberAsString,omitempty"`
+	}
+	// OutputSerialization describes the format of the result
+	OutputSerialization struct {
+		CSV            *CSVOutput  `xml:"CSV,omitempty"`
+		JSON           *JSONOutput `xml:"JSON,omitempty"`
+		KeepAllColumns bool        `xml:"KeepAllColumns,omitempty"`
+		// OutputRawData returns the result without frames, so that errors
+		// after the response header are not reported
+		OutputRawData bool `xml:"OutputRawData,omitempty"`
+		// EnablePayloadCrc asks OSS for the CRC-32 of the frames, which
+		// are then verified
+		EnablePayloadCrc bool `xml:"EnablePayloadCrc,omitempty"`
+		OutputHeader     bool `xml:"OutputHeader,omitempty"`
+	}
+	// CSVOutput is the format of a CSV result
+	CSVOutput struct {
+		RecordDelimiter string `xml:"RecordDelimiter,omitempty"`
+		FieldDelimiter  string `xml:"FieldDelimiter,omitempty"`
+	}
+	// JSONOutput is the format of a JSON result
+	JSONOutput struct {
+		RecordDelimiter string `xml:"RecordDelimiter,omitempty"`
+	}
+	// SelectOptions tolerates malformed records
+	SelectOptions struct {
+		SkipPartialDataRecord    bool `xml:"SkipPartialDataRecord,omitempty"`
+		MaxSkippedRecordsAllowed int  `xml:"MaxSkippedRecordsAllowed,omitempty"`
+	}
+
+	// SelectMetaRequest is the input for CreateSelectObjectMeta API, only
+	// the delimiters and quote character of CSV and the type of JSON are
+	// used
+	SelectMetaRequest struct {
+		InputSerialization InputSerialization
+		OverwriteIfExists  bool `xml:"OverwriteIfExists,omitempty"`
+	}
+	// SelectMeta is returned by CreateSelectObjectMeta API
+	SelectMeta struct {
+		ScannedBytes int64
+		Splits       int
+		Rows         int64
+		// Columns is only counted for CSV objects
+		Columns int
+	}
+
+	// SelectError is the error reported by the end frame of a select
+	// response, after the response header has been sent
+	SelectError struct {
+		StatusCode int
+		Message    string
+	}
+
+	// SelectObjectReader streams the result of SelectObject, it must be
+	// closed by the caller
+	SelectObjectReader struct {
+		// ScannedBytes is the number of bytes of the object scanned so far
+		ScannedBytes int64
+		body         io.ReadCloser
+		done         func(err error) error
+		raw          bool
+		verify       bool
+		data         []byte
+		err          error
+	}
+
+	// selectFrame is a decoded frame
+	selectFrame struct {
+		typ     uint32
+		payload []byte
+	}
+)
+
+// LineRange is the Range of the lines from first to last of a CSV or JSON
+// lines object, last < 0 means the last line
+func LineRange(first, last int64) string {
+	return "line-range=" + selectRange(first, last)
+}
+
+// SplitRange is the Range of the splits from first to last of a CSV or JSON
+// lines object, last < 0 means the last split
+func SplitRange(first, last int64) string {
+	return "split-range=" + selectRange(first, last)
+}
+
+func selectRange(first, last int64) string {
+	if last < 0 {
+		return strconv.FormatInt(first, 10) + "-"
+	}
+	return strconv.FormatInt(first, 10) + "-" + strconv.FormatInt(last, 10)
+}
+
+// SelectObject runs the SQL expression of request on a CSV or JSON object,
+// the result is streamed by the returned reader. An error reported by OSS
+// after the response header is returned by Read as a *SelectError.
+func (a *API) SelectObject(bucket, object string, request *SelectRequest, options ...Option) (*SelectObjectReader, error) {
+	process := "csv/select"
+	if request.InputSerialization.JSON != nil {
+		process = "json/select"
+	}
+	res := &SelectObjectReader{raw: request.OutputSerialization.OutputRawData, verify: request.OutputSerialization.EnablePayloadCrc}
+	if err := a.Do("POST", bucket, object+"?x-oss-process="+process, res, append([]Option{XMLBody(request)}, options...)...); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// CreateSelectObjectMeta scans a CSV or JSON lines object to count its
+// lines and splits, which allows SelectObject with a LineRange or
+// SplitRange
+func (a *API) CreateSelectObjectMeta(bucket, object string, request *SelectMetaRequest) (res *SelectMeta, _ error) {
+	process := "csv/meta"
+	if request.InputSerialization.JSON != nil {
+		process = "json/meta"
+	}
+	return res, a.Do("POST", bucket, object+"?x-oss-process="+process, &res, XMLBody(request))
+}
+
+// MarshalXML implements xml.Marshaler, encoding the expression and the
+// characters in base64
+func (r *SelectRequest) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	type selectRequest SelectRequest
+	encoded := selectRequest(*r)
+	encoded.Expression = base64String(r.Expression)
+	encoded.InputSerialization = r.InputSerialization.encode()
+	if csv := r.OutputSerialization.CSV; csv != nil {
+		encoded.OutputSerialization.CSV = &CSVOutput{
+			RecordDelimiter: base64String(csv.RecordDelimiter),
+			FieldDelimiter:  base64String(csv.FieldDelimiter),
+		}
+	}
+	if json := r.OutputSerialization.JSON; json != nil {
+		encoded.OutputSerialization.JSON = &JSONOutput{RecordDelimiter: base64String(json.RecordDelimiter)}
+	}
+	start.Name = xml.Name{Local: "SelectRequest"}
+	return e.EncodeElement(&encoded, start)
+}
+
+// MarshalXML implements xml.Marshaler, encoding the characters in base64
+func (r *SelectMetaRequest) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	type selectMetaRequest SelectMetaRequest
+	encoded := selectMetaRequest(*r)
+	encoded.InputSerialization = r.InputSerialization.encode()
+	start.Name = xml.Name{Local: "CsvMetaRequest"}
+	if r.InputSerialization.JSON != nil {
+		start.Name.Local = "JsonMetaRequest"
+	}
+	return e.EncodeElement(&encoded, start)
+}
+
+func (s InputSerialization) encode() InputSerialization {
+	if csv := s.CSV; csv != nil {
+		encoded := *csv
+		encoded.RecordDelimiter = base64String(csv.RecordDelimiter)
+		encoded.FieldDelimiter = base64String(csv.FieldDelimiter)
+		encoded.QuoteCharacter = base64String(csv.QuoteCharacter)
+		encoded.CommentCharacter = base64String(csv.CommentCharacter)
+		s.CSV = &encoded
+	}
+	return s
+}
+
+func base64String(s string) string {
+	return base64.StdEncoding.EncodeToString([]byte(s))
+}
+
+func (r *SelectObjectReader) parseStream(resp *http.Response, done func(err error) error) error {
+	if resp.StatusCode == http.StatusNotModified {
+		defer resp.Body.Close()
+		return done(parseError(resp))
+	}
+	if resp.Header.Get("X-Oss-Select-Output-Raw") == "true" {
+		r.raw = true
+	}
+	r.body, r.done = resp.Body, done
+	return nil
+}
+
+// Read implements io.Reader, returning the data of the result
+func (r *SelectObjectReader) Read(p []byte) (int, error) {
+	if r.raw {
+		if r.err != nil {
+			return 0, r.err
+		}
+		n, err := r.body.Read(p)
+		if err != nil {
+			r.finish(err)
+			err = r.err
+		}
+		return n, err
+	}
+	for len(r.data) == 0 && r.err == nil {
+		r.next()
+	}
+	if len(r.data) == 0 {
+		return 0, r.err
+	}
+	n := copy(p, r.data)
+	r.data = r.data[n:]
+	return n, nil
+}
+
+// next reads a frame, keeping its data or setting err
+func (r *SelectObjectReader) next() {
+	frame, err := readSelectFrame(r.body, r.verify)
+	if err != nil {
+		if err == io.EOF {
+			// the end frame is missing
+			err = io.ErrUnexpectedEOF
+		}
+		r.finish(err)
+		return
+	}
+	switch frame.typ {
+	case selectDataFrame:
+		if len(frame.payload) < 8 {
+			r.finish(ErrSelectFrame)
+			return
+		}
+		r.ScannedBytes = int64(binary.BigEndian.Uint64(frame.payload))
+		r.data = frame.payload[8:]
+	case selectContinuousFrame:
+		if len(frame.payload) >= 8 {
+			r.ScannedBytes = int64(binary.BigEndian.Uint64(frame.payload))
+		}
+	case selectEndFrame:
+		if len(frame.payload) < 20 {
+			r.finish(ErrSelectFrame)
+			return
+		}
+		r.ScannedBytes = int64(binary.BigEndian.Uint64(frame.payload[8:]))
+		if status := int(binary.BigEndian.Uint32(frame.payload[16:])); status >= 400 {
+			r.finish(&SelectError{StatusCode: status, Message: string(frame.payload[20:])})
+			return
+		}
+		r.finish(io.EOF)
+	default:
+		r.finish(ErrSelectFrame)
+	}
+}
+
+// finish sets the error returned after the data and reports the end of the
+// response to API.Do
+func (r *SelectObjectReader) finish(err error) {
+	if r.done == nil {
+		r.err = err
+		return
+	}
+	done := r.done
+	r.done = nil
+	if err == io.EOF {
+		if doneErr := done(nil); doneErr != nil {
+			err = doneErr
+		}
+	} else {
+		done(err)
+	}
+	r.err = err
+}
+
+// Close implements io.Closer
+func (r *SelectObjectReader) Close() error {
+	if r.err == nil {
+		r.finish(io.ErrUnexpectedEOF)
+	}
+	return r.body.Close()
+}
+
+// Parse implements ResponseParser
+func (m *SelectMeta) Parse(resp *http.Response) error {
+	for {
+		frame, err := readSelectFrame(resp.Body, false)
+		if err == io.EOF {
+			return io.ErrUnexpectedEOF
+		} else if err != nil {
+			return err
+		}
+		switch frame.typ {
+		case selectContinuousFrame:
+			continue
+		case selectCSVMetaEndFrame, selectJSONMetaFrame:
+		default:
+			return ErrSelectFrame
+		}
+		p := frame.payload
+		// offset, scanned bytes, status, splits, rows and columns for CSV, as
+		// in the end frame of the data
+		size := 32
+		if frame.typ == selectCSVMetaEndFrame {
+			size = 36
+		}
+		if len(p) < size {
+			return ErrSelectFrame
+		}
+		if status := int(binary.BigEndian.Uint32(p[16:])); status >= 400 {
+			return &SelectError{StatusCode: status, Message: string(p[size:])}
+		}
+		m.ScannedBytes = int64(binary.BigEndian.Uint64(p[8:]))
+		m.Splits = int(binary.BigEndian.Uint32(p[20:]))
+		m.Rows = int64(binary.BigEndian.Uint64(p[24:]))
+		if frame.typ == selectCSVMetaEndFrame {
+			m.Columns = int(binary.BigEndian.Uint32(p[32:]))
+		}
+		return nil
+	}
+}
+
+// readSelectFrame reads a frame: a version byte, a 3-byte type, a 4-byte
+// payload length, a 4-byte header checksum, the payload and its CRC-32. The
+// CRC-32 is verified if verify is true or it is not 0.
+func readSelectFrame(r io.Reader, verify bool) (*selectFrame, error) {
+	var header [12]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		if err == io.ErrUnexpectedEOF {
+			return nil, ErrSelectFrame
+		}
+		return nil, err
+	}
+	typ := binary.BigEndian.Uint32(header[:4]) & 0xffffff
+	if header[0] != 1 {
+		return nil, ErrSelectFrame
+	}
+	payload := make([]byte, binary.BigEndian.Uint32(header[4:])+4)
+	if _, err := io.ReadFull(r, payload); err != nil {
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return nil, ErrSelectFrame
+		}
+		return nil, err
+	}
+	sum := binary.BigEndian.Uint32(payload[len(payload)-4:])
+	payload = payload[:len(payload)-4]
+	if (verify || sum != 0) && crc32.ChecksumIEEE(payload) != sum {
+		return nil, ErrSelectChecksum
+	}
+	return &selectFrame{typ: typ, payload: payload}, nil
+}
+
+func (e *SelectError) Error() string {
+	return fmt.Sprintf("select failed (%d %s): %s", e.StatusCode, http.StatusText(e.StatusCode), e.Message)
+}
@@ -0,0 +1,170 @@
+package oss
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/xml"
+	"hash/crc32"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// selectFrameBytes encodes a select frame with its CRC-32
+func selectFrameBytes(typ uint32, payload []byte) []byte {
+	frame := make([]byte, 12, 16+len(payload))
+	binary.BigEndian.PutUint32(frame, 1<<24|typ)
+	binary.BigEndian.PutUint32(frame[4:], uint32(len(payload)))
+	frame = append(frame, payload...)
+	return binary.BigEndian.AppendUint32(frame, crc32.ChecksumIEEE(payload))
+}
+
+func selectDataBytes(offset uint64, data string) []byte {
+	return selectFrameBytes(selectDataFrame, append(binary.BigEndian.AppendUint64(nil, offset), data...))
+}
+
+func selectEndBytes(scanned uint64, status uint32, message string) []byte {
+	payload := binary.BigEndian.AppendUint64(nil, scanned)
+	payload = binary.BigEndian.AppendUint64(payload, scanned)
+	payload = binary.BigEndian.AppendUint32(payload, status)
+	return selectFrameBytes(selectEndFrame, append(payload, message...))
+}
+
+func TestSelectRequestXML(t *testing.T) {
+	request := &SelectRequest{
+		Expression: "select * from ossobject",
+		InputSerialization: InputSerialization{
+			CompressionType: CompressionNone,
+			CSV:             &CSVInput{FileHeaderInfo: CSVHeaderUse, FieldDelimiter: ",", Range: LineRange(10, -1)},
+		},
+		OutputSerialization: OutputSerialization{CSV: &CSVOutput{RecordDelimiter: "\n"}, EnablePayloadCrc: true},
+	}
+	buf, err := xml.Marshal(request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := "<SelectRequest><Expression>" + base64.StdEncoding.EncodeToString([]byte(request.Expression)) + "</Expression>" +
+		"<InputSerialization><CompressionType>None</CompressionType><CSV><FileHeaderInfo>USE</FileHeaderInfo><FieldDelimiter>LA==</FieldDelimiter><Range>line-range=10-</Range></CSV></InputSerialization>" +
+		"<OutputSerialization><CSV><RecordDelimiter>Cg==</RecordDelimiter></CSV><EnablePayloadCrc>true</EnablePayloadCrc></OutputSerialization></SelectRequest>"
+	if string(buf) != expected {
+		t.Fatalf(expectBut, expected, string(buf))
+	}
+	if request.InputSerialization.CSV.FieldDelimiter != "," {
+		t.Fatalf(expectBut, ",", request.InputSerialization.CSV.FieldDelimiter)
+	}
+	buf, err = xml.Marshal(&SelectMetaRequest{InputSerialization: InputSerialization{JSON: &JSONInput{Type: JSONLines}}})
+	if expected := "<JsonMetaRequest><InputSerialization><JSON><Type>LINES</Type></JSON></InputSerialization></JsonMetaRequest>"; err != nil || string(buf) != expected {
+		t.Fatalf(expectBut, expected, []interface{}{string(buf), err})
+	}
+}
+
+func TestSelectObject(t *testing.T) {
+	var body []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		switch req.URL.Path {
+		case "/bucket/raw":
+			w.Header().Set("X-Oss-Select-Output-Raw", "true")
+		case "/bucket/missing":
+			w.WriteHeader(http.StatusNotFound)
+			io.WriteString(w, "<Error><Code>NoSuchKey</Code></Error>")
+			return
+		}
+		if req.URL.Query().Get("x-oss-process") != "json/select" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.WriteHeader(http.StatusPartialContent)
+		w.Write(body)
+	}))
+	defer server.Close()
+	api := New(strings.TrimPrefix(server.URL, "http://"), testID, testSecret)
+	request := &SelectRequest{
+		Expression:          "select * from ossobject s where s.a > 1",
+		InputSerialization:  InputSerialization{JSON: &JSONInput{Type: JSONLines}},
+		OutputSerialization: OutputSerialization{EnablePayloadCrc: true},
+	}
+	continuous := selectFrameBytes(selectContinuousFrame, binary.BigEndian.AppendUint64(nil, 5))
+	corrupted := selectDataBytes(20, "{}\n")
+	corrupted[len(corrupted)-1] ^= 1
+	for _, testcase := range []struct {
+		object string
+		body   [][]byte
+		data   string
+		err    error
+	}{
+		{"object", [][]byte{selectDataBytes(10, `{"a":2}`+"\n"), continuous, selectDataBytes(30, `{"a":3}`+"\n"), selectEndBytes(40, 206, "")}, `{"a":2}` + "\n" + `{"a":3}` + "\n", nil},
+		{"object", [][]byte{selectDataBytes(10, "{}\n"), selectEndBytes(20, 400, "InvalidJsonData")}, "{}\n", &SelectError{StatusCode: 400, Message: "InvalidJsonData"}},
+		{"object", [][]byte{selectDataBytes(10, "{}\n")}, "{}\n", io.ErrUnexpectedEOF},
+		{"object", [][]byte{corrupted}, "", ErrSelectChecksum},
+		{"object", [][]byte{selectDataBytes(10, "{}\n")[:10]}, "", ErrSelectFrame},
+		{"raw", [][]byte{[]byte("raw data")}, "raw data", nil},
+	} {
+		body = nil
+		for _, frame := range testcase.body {
+			body = append(body, frame...)
+		}
+		reader, err := api.SelectObject("bucket", testcase.object, request)
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, err := ioutil.ReadAll(reader)
+		reader.Close()
+		if string(data) != testcase.data || !equalError(err, testcase.err) {
+			t.Fatalf(testcaseExpectBut, testcase.body, []interface{}{testcase.data, testcase.err}, []interface{}{string(data), err})
+		}
+		if testcase.err == nil && testcase.object == "object" && reader.ScannedBytes != 40 {
+			t.Fatalf(expectBut, 40, reader.ScannedBytes)
+		}
+	}
+	if _, err := api.SelectObject("bucket", "missing", request); !IsErrorCode(err, "NoSuchKey") {
+		t.Fatalf(expectBut, "NoSuchKey", err)
+	}
+}
+
+func equalError(a, b error) bool {
+	if ea, ok := a.(*SelectError); ok {
+		eb, ok := b.(*SelectError)
+		return ok && *ea == *eb
+	}
+	return a == b
+}
+
+func TestCreateSelectObjectMeta(t *testing.T) {
+	// offset, scanned bytes, status, splits, rows and columns
+	metaPayload := func(status uint32, message string) []byte {
+		payload := binary.BigEndian.AppendUint64(nil, 100)
+		payload = binary.BigEndian.AppendUint64(payload, 1000)
+		payload = binary.BigEndian.AppendUint32(payload, status)
+		payload = binary.BigEndian.AppendUint32(payload, 2)
+		payload = binary.BigEndian.AppendUint64(payload, 10)
+		payload = binary.BigEndian.AppendUint32(payload, 3)
+		return append(payload, message...)
+	}
+	var payload []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.URL.Query().Get("x-oss-process") != "csv/meta" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.Write(selectFrameBytes(selectContinuousFrame, binary.BigEndian.AppendUint64(nil, 50)))
+		w.Write(selectFrameBytes(selectCSVMetaEndFrame, payload))
+	}))
+	defer server.Close()
+	api := New(strings.TrimPrefix(server.URL, "http://"), testID, testSecret)
+	request := &SelectMetaRequest{InputSerialization: InputSerialization{CSV: &CSVInput{}}}
+
+	payload = metaPayload(200, "")
+	meta, err := api.CreateSelectObjectMeta("bucket", "object", request)
+	if expected := (SelectMeta{ScannedBytes: 1000, Splits: 2, Rows: 10, Columns: 3}); err != nil || *meta != expected {
+		t.Fatalf(expectBut, expected, []interface{}{meta, err})
+	}
+
+	payload = metaPayload(400, "InvalidCsvLine")
+	_, err = api.CreateSelectObjectMeta("bucket", "object", request)
+	if expected := (&SelectError{StatusCode: 400, Message: "InvalidCsvLine"}); !equalError(err, expected) {
+		t.Fatalf(expectBut, expected, err)
+	}
+}
@@ -0,0 +1,125 @@
+package oss
+
+import (
+	"encoding/xml"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// ErrInvalidImageProcess is returned when an image style is not an image
+// process, i.e. "image/" followed by the operations
+var ErrInvalidImageProcess = errors.New("invalid image process")
+
+type (
+	// Style is a named image process of a bucket
+	Style struct {
+		Name string
+		// Content is the image process, e.g. "image/resize,w_100"
+		Content        string
+		CreateTime     time.Time
+		LastModifyTime time.Time
+	}
+
+	// StyleList is returned by ListStyle API
+	StyleList struct {
+		Style []Style
+	}
+
+	styleContent struct {
+		XMLName xml.Name `xml:"Style"`
+		Content string
+	}
+)
+
+// PutStyle creates or replaces an image style of a bucket
+func (a *API) PutStyle(bucket, name string, process *ImageProcess) error {
+	return a.Do("PUT", bucket, styleResource(name), nil, XMLBody(&styleContent{Content: process.String()}))
+}
+
+// GetStyle returns an image style of a bucket
+func (a *API) GetStyle(bucket, name string) (res *Style, _ error) {
+	return res, a.Do("GET", bucket, styleResource(name), &res)
+}
+
+// ListStyle returns all the image styles of a bucket
+func (a *API) ListStyle(bucket string) (res *StyleList, _ error) {
+	return res, a.Do("GET", bucket, "?style", &res)
+}
+
+// DeleteStyle deletes an image style of a bucket
+func (a *API) DeleteStyle(bucket, name string) error {
+	return a.Do("DELETE", bucket, styleResource(name), nil)
+}
+
+func styleResource(name string) string {
+	return "?style&styleName=" + url.QueryEscape(name)
+}
+
+// ImageStyle is an option to process an image object with a style of its
+// bucket, e.g. for GetObject or SignURL
+func ImageStyle(name string) Option {
+	return Process("style/" + name)
+}
+
+// ParseImageProcess parses the value of x-oss-process or the content of a
+// style into an ImageProcess, so that more operations can be added
+func ParseImageProcess(value string) (*ImageProcess, error) {
+	if value == "image" {
+		return NewImageProcess(), nil
+	}
+	if !strings.HasPrefix(value, "image/") {
+		return nil, ErrInvalidImageProcess
+	}
+	operations := strings.Split(strings.TrimPrefix(value, "image/"), "/")
+	for _, operation := range operations {
+		if operation == "" {
+			return nil, ErrInvalidImageProcess
+		}
+	}
+	return &ImageProcess{operations: operations}, nil
+}
+
+// Process returns the content of the style as an ImageProcess
+func (s *Style) Process() (*ImageProcess, error) {
+	return ParseImageProcess(s.Content)
+}
+
+// UnmarshalXML implements xml.Unmarshaler, the times of a style are in the
+// format of HTTP headers
+func (s *Style) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	var v struct {
+		Name           string
+		Content        string
+		CreateTime     string
+		LastModifyTime string
+	}
+	if err := d.DecodeElement(&v, &start); err != nil {
+		return err
+	}
+	*s = Style{Name: v.Name, Content: v.Content}
+	var err error
+	if v.CreateTime != "" {
+		if s.CreateTime, err = time.Parse(gmtTime, v.CreateTime); err != nil {
+			return err
+		}
+	}
+	if v.LastModifyTime != "" {
+		if s.LastModifyTime, err = time.Parse(gmtTime, v.LastModifyTime); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Parse implements ResponseParser
+func (r *Style) Parse(resp *http.Response) error {
+	return xml.NewDecoder(resp.Body).Decode(r)
+}
+
+// Parse implements ResponseParser
+func (r *StyleList) Parse(resp *http.Response) error {
+	return xml.NewDecoder(resp.Body).Decode(r)
+}
@@ -0,0 +1,164 @@
+package oss
+
+import (
+	"net/http"
+	"testing"
+)
+
+var styleTestcases = []testcase{
+	{
+		name: "PutStyle",
+		request: func(a *API) (interface{}, error) {
+			return nil, a.PutStyle(testBucketName, "small", NewImageProcess().Resize(ResizeOptions{Percentage: 50}))
+		},
+		expectedRequest: `PUT /?style&styleName=small HTTP/1.1
+Host: bucket-name.oss-cn-hangzhou.aliyuncs.com
+User-Agent: %s
+Content-Length: 51
+Accept-Encoding: identity
+Authorization: OSS ayahghai0juiSie:uoxEUjPUA5fmxx3REHs2bEQ7TLQ=
+Date: %s
+
+<Style><Content>image/resize,p_50</Content></Style>`,
+		response: `HTTP/1.1 200 OK
+x-oss-request-id: 5C1B138A109F4E405B2D8AEF
+Date: Wed, 20 May 2020 12:07:15 GMT
+Content-Length: 0
+Connection: close
+Server: AliyunOSS
+`,
+		expectedResponse: nil,
+	},
+
+	{
+		name: "GetStyle",
+		request: func(a *API) (interface{}, error) {
+			r, err := a.GetStyle(testBucketName, "small")
+			return r, err
+		},
+		expectedRequest: `GET /?style&styleName=small HTTP/1.1
+Host: bucket-name.oss-cn-hangzhou.aliyuncs.com
+User-Agent: %s
+Accept-Encoding: identity
+Authorization: OSS ayahghai0juiSie:RZ2kdLWHDZecPviL3t4vIPEjuwQ=
+Date: %s`,
+		response: `HTTP/1.1 200 OK
+x-oss-request-id: 5C1B138A109F4E405B2D8AEF
+Date: Wed, 20 May 2020 12:07:15 GMT
+Content-Type: application/xml
+Content-Length: 233
+Connection: close
+Server: AliyunOSS
+
+<?xml version="1.0" encoding="UTF-8"?>
+<Style>
+ <Name>small</Name>
+ <Content>image/resize,p_50</Content>
+ <CreateTime>Wed, 20 May 2020 12:07:15 GMT</CreateTime>
+ <LastModifyTime>Wed, 21 May 2020 12:07:15 GMT</LastModifyTime>
+</Style>`,
+		expectedResponse: &Style{
+			Name:           "small",
+			Content:        "image/resize,p_50",
+			CreateTime:     parseTime(gmtTime, "Wed, 20 May 2020 12:07:15 GMT"),
+			LastModifyTime: parseTime(gmtTime, "Wed, 21 May 2020 12:07:15 GMT"),
+		},
+	},
+
+	{
+		name: "ListStyle",
+		request: func(a *API) (interface{}, error) {
+			r, err := a.ListStyle(testBucketName)
+			return r, err
+		},
+		expectedRequest: `GET /?style HTTP/1.1
+Host: bucket-name.oss-cn-hangzhou.aliyuncs.com
+User-Agent: %s
+Accept-Encoding: identity
+Authorization: OSS ayahghai0juiSie:EkMklXCmn+ejNo8uQA/9zXCu2rI=
+Date: %s`,
+		response: `HTTP/1.1 200 OK
+x-oss-request-id: 5C1B138A109F4E405B2D8AEF
+Date: Wed, 20 May 2020 12:07:15 GMT
+Content-Type: application/xml
+Content-Length: 234
+Connection: close
+Server: AliyunOSS
+
+<?xml version="1.0" encoding="UTF-8"?>
+<StyleList>
+ <Style>
+  <Name>small</Name>
+  <Content>image/resize,p_50</Content>
+ </Style>
+ <Style>
+  <Name>thumb</Name>
+  <Content>image/resize,w_100/format,webp</Content>
+ </Style>
+</StyleList>`,
+		expectedResponse: &StyleList{
+			Style: []Style{
+				{Name: "small", Content: "image/resize,p_50"},
+				{Name: "thumb", Content: "image/resize,w_100/format,webp"},
+			},
+		},
+	},
+
+	{
+		name: "DeleteStyle",
+		request: func(a *API) (interface{}, error) {
+			return nil, a.DeleteStyle(testBucketName, "small")
+		},
+		expectedRequest: `DELETE /?style&styleName=small HTTP/1.1
+Host: bucket-name.oss-cn-hangzhou.aliyuncs.com
+User-Agent: %s
+Accept-Encoding: identity
+Authorization: OSS ayahghai0juiSie:JtWzWo7LtiCAGGbw/jJevG5sUSM=
+Date: %s`,
+		response: `HTTP/1.1 204 No Content
+x-oss-request-id: 5C1B138A109F4E405B2D8AEF
+Date: Wed, 20 May 2020 12:07:15 GMT
+Content-Length: 0
+Connection: close
+Server: AliyunOSS
+`,
+		expectedResponse: nil,
+	},
+}
+
+func TestStyleAPI(t *testing.T) {
+	for i := range styleTestcases {
+		testAPI(t, &styleTestcases[i])
+	}
+}
+
+func TestParseImageProcess(t *testing.T) {
+	for _, value := range []string{"image", "image/resize,w_100", "image/resize,w_100/format,webp"} {
+		process, err := ParseImageProcess(value)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if actual := process.String(); actual != value {
+			t.Fatalf(expectBut, value, actual)
+		}
+	}
+	process, _ := (&Style{Content: "image/resize,w_100"}).Process()
+	if expected, actual := "image/resize,w_100/format,webp", process.Format("webp").String(); actual != expected {
+		t.Fatalf(expectBut, expected, actual)
+	}
+	for _, value := range []string{"", "style/small", "image/", "image/resize,w_100//format,webp"} {
+		if _, err := ParseImageProcess(value); err != ErrInvalidImageProcess {
+			t.Fatalf(testcaseExpectBut, value, ErrInvalidImageProcess, err)
+		}
+	}
+}
+
+func TestImageStyle(t *testing.T) {
+	req, _ := http.NewRequest("GET", "http://bucket.oss-cn-hangzhou.aliyuncs.com/a.jpg", nil)
+	if err := ImageStyle("small")(req); err != nil {
+		t.Fatal(err)
+	}
+	if expected := "x-oss-process=style/small"; req.URL.RawQuery != expected {
+		t.Fatalf(expectBut, expected, req.URL.RawQuery)
+	}
+}
@@ -0,0 +1,207 @@
+package oss
+
+import (
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// Values of IndexDocument.Type, for a path without "/" which is not an
+// object when SupportSubDir is set
+const (
+	// WebsiteSubDirRedirect redirects to the path with "/" if its index
+	// document exists
+	WebsiteSubDirRedirect = 0
+	// WebsiteSubDirNotFound returns the error document
+	WebsiteSubDirNotFound = 1
+	// WebsiteSubDirIndex serves the index document of the path with "/"
+	WebsiteSubDirIndex = 2
+)
+
+// Values of RoutingRuleRedirect.RedirectType
+const (
+	// RedirectMirror fetches the missing objects from MirrorURL
+	RedirectMirror = "Mirror"
+	// RedirectExternal redirects to HostName
+	RedirectExternal = "External"
+	// RedirectAliCDN redirects to HostName through Alibaba Cloud CDN
+	RedirectAliCDN = "AliCDN"
+)
+
+// Kinds of a WebsiteResolution
+const (
+	// WebsiteServe serves an object, or an empty 404 if Key is empty
+	WebsiteServe = "Serve"
+	// WebsiteRedirect redirects to Location
+	WebsiteRedirect = "Redirect"
+	// WebsiteMirror fetches Key from the origin at Location
+	WebsiteMirror = "Mirror"
+)
+
+// WebsiteResolution is how a website answers a request, returned by
+// WebsiteConfiguration.Resolve
+type WebsiteResolution struct {
+	// Kind is WebsiteServe, WebsiteRedirect or WebsiteMirror
+	Kind string
+	// Key is the object served or mirrored
+	Key string
+	// StatusCode is the status of the response, 0 for WebsiteMirror
+	StatusCode int
+	// Location is the URL redirected to or fetched from the origin
+	Location string
+	// Header are the request headers passed to the origin by WebsiteMirror
+	Header http.Header
+	// RuleNumber is the routing rule applied, 0 if there is none
+	RuleNumber int
+}
+
+// Resolve returns how a bucket with the configuration as website answers a
+// request, without calling OSS. The objects existing are reported by exists.
+// The routing rules are matched in the order of their RuleNumber, those with
+// HTTPErrorCodeReturnedEquals only if the object is not found. The error
+// document is served if no rule matches a missing object.
+func (c *WebsiteConfiguration) Resolve(req *http.Request, exists func(key string) bool) *WebsiteResolution {
+	key := strings.TrimPrefix(req.URL.Path, "/")
+	found := c.lookup(key, exists)
+	var rules []RoutingRule
+	if c.RoutingRules != nil {
+		rules = append(rules, c.RoutingRules.RoutingRule...)
+	}
+	sort.SliceStable(rules, func(i, j int) bool { return rules[i].RuleNumber < rules[j].RuleNumber })
+	for i := range rules {
+		rule := &rules[i]
+		if code := rule.Condition.HTTPErrorCodeReturnedEquals; code != 0 && (found != nil || code != http.StatusNotFound) {
+			continue
+		}
+		if rule.Condition.matches(key, req.Header) {
+			return rule.resolve(key, req)
+		}
+	}
+	if found != nil {
+		return found
+	}
+	if c.ErrorDocument.Key != "" && exists(c.ErrorDocument.Key) {
+		status := c.ErrorDocument.HTTPStatus
+		if status == 0 {
+			status = http.StatusNotFound
+		}
+		return &WebsiteResolution{Kind: WebsiteServe, Key: c.ErrorDocument.Key, StatusCode: status}
+	}
+	return &WebsiteResolution{Kind: WebsiteServe, StatusCode: http.StatusNotFound}
+}
+
+// lookup returns the resolution of a key without the routing rules, or nil
+// if it is not found
+func (c *WebsiteConfiguration) lookup(key string, exists func(key string) bool) *WebsiteResolution {
+	index := c.IndexDocument
+	serve := func(key string) *WebsiteResolution {
+		return &WebsiteResolution{Kind: WebsiteServe, Key: key, StatusCode: http.StatusOK}
+	}
+	if key == "" || strings.HasSuffix(key, "/") {
+		if !index.SupportSubDir {
+			key = ""
+		}
+		if key += index.Suffix; key != "" && exists(key) {
+			return serve(key)
+		}
+		return nil
+	}
+	if exists(key) {
+		return serve(key)
+	}
+	if !index.SupportSubDir || index.Suffix == "" || !exists(key+"/"+index.Suffix) {
+		return nil
+	}
+	switch index.Type {
+	case WebsiteSubDirRedirect:
+		return &WebsiteResolution{Kind: WebsiteRedirect, StatusCode: http.StatusFound, Location: "/" + key + "/"}
+	case WebsiteSubDirIndex:
+		return serve(key + "/" + index.Suffix)
+	}
+	return nil
+}
+
+func (c *RoutingRuleCondition) matches(key string, header http.Header) bool {
+	if !strings.HasPrefix(key, c.KeyPrefixEquals) || !strings.HasSuffix(key, c.KeySuffixEquals) {
+		return false
+	}
+	for _, include := range c.IncludeHeader {
+		values, ok := header[http.CanonicalHeaderKey(include.Key)]
+		if !ok || len(values) == 0 {
+			return false
+		}
+		value := values[0]
+		if include.Equals != "" && value != include.Equals ||
+			!strings.HasPrefix(value, include.StartsWith) || !strings.HasSuffix(value, include.EndsWith) {
+			return false
+		}
+	}
+	return true
+}
+
+func (r *RoutingRule) resolve(key string, req *http.Request) *WebsiteResolution {
+	redirect := &r.Redirect
+	target := key
+	if redirect.ReplaceKeyWith != "" {
+		target = strings.Replace(redirect.ReplaceKeyWith, "${key}", key, -1)
+	} else if redirect.EnableReplacePrefix {
+		target = redirect.ReplaceKeyPrefixWith + strings.TrimPrefix(key, r.Condition.KeyPrefixEquals)
+	}
+	withQuery := func(location string, pass bool) string {
+		if pass && req.URL.RawQuery != "" {
+			location += "?" + req.URL.RawQuery
+		}
+		return location
+	}
+	if redirect.RedirectType == RedirectMirror {
+		return &WebsiteResolution{
+			Kind:       WebsiteMirror,
+			Key:        key,
+			Location:   withQuery(redirect.MirrorURL+target, redirect.MirrorPassQueryString || redirect.PassQueryString),
+			Header:     redirect.MirrorHeaders.header(req.Header),
+			RuleNumber: r.RuleNumber,
+		}
+	}
+	protocol, host := redirect.Protocol, redirect.HostName
+	if protocol == "" {
+		protocol = "http"
+	}
+	if host == "" {
+		host = req.Host
+	}
+	status := redirect.HTTPRedirectCode
+	if status == 0 {
+		status = http.StatusFound
+	}
+	return &WebsiteResolution{
+		Kind:       WebsiteRedirect,
+		StatusCode: status,
+		Location:   withQuery(protocol+"://"+host+"/"+target, redirect.PassQueryString),
+		RuleNumber: r.RuleNumber,
+	}
+}
+
+// header returns the request headers passed to the origin
+func (h *MirrorHeaders) header(request http.Header) http.Header {
+	header := make(http.Header)
+	if h == nil {
+		return header
+	}
+	if h.PassAll {
+		for name, values := range request {
+			header[name] = append([]string{}, values...)
+		}
+	}
+	for _, name := range h.Pass {
+		if values, ok := request[http.CanonicalHeaderKey(name)]; ok {
+			header[http.CanonicalHeaderKey(name)] = append([]string{}, values...)
+		}
+	}
+	for _, name := range h.Remove {
+		header.Del(name)
+	}
+	for _, set := range h.Set {
+		header.Set(set.Key, set.Value)
+	}
+	return header
+}
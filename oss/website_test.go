@@ -0,0 +1,251 @@
+package oss
+
+import (
+	"encoding/xml"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"regexp"
+	"testing"
+)
+
+const websiteXML = `<WebsiteConfiguration>
+  <IndexDocument>
+    <Suffix>index.html</Suffix>
+    <SupportSubDir>true</SupportSubDir>
+    <Type>2</Type>
+  </IndexDocument>
+  <ErrorDocument>
+    <Key>error.html</Key>
+    <HttpStatus>404</HttpStatus>
+  </ErrorDocument>
+  <RoutingRules>
+    <RoutingRule>
+      <RuleNumber>1</RuleNumber>
+      <Condition>
+        <KeyPrefixEquals>abc/</KeyPrefixEquals>
+        <HttpErrorCodeReturnedEquals>404</HttpErrorCodeReturnedEquals>
+      </Condition>
+      <Redirect>
+        <RedirectType>Mirror</RedirectType>
+        <PassQueryString>true</PassQueryString>
+        <MirrorURL>http://example.com/</MirrorURL>
+        <MirrorPassQueryString>true</MirrorPassQueryString>
+        <MirrorFollowRedirect>false</MirrorFollowRedirect>
+        <MirrorCheckMd5>true</MirrorCheckMd5>
+        <MirrorHeaders>
+          <PassAll>true</PassAll>
+          <Pass>myheader-key1</Pass>
+          <Pass>myheader-key2</Pass>
+          <Remove>myheader-key3</Remove>
+          <Set>
+            <Key>myheader-key5</Key>
+            <Value>myheader-value5</Value>
+          </Set>
+        </MirrorHeaders>
+      </Redirect>
+    </RoutingRule>
+    <RoutingRule>
+      <RuleNumber>2</RuleNumber>
+      <Condition>
+        <KeyPrefixEquals>abc/</KeyPrefixEquals>
+        <KeySuffixEquals>.txt</KeySuffixEquals>
+        <IncludeHeader>
+          <Key>host</Key>
+          <Equals>test.oss-cn-beijing-internal.aliyuncs.com</Equals>
+        </IncludeHeader>
+        <IncludeHeader>
+          <Key>user-agent</Key>
+          <StartsWith>curl/</StartsWith>
+        </IncludeHeader>
+      </Condition>
+      <Redirect>
+        <RedirectType>AliCDN</RedirectType>
+        <PassQueryString>true</PassQueryString>
+        <Protocol>http</Protocol>
+        <HostName>example.com</HostName>
+        <ReplaceKeyPrefixWith>prefix/</ReplaceKeyPrefixWith>
+        <EnableReplacePrefix>true</EnableReplacePrefix>
+        <HttpRedirectCode>301</HttpRedirectCode>
+      </Redirect>
+    </RoutingRule>
+    <RoutingRule>
+      <RuleNumber>3</RuleNumber>
+      <Condition>
+        <HttpErrorCodeReturnedEquals>404</HttpErrorCodeReturnedEquals>
+      </Condition>
+      <Redirect>
+        <RedirectType>External</RedirectType>
+        <Protocol>https</Protocol>
+        <HostName>example.com</HostName>
+        <ReplaceKeyWith>prefix/${key}.suffix</ReplaceKeyWith>
+        <HttpRedirectCode>302</HttpRedirectCode>
+      </Redirect>
+    </RoutingRule>
+  </RoutingRules>
+</WebsiteConfiguration>`
+
+func TestWebsiteConfigurationXML(t *testing.T) {
+	var config WebsiteConfiguration
+	if err := xml.Unmarshal([]byte(websiteXML), &config); err != nil {
+		t.Fatal(err)
+	}
+	followRedirect := false
+	expected := WebsiteConfiguration{
+		IndexDocument: IndexDocument{Suffix: "index.html", SupportSubDir: true, Type: WebsiteSubDirIndex},
+		ErrorDocument: ErrorDocument{Key: "error.html", HTTPStatus: 404},
+		RoutingRules: &RoutingRules{RoutingRule: []RoutingRule{
+			{
+				RuleNumber: 1,
+				Condition:  RoutingRuleCondition{KeyPrefixEquals: "abc/", HTTPErrorCodeReturnedEquals: 404},
+				Redirect: RoutingRuleRedirect{
+					RedirectType:          RedirectMirror,
+					PassQueryString:       true,
+					MirrorURL:             "http://example.com/",
+					MirrorPassQueryString: true,
+					MirrorFollowRedirect:  &followRedirect,
+					MirrorCheckMD5:        true,
+					MirrorHeaders: &MirrorHeaders{
+						PassAll: true,
+						Pass:    []string{"myheader-key1", "myheader-key2"},
+						Remove:  []string{"myheader-key3"},
+						Set:     []MirrorHeaderSet{{Key: "myheader-key5", Value: "myheader-value5"}},
+					},
+				},
+			},
+			{
+				RuleNumber: 2,
+				Condition: RoutingRuleCondition{
+					KeyPrefixEquals: "abc/",
+					KeySuffixEquals: ".txt",
+					IncludeHeader: []RoutingRuleIncludeHeader{
+						{Key: "host", Equals: "test.oss-cn-beijing-internal.aliyuncs.com"},
+						{Key: "user-agent", StartsWith: "curl/"},
+					},
+				},
+				Redirect: RoutingRuleRedirect{
+					RedirectType:         RedirectAliCDN,
+					PassQueryString:      true,
+					Protocol:             "http",
+					HostName:             "example.com",
+					ReplaceKeyPrefixWith: "prefix/",
+					EnableReplacePrefix:  true,
+					HTTPRedirectCode:     301,
+				},
+			},
+			{
+				RuleNumber: 3,
+				Condition:  RoutingRuleCondition{HTTPErrorCodeReturnedEquals: 404},
+				Redirect: RoutingRuleRedirect{
+					RedirectType:     RedirectExternal,
+					Protocol:         "https",
+					HostName:         "example.com",
+					ReplaceKeyWith:   "prefix/${key}.suffix",
+					HTTPRedirectCode: 302,
+				},
+			},
+		}},
+	}
+	if !reflect.DeepEqual(config, expected) {
+		t.Fatalf(expectBut, expected, config)
+	}
+	data, err := xml.Marshal(&config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if compact := regexp.MustCompile(`>\s+<`).ReplaceAllString(websiteXML, "><"); string(data) != compact {
+		t.Fatalf(expectBut, compact, string(data))
+	}
+}
+
+func TestWebsiteResolve(t *testing.T) {
+	objects := map[string]bool{
+		"index.html":      true,
+		"a.txt":           true,
+		"docs/index.html": true,
+		"error.html":      true,
+	}
+	exists := func(key string) bool { return objects[key] }
+	config := &WebsiteConfiguration{
+		IndexDocument: IndexDocument{Suffix: "index.html", SupportSubDir: true},
+		ErrorDocument: ErrorDocument{Key: "error.html"},
+		RoutingRules: &RoutingRules{RoutingRule: []RoutingRule{
+			{
+				RuleNumber: 2,
+				Condition:  RoutingRuleCondition{KeyPrefixEquals: "img/", HTTPErrorCodeReturnedEquals: 404},
+				Redirect: RoutingRuleRedirect{
+					RedirectType:          RedirectMirror,
+					MirrorURL:             "https://origin.example.com/",
+					MirrorPassQueryString: true,
+					MirrorHeaders: &MirrorHeaders{
+						PassAll: true,
+						Remove:  []string{"Cookie"},
+						Set:     []MirrorHeaderSet{{Key: "X-From", Value: "oss"}},
+					},
+				},
+			},
+			{
+				RuleNumber: 1,
+				Condition: RoutingRuleCondition{
+					KeyPrefixEquals: "old/",
+					IncludeHeader:   []RoutingRuleIncludeHeader{{Key: "user-agent", StartsWith: "curl/"}},
+				},
+				Redirect: RoutingRuleRedirect{
+					RedirectType:         RedirectExternal,
+					Protocol:             "https",
+					HostName:             "www.example.com",
+					ReplaceKeyPrefixWith: "new/",
+					EnableReplacePrefix:  true,
+					PassQueryString:      true,
+					HTTPRedirectCode:     301,
+				},
+			},
+			{
+				RuleNumber: 3,
+				Condition:  RoutingRuleCondition{KeyPrefixEquals: "old/"},
+				Redirect:   RoutingRuleRedirect{RedirectType: RedirectAliCDN, ReplaceKeyWith: "${key}.html"},
+			},
+		}},
+	}
+	testcases := []struct {
+		target   string
+		header   http.Header
+		config   func(c *WebsiteConfiguration)
+		expected WebsiteResolution
+	}{
+		{target: "/", expected: WebsiteResolution{Kind: WebsiteServe, Key: "index.html", StatusCode: 200}},
+		{target: "/a.txt", expected: WebsiteResolution{Kind: WebsiteServe, Key: "a.txt", StatusCode: 200}},
+		{target: "/docs/", expected: WebsiteResolution{Kind: WebsiteServe, Key: "docs/index.html", StatusCode: 200}},
+		{target: "/docs", expected: WebsiteResolution{Kind: WebsiteRedirect, StatusCode: 302, Location: "/docs/"}},
+		{target: "/docs", config: func(c *WebsiteConfiguration) { c.IndexDocument.Type = WebsiteSubDirIndex },
+			expected: WebsiteResolution{Kind: WebsiteServe, Key: "docs/index.html", StatusCode: 200}},
+		{target: "/docs", config: func(c *WebsiteConfiguration) { c.IndexDocument.Type = WebsiteSubDirNotFound },
+			expected: WebsiteResolution{Kind: WebsiteServe, Key: "error.html", StatusCode: 404}},
+		{target: "/other/", config: func(c *WebsiteConfiguration) { c.IndexDocument.SupportSubDir = false },
+			expected: WebsiteResolution{Kind: WebsiteServe, Key: "index.html", StatusCode: 200}},
+		{target: "/missing", config: func(c *WebsiteConfiguration) { c.ErrorDocument.HTTPStatus = 200 },
+			expected: WebsiteResolution{Kind: WebsiteServe, Key: "error.html", StatusCode: 200}},
+		{target: "/missing", config: func(c *WebsiteConfiguration) { c.ErrorDocument.Key = "" },
+			expected: WebsiteResolution{Kind: WebsiteServe, StatusCode: 404}},
+		{target: "/img/a.png?w=10", header: http.Header{"Cookie": {"id=1"}, "Accept": {"image/png"}},
+			expected: WebsiteResolution{Kind: WebsiteMirror, Key: "img/a.png", Location: "https://origin.example.com/img/a.png?w=10",
+				Header: http.Header{"Accept": {"image/png"}, "X-From": {"oss"}}, RuleNumber: 2}},
+		{target: "/old/a?x=1", header: http.Header{"User-Agent": {"curl/7.0"}},
+			expected: WebsiteResolution{Kind: WebsiteRedirect, StatusCode: 301, Location: "https://www.example.com/new/a?x=1", RuleNumber: 1}},
+		{target: "/old/a?x=1",
+			expected: WebsiteResolution{Kind: WebsiteRedirect, StatusCode: 302, Location: "http://example.com/old/a.html", RuleNumber: 3}},
+	}
+	for _, tc := range testcases {
+		c := *config
+		if tc.config != nil {
+			tc.config(&c)
+		}
+		req := httptest.NewRequest("GET", tc.target, nil)
+		for name, values := range tc.header {
+			req.Header[name] = values
+		}
+		if res := c.Resolve(req, exists); !reflect.DeepEqual(*res, tc.expected) {
+			t.Fatalf(testcaseExpectBut, tc.target, tc.expected, *res)
+		}
+	}
+}
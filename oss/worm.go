@@ -0,0 +1,110 @@
+package oss
+
+import (
+	"encoding/xml"
+	"errors"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// States of a WORM retention policy
+const (
+	// WormInProgress can be aborted and expires after 24 hours unless it is
+	// completed
+	WormInProgress = "InProgress"
+	// WormLocked can only be extended
+	WormLocked = "Locked"
+)
+
+var (
+	// ErrObjectImmutable matches the error of overwriting or deleting an
+	// object protected by a WORM retention policy, with errors.Is
+	ErrObjectImmutable = errors.New("object protected by WORM retention policy")
+	// ErrWormLocked matches the error of aborting a locked WORM retention
+	// policy, with errors.Is
+	ErrWormLocked = errors.New("WORM retention policy locked")
+)
+
+type (
+	// WormConfiguration is returned by GetBucketWorm API
+	WormConfiguration struct {
+		WormID                string `xml:"WormId"`
+		State                 string
+		RetentionPeriodInDays int
+		CreationDate          time.Time
+	}
+
+	initiateWormConfiguration struct {
+		XMLName               xml.Name `xml:"InitiateWormConfiguration"`
+		RetentionPeriodInDays int
+	}
+	extendWormConfiguration struct {
+		XMLName               xml.Name `xml:"ExtendWormConfiguration"`
+		RetentionPeriodInDays int
+	}
+)
+
+// InitiateBucketWorm creates a WORM retention policy of a bucket in the
+// InProgress state, which protects the objects for days after they are last
+// modified, and returns its ID
+func (a *API) InitiateBucketWorm(bucket string, days int) (string, error) {
+	var res Header
+	if err := a.Do("POST", bucket, "?worm", &res, XMLBody(&initiateWormConfiguration{RetentionPeriodInDays: days})); err != nil {
+		return "", err
+	}
+	return http.Header(res).Get("X-Oss-Worm-Id"), nil
+}
+
+// AbortBucketWorm deletes a WORM retention policy in the InProgress state
+func (a *API) AbortBucketWorm(bucket string) error {
+	return a.Do("DELETE", bucket, "?worm", nil)
+}
+
+// CompleteBucketWorm locks a WORM retention policy, it cannot be deleted or
+// shortened afterwards
+func (a *API) CompleteBucketWorm(bucket, wormID string) error {
+	return a.Do("POST", bucket, "?wormId="+url.QueryEscape(wormID), nil)
+}
+
+// ExtendBucketWorm extends the retention period of a locked WORM retention
+// policy to days
+func (a *API) ExtendBucketWorm(bucket, wormID string, days int) error {
+	return a.Do("POST", bucket, "?wormExtend&wormId="+url.QueryEscape(wormID), nil, XMLBody(&extendWormConfiguration{RetentionPeriodInDays: days}))
+}
+
+// GetBucketWorm returns the WORM retention policy of a bucket
+func (a *API) GetBucketWorm(bucket string) (res *WormConfiguration, _ error) {
+	return res, a.Do("GET", bucket, "?worm", &res)
+}
+
+// UnmarshalXML implements xml.Unmarshaler, CreationDate may have no time
+// zone, which is UTC
+func (c *WormConfiguration) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	var v struct {
+		WormID                string `xml:"WormId"`
+		State                 string
+		RetentionPeriodInDays int
+		CreationDate          string
+	}
+	if err := d.DecodeElement(&v, &start); err != nil {
+		return err
+	}
+	*c = WormConfiguration{WormID: v.WormID, State: v.State, RetentionPeriodInDays: v.RetentionPeriodInDays}
+	if v.CreationDate == "" {
+		return nil
+	}
+	t, err := time.Parse(time.RFC3339, v.CreationDate)
+	if err != nil {
+		if t, err = time.Parse("2006-01-02T15:04:05", v.CreationDate); err != nil {
+			return err
+		}
+	}
+	c.CreationDate = t
+	return nil
+}
+
+// Parse implements ResponseParser
+func (r *WormConfiguration) Parse(resp *http.Response) error {
+	return xml.NewDecoder(resp.Body).Decode(r)
+}
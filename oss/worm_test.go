@@ -0,0 +1,167 @@
+package oss
+
+import (
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+var wormTestcases = []testcase{
+	{
+		name: "InitiateBucketWorm",
+		request: func(a *API) (interface{}, error) {
+			return a.InitiateBucketWorm(testBucketName, 365)
+		},
+		expectedRequest: `POST /?worm HTTP/1.1
+Host: bucket-name.oss-cn-hangzhou.aliyuncs.com
+User-Agent: %s
+Content-Length: 105
+Accept-Encoding: identity
+Authorization: OSS ayahghai0juiSie:AG9RMuK33AHB0K7QzQNaZBjCZq0=
+Date: %s
+
+<InitiateWormConfiguration><RetentionPeriodInDays>365</RetentionPeriodInDays></InitiateWormConfiguration>`,
+		response: `HTTP/1.1 200 OK
+x-oss-request-id: 5C1B138A109F4E405B2D8AEF
+x-oss-worm-id: 1666E2CFB2B34180
+Date: Thu, 15 Oct 2020 15:50:32 GMT
+Content-Length: 0
+Connection: close
+Server: AliyunOSS
+`,
+		expectedResponse: "1666E2CFB2B34180",
+	},
+
+	{
+		name: "CompleteBucketWorm",
+		request: func(a *API) (interface{}, error) {
+			return nil, a.CompleteBucketWorm(testBucketName, "1666E2CFB2B34180")
+		},
+		expectedRequest: `POST /?wormId=1666E2CFB2B34180 HTTP/1.1
+Host: bucket-name.oss-cn-hangzhou.aliyuncs.com
+User-Agent: %s
+Content-Length: 0
+Accept-Encoding: identity
+Authorization: OSS ayahghai0juiSie:3iHFitiu6gy6geF0MfYYimj/wDg=
+Date: %s`,
+		response: `HTTP/1.1 200 OK
+x-oss-request-id: 5C1B138A109F4E405B2D8AEF
+Date: Thu, 15 Oct 2020 15:50:32 GMT
+Content-Length: 0
+Connection: close
+Server: AliyunOSS
+`,
+		expectedResponse: nil,
+	},
+
+	{
+		name: "ExtendBucketWorm",
+		request: func(a *API) (interface{}, error) {
+			return nil, a.ExtendBucketWorm(testBucketName, "1666E2CFB2B34180", 366)
+		},
+		expectedRequest: `POST /?wormExtend&wormId=1666E2CFB2B34180 HTTP/1.1
+Host: bucket-name.oss-cn-hangzhou.aliyuncs.com
+User-Agent: %s
+Content-Length: 101
+Accept-Encoding: identity
+Authorization: OSS ayahghai0juiSie:b9TGDuTXNVZUbEN3k7fsg1v6LZQ=
+Date: %s
+
+<ExtendWormConfiguration><RetentionPeriodInDays>366</RetentionPeriodInDays></ExtendWormConfiguration>`,
+		response: `HTTP/1.1 200 OK
+x-oss-request-id: 5C1B138A109F4E405B2D8AEF
+Date: Thu, 15 Oct 2020 15:50:32 GMT
+Content-Length: 0
+Connection: close
+Server: AliyunOSS
+`,
+		expectedResponse: nil,
+	},
+
+	{
+		name: "GetBucketWorm",
+		request: func(a *API) (interface{}, error) {
+			r, err := a.GetBucketWorm(testBucketName)
+			return r, err
+		},
+		expectedRequest: `GET /?worm HTTP/1.1
+Host: bucket-name.oss-cn-hangzhou.aliyuncs.com
+User-Agent: %s
+Accept-Encoding: identity
+Authorization: OSS ayahghai0juiSie:BqjO623I+vqQS8gTpYI340UgVcc=
+Date: %s`,
+		response: `HTTP/1.1 200 OK
+x-oss-request-id: 5C1B138A109F4E405B2D8AEF
+Date: Thu, 15 Oct 2020 15:50:32 GMT
+Content-Type: application/xml
+Content-Length: 241
+Connection: close
+Server: AliyunOSS
+
+<?xml version="1.0" encoding="UTF-8"?>
+<WormConfiguration>
+  <WormId>1666E2CFB2B34180</WormId>
+  <State>Locked</State>
+  <RetentionPeriodInDays>1</RetentionPeriodInDays>
+  <CreationDate>2020-10-15T15:50:32</CreationDate>
+</WormConfiguration>`,
+		expectedResponse: &WormConfiguration{
+			WormID:                "1666E2CFB2B34180",
+			State:                 WormLocked,
+			RetentionPeriodInDays: 1,
+			CreationDate:          parseTime(time.RFC3339, "2020-10-15T15:50:32Z"),
+		},
+	},
+
+	{
+		name: "AbortBucketWorm",
+		request: func(a *API) (interface{}, error) {
+			return nil, a.AbortBucketWorm(testBucketName)
+		},
+		expectedRequest: `DELETE /?worm HTTP/1.1
+Host: bucket-name.oss-cn-hangzhou.aliyuncs.com
+User-Agent: %s
+Accept-Encoding: identity
+Authorization: OSS ayahghai0juiSie:TT4n5a0hn7uYAMNMeX75PZFs1xA=
+Date: %s`,
+		response: `HTTP/1.1 204 No Content
+x-oss-request-id: 5C1B138A109F4E405B2D8AEF
+Date: Thu, 15 Oct 2020 15:50:32 GMT
+Content-Length: 0
+Connection: close
+Server: AliyunOSS
+`,
+		expectedResponse: nil,
+	},
+}
+
+func TestWormAPI(t *testing.T) {
+	for i := range wormTestcases {
+		testAPI(t, &wormTestcases[i])
+	}
+}
+
+func TestWormErrors(t *testing.T) {
+	for _, testcase := range []struct {
+		code     string
+		expected error
+	}{
+		{"FileImmutable", ErrObjectImmutable},
+		{"WORMConfigurationLocked", ErrWormLocked},
+	} {
+		err := parseError(&http.Response{
+			StatusCode: http.StatusConflict,
+			Status:     "409 Conflict",
+			Body:       ioutil.NopCloser(strings.NewReader("<Error><Code>" + testcase.code + "</Code></Error>")),
+		})
+		if !errors.Is(err, testcase.expected) || !IsErrorCode(err, testcase.code) {
+			t.Fatalf(testcaseExpectBut, testcase.code, testcase.expected, err)
+		}
+	}
+	if err := error(&Error{Code: "NoSuchKey"}); errors.Is(err, ErrObjectImmutable) {
+		t.Fatalf(expectBut, "NoSuchKey", err)
+	}
+}